@@ -0,0 +1,637 @@
+package dzhcore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gogf/gf/v2/database/gdb"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/net/ghttp"
+	"github.com/gogf/gf/v2/os/gcron"
+	"github.com/gogf/gf/v2/os/gfile"
+	"github.com/gogf/gf/v2/os/gtime"
+	"github.com/gzdzh-cn/dzhcore/coreconfig"
+	"github.com/gzdzh-cn/dzhcore/envconfig"
+	"github.com/gzdzh-cn/dzhcore/utility/util"
+)
+
+// sqlite 数据库备份
+// 通过 VACUUM INTO 在线生成一致的快照,按 core.backup.cron 定时备份,保留最近 core.backup.keep 个
+// 启动时执行 PRAGMA quick_check,数据库损坏时自动从最近一个完好的备份恢复
+// 恢复时持有维护锁,等待进行中的请求结束并阻塞新的请求,直到新的数据库文件重新连接
+
+const (
+	backupTimeLayout       = "20060102150405.000000" // 精确到微秒,同一秒内多次备份不会覆盖
+	backupTimeLayoutSecond = "20060102150405"        // 旧版本备份文件名的时间格式
+	backupExt              = ".sqlite"
+	sqliteMemType          = "sqlitemem" // 内存 sqlite,与 contrib/drivers/sqlitemem 的 DriverName 一致
+)
+
+var (
+	// 备份与恢复互斥
+	backupMu sync.Mutex
+	// 维护锁,请求期间持有读锁,恢复数据库时持有写锁
+	dbGate sync.RWMutex
+)
+
+// 请求持有的维护锁读锁
+type dbGateHold struct {
+	held bool
+}
+
+type dbGateCtxKey struct{}
+
+// DbGate 请求期间持有维护锁的读锁,恢复数据库时等待进行中的请求结束,并阻塞新的请求直到恢复完成
+func DbGate(r *ghttp.Request) {
+	hold := &dbGateHold{held: true}
+	dbGate.RLock()
+	defer func() {
+		if hold.held {
+			dbGate.RUnlock()
+		}
+	}()
+	r.SetCtx(context.WithValue(r.Context(), dbGateCtxKey{}, hold))
+	r.Middleware.Next()
+}
+
+// 持有维护锁的读锁,用于请求之外的定时任务,ctx 已持有时不重复加锁
+func readDbGate(ctx context.Context) (unlock func()) {
+	if hold, ok := ctx.Value(dbGateCtxKey{}).(*dbGateHold); ok && hold.held {
+		return func() {}
+	}
+	dbGate.RLock()
+	return dbGate.RUnlock
+}
+
+// 持有维护锁的写锁,ctx 所在请求持有的读锁先释放,避免等待自己
+func lockDbGate(ctx context.Context) (unlock func()) {
+	if hold, ok := ctx.Value(dbGateCtxKey{}).(*dbGateHold); ok && hold.held {
+		hold.held = false
+		dbGate.RUnlock()
+	}
+	dbGate.Lock()
+	return dbGate.Unlock
+}
+
+// BackupFile 备份文件
+type BackupFile struct {
+	Name       string      `json:"name"`       // 文件名
+	Size       int64       `json:"size"`       // 文件大小
+	CreateTime *gtime.Time `json:"createTime"` // 创建时间
+}
+
+// 是否为 sqlite 数据库,包括内存 sqlite
+func isSqlite() bool {
+	switch g.DB().GetConfig().Type {
+	case "sqlite", sqliteMemType:
+		return true
+	}
+	return false
+}
+
+// 是否为内存 sqlite
+func isMemorySqlite() bool {
+	return g.DB().GetConfig().Type == sqliteMemType
+}
+
+// 当前 sqlite 数据库文件的绝对路径,内存 sqlite 为库名
+func sqliteFile(ctx context.Context) (string, error) {
+	if !isSqlite() {
+		return "", gerror.New("只支持 sqlite 数据库备份")
+	}
+	if isMemorySqlite() {
+		if name := g.DB().GetConfig().Name; name != "" {
+			return name, nil
+		}
+		return "dzhcore", nil
+	}
+	result, err := g.DB().GetAll(ctx, "PRAGMA database_list")
+	if err != nil {
+		// 文件损坏时无法查询,使用配置中的路径
+		if path := gfile.RealPath(g.DB().GetConfig().Name); path != "" {
+			return path, nil
+		}
+		return "", err
+	}
+	for _, record := range result {
+		if record["name"].String() == "main" && record["file"].String() != "" {
+			return record["file"].String(), nil
+		}
+	}
+	return "", gerror.New("未找到 sqlite 数据库文件")
+}
+
+// 备份目录
+func backupPath() (string, error) {
+	path := util.NewToolUtil().GetBackupPath(envconfig.IsProd, envconfig.AppName, envconfig.IsDesktop, coreconfig.Config.Core.Backup.Path)
+	if err := gfile.Mkdir(path); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// 备份文件名前缀,为数据库文件名去掉扩展名
+func backupPrefix(dbFile string) string {
+	base := filepath.Base(dbFile)
+	return strings.TrimSuffix(base, filepath.Ext(base)) + "-"
+}
+
+// BackupDatabase 在线备份 sqlite 数据库,备份后按 core.backup.keep 清理旧备份
+func BackupDatabase(ctx context.Context) (file *BackupFile, err error) {
+	backupMu.Lock()
+	defer backupMu.Unlock()
+	return backupDatabase(ctx)
+}
+
+func backupDatabase(ctx context.Context) (file *BackupFile, err error) {
+	dbFile, err := sqliteFile(ctx)
+	if err != nil {
+		return nil, err
+	}
+	dir, err := backupPath()
+	if err != nil {
+		return nil, err
+	}
+	var (
+		name   = backupPrefix(dbFile) + time.Now().Format(backupTimeLayout) + backupExt
+		target = filepath.Join(dir, name)
+		temp   = target + ".tmp"
+	)
+	// 先写入临时文件,避免中断后留下不完整的备份
+	_ = os.Remove(temp)
+	if _, err = g.DB().Exec(ctx, "VACUUM INTO ?", temp); err != nil {
+		_ = os.Remove(temp)
+		return nil, gerror.Wrap(err, "备份数据库失败")
+	}
+	if err = os.Rename(temp, target); err != nil {
+		_ = os.Remove(temp)
+		return nil, err
+	}
+	g.Log().Infof(ctx, "数据库已备份到 %s", target)
+	if err = rotateBackups(ctx, dbFile); err != nil {
+		g.Log().Errorf(ctx, "清理旧备份失败: %v", err)
+	}
+	return &BackupFile{Name: name, Size: gfile.Size(target), CreateTime: gtime.Now()}, nil
+}
+
+// 只保留最近的 core.backup.keep 个备份
+func rotateBackups(ctx context.Context, dbFile string) error {
+	keep := coreconfig.Config.Core.Backup.Keep
+	if keep <= 0 {
+		return nil
+	}
+	list, err := listBackups(dbFile)
+	if err != nil {
+		return err
+	}
+	if len(list) <= keep {
+		return nil
+	}
+	dir, err := backupPath()
+	if err != nil {
+		return err
+	}
+	for _, item := range list[keep:] {
+		if err = os.Remove(filepath.Join(dir, item.Name)); err != nil {
+			return err
+		}
+		g.Log().Debugf(ctx, "删除旧备份 %s", item.Name)
+	}
+	return nil
+}
+
+// ListBackups 获取备份文件,按时间倒序
+func ListBackups(ctx context.Context) ([]*BackupFile, error) {
+	dbFile, err := sqliteFile(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return listBackups(dbFile)
+}
+
+func listBackups(dbFile string) (list []*BackupFile, err error) {
+	dir, err := backupPath()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	prefix := backupPrefix(dbFile)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, backupExt) {
+			continue
+		}
+		createTime, ok := parseBackupTime(strings.TrimSuffix(strings.TrimPrefix(name, prefix), backupExt))
+		if !ok {
+			continue
+		}
+		list = append(list, &BackupFile{
+			Name:       name,
+			Size:       gfile.Size(filepath.Join(dir, name)),
+			CreateTime: gtime.New(createTime),
+		})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].CreateTime.After(list[j].CreateTime) })
+	return
+}
+
+// 解析备份文件名中的时间
+func parseBackupTime(value string) (time.Time, bool) {
+	for _, layout := range []string{backupTimeLayout, backupTimeLayoutSecond} {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// 检查 sqlite 文件完整性
+func checkSqliteFile(ctx context.Context, file string) error {
+	db, err := gdb.New(gdb.ConfigNode{Type: "sqlite", Name: file})
+	if err != nil {
+		return err
+	}
+	defer db.Close(ctx)
+	return quickCheck(ctx, db)
+}
+
+// PRAGMA quick_check 结果不是 ok 时返回错误
+func quickCheck(ctx context.Context, db gdb.DB) error {
+	value, err := db.GetValue(ctx, "PRAGMA quick_check")
+	if err != nil {
+		return err
+	}
+	if value.String() != "ok" {
+		return gerror.Newf("数据库完整性检查失败: %s", value.String())
+	}
+	return nil
+}
+
+// RestoreDatabase 从备份恢复数据库,恢复前先备份当前数据库
+// 恢复期间持有维护锁,关闭 g.DB() 和 gorm 的连接,替换数据库文件并确认重新连接到新文件后释放
+// 内存 sqlite 无法替换文件,在同一个库中按备份重建表和数据
+func RestoreDatabase(ctx context.Context, name string) error {
+	backupMu.Lock()
+	defer backupMu.Unlock()
+	if name == "" || name != filepath.Base(name) {
+		return gerror.Newf("备份文件名不正确: %s", name)
+	}
+	dbFile, err := sqliteFile(ctx)
+	if err != nil {
+		return err
+	}
+	dir, err := backupPath()
+	if err != nil {
+		return err
+	}
+	source := filepath.Join(dir, name)
+	if !strings.HasPrefix(name, backupPrefix(dbFile)) || !gfile.Exists(source) {
+		return gerror.Newf("备份文件不存在: %s", name)
+	}
+	if err = checkSqliteFile(ctx, source); err != nil {
+		return gerror.Wrapf(err, "备份文件 %s 已损坏", name)
+	}
+	if isMemorySqlite() {
+		if _, err = backupDatabase(ctx); err != nil {
+			return gerror.Wrap(err, "恢复前备份当前数据库失败")
+		}
+		unlock := lockDbGate(ctx)
+		defer unlock()
+		if err = restoreMemory(ctx, source); err != nil {
+			return err
+		}
+		g.Log().Infof(ctx, "数据库已从 %s 恢复", name)
+		return nil
+	}
+	// 先复制要恢复的备份,恢复前的备份会清理旧备份,可能删除该文件
+	staged, err := stageDatabase(ctx, dbFile, source)
+	defer os.Remove(staged.file)
+	if err != nil {
+		return err
+	}
+	if _, err = backupDatabase(ctx); err != nil {
+		return gerror.Wrap(err, "恢复前备份当前数据库失败")
+	}
+	unlock := lockDbGate(ctx)
+	defer unlock()
+	if err = replaceDatabase(ctx, dbFile, staged); err != nil {
+		return err
+	}
+	g.Log().Infof(ctx, "数据库已从 %s 恢复", name)
+	return nil
+}
+
+// 待替换的数据库文件
+type stagedDatabase struct {
+	file    string // 复制的文件
+	version int    // 原 user_version
+	marker  int    // 替换期间的 user_version,用于确认连接已指向新文件
+}
+
+// 把 source 复制到数据库文件所在目录,并把 user_version 改为标记值
+func stageDatabase(ctx context.Context, dbFile, source string) (staged *stagedDatabase, err error) {
+	staged = &stagedDatabase{file: dbFile + ".restore"}
+	if err = gfile.CopyFile(source, staged.file); err != nil {
+		return staged, err
+	}
+	db, err := gdb.New(gdb.ConfigNode{Type: "sqlite", Name: staged.file})
+	if err != nil {
+		return staged, err
+	}
+	defer db.Close(ctx)
+	value, err := db.GetValue(ctx, "PRAGMA user_version")
+	if err != nil {
+		return staged, err
+	}
+	staged.version = value.Int()
+	staged.marker = int(time.Now().UnixNano()&0x3fffffff) + 1
+	if staged.marker == staged.version {
+		staged.marker++
+	}
+	_, err = db.Exec(ctx, fmt.Sprintf("PRAGMA user_version = %d", staged.marker))
+	return staged, err
+}
+
+// 关闭连接并用 stageDatabase 复制的文件替换数据库文件,需持有维护锁的写锁
+// 替换后确认 g.DB() 和 gorm 都已连接到新文件,再恢复原 user_version
+func replaceDatabase(ctx context.Context, dbFile string, staged *stagedDatabase) (err error) {
+	if err = closeConnections(ctx, "default"); err != nil {
+		return err
+	}
+	for _, suffix := range []string{"-wal", "-shm", "-journal"} {
+		if err = os.Remove(dbFile + suffix); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	if err = os.Rename(staged.file, dbFile); err != nil {
+		return err
+	}
+	// 清除查询缓存和表结构缓存,之后的查询自动重新连接
+	if err = g.DB().GetCore().ClearCacheAll(ctx); err != nil {
+		return err
+	}
+	value, err := g.DB().GetValue(ctx, "PRAGMA user_version")
+	if err != nil {
+		return err
+	}
+	if value.Int() != staged.marker {
+		return gerror.New("恢复后 g.DB() 未连接到新的数据库文件")
+	}
+	db, err := getDBbyGroup("default")
+	if err != nil {
+		return err
+	}
+	var version int
+	if err = db.Raw("PRAGMA user_version").Scan(&version).Error; err != nil {
+		return err
+	}
+	if version != staged.marker {
+		return gerror.New("恢复后 gorm 未连接到新的数据库文件")
+	}
+	_, err = g.DB().Exec(ctx, fmt.Sprintf("PRAGMA user_version = %d", staged.version))
+	return err
+}
+
+// 内存 sqlite 从备份文件恢复,删除当前库的全部表后按备份重建表结构并复制数据,需持有维护锁的写锁
+func restoreMemory(ctx context.Context, source string) (err error) {
+	master, err := g.DB().Master()
+	if err != nil {
+		return err
+	}
+	// ATTACH 只对当前连接有效,全部操作使用同一个连接
+	conn, err := master.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if _, err = conn.ExecContext(ctx, "ATTACH DATABASE ? AS restore_src", source); err != nil {
+		return err
+	}
+	defer conn.ExecContext(context.Background(), "DETACH DATABASE restore_src")
+	// 复制数据时不检查外键,完成后恢复连接原来的设置
+	var foreignKeys int
+	if err = conn.QueryRowContext(ctx, "PRAGMA foreign_keys").Scan(&foreignKeys); err != nil {
+		return err
+	}
+	if _, err = conn.ExecContext(ctx, "PRAGMA foreign_keys = OFF"); err != nil {
+		return err
+	}
+	defer conn.ExecContext(context.Background(), fmt.Sprintf("PRAGMA foreign_keys = %d", foreignKeys))
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	// 先删除虚拟表,同时删除其影子表
+	for _, virtual := range []bool{true, false} {
+		names, err := queryStrings(ctx, tx, `SELECT name FROM main.sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%' AND (sql LIKE 'CREATE VIRTUAL%') = ?`, virtual)
+		if err != nil {
+			return err
+		}
+		for _, name := range names {
+			if _, err = tx.ExecContext(ctx, fmt.Sprintf(`DROP TABLE IF EXISTS main."%s"`, name)); err != nil {
+				return err
+			}
+		}
+	}
+	views, err := queryStrings(ctx, tx, `SELECT name FROM main.sqlite_master WHERE type = 'view'`)
+	if err != nil {
+		return err
+	}
+	for _, name := range views {
+		if _, err = tx.ExecContext(ctx, fmt.Sprintf(`DROP VIEW main."%s"`, name)); err != nil {
+			return err
+		}
+	}
+	// 建表,虚拟表在前,其影子表由虚拟表创建
+	tables, err := queryStrings(ctx, tx, `SELECT sql FROM restore_src.sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%' ORDER BY sql NOT LIKE 'CREATE VIRTUAL%'`)
+	if err != nil {
+		return err
+	}
+	for _, stmt := range tables {
+		if strings.HasPrefix(stmt, "CREATE TABLE ") {
+			stmt = "CREATE TABLE IF NOT EXISTS " + strings.TrimPrefix(stmt, "CREATE TABLE ")
+		}
+		if _, err = tx.ExecContext(ctx, stmt); err != nil {
+			return err
+		}
+	}
+	// 复制普通表和影子表的数据,虚拟表的数据保存在影子表中
+	names, err := queryStrings(ctx, tx, `SELECT name FROM restore_src.sqlite_master WHERE type = 'table' AND sql NOT LIKE 'CREATE VIRTUAL%' AND (name NOT LIKE 'sqlite_%' OR name = 'sqlite_sequence')`)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		if _, err = tx.ExecContext(ctx, fmt.Sprintf(`DELETE FROM main."%s"`, name)); err != nil {
+			return err
+		}
+		if _, err = tx.ExecContext(ctx, fmt.Sprintf(`INSERT INTO main."%s" SELECT * FROM restore_src."%s"`, name, name)); err != nil {
+			return err
+		}
+	}
+	// 数据复制完成后再创建索引、触发器和视图,避免触发器重复写入
+	others, err := queryStrings(ctx, tx, `SELECT sql FROM restore_src.sqlite_master WHERE type IN ('index', 'trigger', 'view') AND sql IS NOT NULL ORDER BY type = 'view'`)
+	if err != nil {
+		return err
+	}
+	for _, stmt := range others {
+		if _, err = tx.ExecContext(ctx, stmt); err != nil {
+			return err
+		}
+	}
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+	return g.DB().GetCore().ClearCacheAll(ctx)
+}
+
+// 查询第一列的全部值
+func queryStrings(ctx context.Context, tx *sql.Tx, query string, args ...interface{}) (values []string, err error) {
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var value string
+		if err = rows.Scan(&value); err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, rows.Err()
+}
+
+// 启动时检查 sqlite 数据库完整性,损坏时从最近一个完好的备份恢复
+func checkDatabase() {
+	if !isSqlite() || isMemorySqlite() || !coreconfig.Config.Core.Backup.CheckOnStart {
+		return
+	}
+	dbFile, err := sqliteFile(ctx)
+	if err != nil {
+		g.Log().Errorf(ctx, "数据库完整性检查失败: %v", err)
+		return
+	}
+	err = quickCheck(ctx, g.DB())
+	if err == nil {
+		return
+	}
+	g.Log().Errorf(ctx, "数据库 %s 已损坏: %v", dbFile, err)
+	if !coreconfig.Config.Core.Backup.AutoRestore {
+		panic(err)
+	}
+	list, e := listBackups(dbFile)
+	if e != nil {
+		panic(e)
+	}
+	dir, e := backupPath()
+	if e != nil {
+		panic(e)
+	}
+	backupMu.Lock()
+	defer backupMu.Unlock()
+	unlock := lockDbGate(ctx)
+	defer unlock()
+	for _, item := range list {
+		source := filepath.Join(dir, item.Name)
+		if e = checkSqliteFile(ctx, source); e != nil {
+			g.Log().Warningf(ctx, "备份 %s 不可用: %v", item.Name, e)
+			continue
+		}
+		// 保留损坏的文件用于排查
+		if e = gfile.CopyFile(dbFile, dbFile+".corrupt-"+time.Now().Format(backupTimeLayout)); e != nil {
+			g.Log().Warningf(ctx, "保留损坏的数据库文件失败: %v", e)
+		}
+		staged, e := stageDatabase(ctx, dbFile, source)
+		if e == nil {
+			e = replaceDatabase(ctx, dbFile, staged)
+		}
+		if e != nil {
+			_ = os.Remove(staged.file)
+			panic(e)
+		}
+		g.Log().Warningf(ctx, "数据库已从备份 %s 自动恢复", item.Name)
+		return
+	}
+	g.Log().Error(ctx, "没有可用的备份,无法自动恢复")
+	panic(err)
+}
+
+// 启动定时备份
+func startBackup() {
+	cfg := coreconfig.Config.Core.Backup
+	if !cfg.Enable || cfg.Cron == "" || !isSqlite() {
+		return
+	}
+	_, err := gcron.AddSingleton(ctx, cfg.Cron, func(ctx context.Context) {
+		if _, err := BackupDatabase(ctx); err != nil {
+			g.Log().Errorf(ctx, "定时备份数据库失败: %v", err)
+		}
+	}, "core.backup")
+	if err != nil {
+		g.Log().Errorf(ctx, "启动定时备份失败: %v", err)
+	}
+}
+
+// BackupController 数据库备份接口
+type BackupController struct {
+	*ControllerSimple
+}
+
+type BackupListReq struct {
+	g.Meta `path:"/list" method:"GET"`
+}
+
+type BackupCreateReq struct {
+	g.Meta `path:"/create" method:"POST"`
+}
+
+type BackupRestoreReq struct {
+	g.Meta `path:"/restore" method:"POST"`
+	Name   string `json:"name" v:"required#请选择要恢复的备份"` // 备份文件名
+}
+
+// List 备份列表
+func (c *BackupController) List(ctx context.Context, req *BackupListReq) (res *BaseRes, err error) {
+	list, err := ListBackups(ctx)
+	if err != nil {
+		return Fail(err.Error()), err
+	}
+	return Ok(list), nil
+}
+
+// Create 立即备份
+func (c *BackupController) Create(ctx context.Context, req *BackupCreateReq) (res *BaseRes, err error) {
+	file, err := BackupDatabase(ctx)
+	if err != nil {
+		return Fail(err.Error()), err
+	}
+	return Ok(file), nil
+}
+
+// Restore 从备份恢复
+func (c *BackupController) Restore(ctx context.Context, req *BackupRestoreReq) (res *BaseRes, err error) {
+	if err = RestoreDatabase(ctx, req.Name); err != nil {
+		return Fail(err.Error()), err
+	}
+	return Ok(nil), nil
+}
+
+func init() {
+	if coreconfig.Config.Core.Backup.Enable {
+		AddControllerSimple(&BackupController{
+			&ControllerSimple{Prefix: "/admin/core/backup"},
+		})
+	}
+}
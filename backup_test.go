@@ -0,0 +1,233 @@
+package dzhcore_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gogf/gf/v2/database/gdb"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gzdzh-cn/dzhcore"
+	"github.com/gzdzh-cn/dzhcore/coreconfig"
+	"github.com/gzdzh-cn/dzhcore/dzhcoretest"
+)
+
+var (
+	gateEntered = make(chan struct{})
+	gateRelease = make(chan struct{})
+)
+
+// 进行中的请求,收到 gateRelease 后返回
+type testGateController struct {
+	*dzhcore.ControllerSimple
+}
+
+type testGateWaitReq struct {
+	g.Meta `path:"/wait" method:"GET"`
+}
+
+func (c *testGateController) Wait(ctx context.Context, req *testGateWaitReq) (res *dzhcore.BaseRes, err error) {
+	gateEntered <- struct{}{}
+	<-gateRelease
+	count, err := g.DB().Model("test_product").Ctx(ctx).Count()
+	if err != nil {
+		return dzhcore.Fail(err.Error()), err
+	}
+	return dzhcore.Ok(count), nil
+}
+
+func init() {
+	testOptions.ControllerSimples = append(testOptions.ControllerSimples,
+		&dzhcore.BackupController{ControllerSimple: &dzhcore.ControllerSimple{Prefix: "/admin/core/backup"}},
+		&testGateController{&dzhcore.ControllerSimple{Prefix: "/admin/test/gate"}},
+	)
+}
+
+// 清空备份目录
+func setupBackup(t *testing.T) *dzhcoretest.App {
+	app := dzhcoretest.Setup(t)
+	list, err := dzhcore.ListBackups(app.Ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, item := range list {
+		if err = os.Remove(filepath.Join(coreconfig.Config.Core.Backup.Path, item.Name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return app
+}
+
+func productCodes(t *testing.T, db gdb.DB) []string {
+	t.Helper()
+	codes, err := db.Model("test_product").Order("code").Array("code")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return g.NewVar(codes).Strings()
+}
+
+// 直接打开数据库文件读取,确认数据写入了当前的数据库文件
+func fileProductCodes(t *testing.T) []string {
+	t.Helper()
+	db, err := gdb.New(gdb.ConfigNode{Type: "sqlite", Name: g.DB().GetConfig().Name})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close(context.Background())
+	return productCodes(t, db)
+}
+
+// 只保留最近的 core.backup.keep 个备份
+func TestBackupRotate(t *testing.T) {
+	app := setupBackup(t)
+	var names []string
+	for i := 0; i < 4; i++ {
+		file, err := dzhcore.BackupDatabase(app.Ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, file.Name)
+	}
+	list, err := dzhcore.ListBackups(app.Ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 3 {
+		t.Fatalf("保留 %d 个备份, 期望 3", len(list))
+	}
+	for i, item := range list {
+		if want := names[len(names)-1-i]; item.Name != want {
+			t.Errorf("第 %d 个备份 %s, 期望 %s", i, item.Name, want)
+		}
+	}
+}
+
+func TestRestoreDatabase(t *testing.T) {
+	app := setupBackup(t)
+	app.Fixture(t, "test_product", g.List{{"code": "before"}})
+	file, err := dzhcore.BackupDatabase(app.Ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	app.Fixture(t, "test_product", g.List{{"code": "after"}})
+
+	res := app.Client.Post(t, "/admin/core/backup/restore", g.Map{"name": file.Name})
+	if !res.Ok() {
+		t.Fatalf("恢复失败: %s", res.Body)
+	}
+	if codes := productCodes(t, g.DB()); strings.Join(codes, ",") != "before" {
+		t.Fatalf("g.DB() 恢复后数据 %v, 期望 [before]", codes)
+	}
+	db, err := dzhcore.GetDBbyGroup("default")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var count int64
+	if err = db.Table("test_product").Count(&count).Error; err != nil || count != 1 {
+		t.Fatalf("gorm 恢复后数据 %d 条, 期望 1: %v", count, err)
+	}
+	// 替换期间的标记已恢复
+	if version, _ := g.DB().GetValue(app.Ctx, "PRAGMA user_version"); version.Int() != 0 {
+		t.Fatalf("user_version %d, 期望 0", version.Int())
+	}
+	// 恢复前先备份了当前数据库
+	list, err := dzhcore.ListBackups(app.Ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 || list[1].Name != file.Name {
+		t.Fatalf("恢复后备份 %d 个, 期望恢复前的备份和 %s", len(list), file.Name)
+	}
+
+	// 恢复后的写入进入新的数据库文件
+	app.Fixture(t, "test_product", g.List{{"code": "new"}})
+	if codes := fileProductCodes(t); strings.Join(codes, ",") != "before,new" {
+		t.Fatalf("数据库文件中的数据 %v, 期望 [before new]", codes)
+	}
+}
+
+// 恢复等待进行中的请求结束,恢复期间的新请求等待恢复完成
+func TestRestoreWaitsForRequests(t *testing.T) {
+	app := setupBackup(t)
+	file, err := dzhcore.BackupDatabase(app.Ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	app.Fixture(t, "test_product", g.List{{"code": "a"}})
+
+	waited := make(chan *dzhcoretest.Response)
+	go func() {
+		waited <- app.Client.Get(t, "/admin/test/gate/wait")
+	}()
+	<-gateEntered
+	restored := make(chan error)
+	go func() {
+		restored <- dzhcore.RestoreDatabase(context.Background(), file.Name)
+	}()
+	select {
+	case err = <-restored:
+		t.Fatalf("恢复没有等待进行中的请求: %v", err)
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	// 恢复等待期间的新请求在恢复完成后执行
+	paged := make(chan *dzhcoretest.Response)
+	go func() {
+		paged <- app.Client.Get(t, "/admin/test/gate/wait")
+	}()
+	select {
+	case <-gateEntered:
+		t.Fatal("恢复期间的新请求没有等待")
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	gateRelease <- struct{}{}
+	if res := <-waited; g.NewVar(res.Data).Int() != 1 {
+		t.Fatalf("进行中的请求读取到 %s, 期望恢复前的 1 条", res.Body)
+	}
+	if err = <-restored; err != nil {
+		t.Fatal(err)
+	}
+	<-gateEntered
+	gateRelease <- struct{}{}
+	if res := <-paged; g.NewVar(res.Data).Int() != 0 {
+		t.Fatalf("恢复后的请求读取到 %s, 期望 0 条", res.Body)
+	}
+}
+
+// 启动时数据库损坏,从最近一个完好的备份恢复
+func TestCheckDatabaseAutoRestore(t *testing.T) {
+	app := setupBackup(t)
+	app.Fixture(t, "test_product", g.List{{"code": "saved"}})
+	if _, err := dzhcore.BackupDatabase(app.Ctx); err != nil {
+		t.Fatal(err)
+	}
+	app.Fixture(t, "test_product", g.List{{"code": "lost"}})
+
+	cfg := coreconfig.Config.Core.Backup
+	coreconfig.Config.Core.Backup.CheckOnStart = true
+	coreconfig.Config.Core.Backup.AutoRestore = true
+	defer func() { coreconfig.Config.Core.Backup = cfg }()
+
+	dbFile := g.DB().GetConfig().Name
+	if err := dzhcore.CloseConnections(app.Ctx, "default"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(dbFile, []byte(strings.Repeat("corrupt", 1024)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	dzhcore.CheckDatabase()
+
+	if codes := productCodes(t, g.DB()); strings.Join(codes, ",") != "saved" {
+		t.Fatalf("自动恢复后数据 %v, 期望 [saved]", codes)
+	}
+	corrupt, err := filepath.Glob(dbFile + ".corrupt-*")
+	if err != nil || len(corrupt) != 1 {
+		t.Fatalf("损坏的数据库文件 %v, 期望保留 1 个", corrupt)
+	}
+	os.Remove(corrupt[0])
+}
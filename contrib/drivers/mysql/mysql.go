@@ -57,7 +57,7 @@ func (d *DriverMysql) GetConn(config *gdb.ConfigNode) (db *gorm.DB, err error) {
 		}
 	}
 
-	return gorm.Open(mysql.Open(source), &gorm.Config{})
+	return gorm.Open(mysql.Open(source), coredb.GormConfig())
 }
 
 func init() {
@@ -63,7 +63,7 @@ func (d *DriverPgsql) GetConn(config *gdb.ConfigNode) (db *gorm.DB, err error) {
 			}
 		}
 	}
-	db, err = gorm.Open(postgres.Open(source), &gorm.Config{})
+	db, err = gorm.Open(postgres.Open(source), coredb.GormConfig())
 	return
 }
 
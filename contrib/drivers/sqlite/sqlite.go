@@ -90,7 +90,7 @@ func (d *DriverSqlite) GetConn(configNode *gdb.ConfigNode) (db *gorm.DB, err err
 	}
 
 	g.Log().Debugf(ctx, "Will use %s to open DB", sourcePath)
-	return gorm.Open(sqlite.Open(sourcePath), &gorm.Config{})
+	return gorm.Open(sqlite.Open(sourcePath), coredb.GormConfig())
 
 }
 
@@ -0,0 +1,153 @@
+// Package sqlitemem 内存 sqlite 驱动,同时注册 GoFrame 的 gdb 驱动和 gorm 驱动,主要用于测试.
+//
+// g.DB() 与 gorm 使用同一个共享缓存的内存库,name 为库名,同名的分组共用一个库,进程退出后数据丢失.
+// 读操作使用 read_uncommitted,事务未提交时其他连接可以读取,但不能写入同一张表.
+//
+//	database:
+//	  default:
+//	    type: sqlitemem
+//	    name: test
+package sqlitemem
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/gogf/gf/contrib/drivers/sqlite/v2"
+	"github.com/gogf/gf/v2/database/gdb"
+	"github.com/gogf/gf/v2/encoding/gurl"
+	"github.com/gogf/gf/v2/errors/gcode"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/text/gstr"
+	"github.com/gogf/gf/v2/util/gconv"
+	"github.com/gzdzh-cn/dzhcore/coredb"
+	gormsqlite "gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+const (
+	DriverName = "sqlitemem" // 数据库类型
+	// 与 gf 的 sqlite 驱动使用同一个底层驱动,gorm 默认的 sqlite3 驱动无法访问该内存库
+	underlyingDriverName = "sqlite"
+	defaultName          = "dzhcore"
+)
+
+var (
+	keepMu sync.Mutex
+	keeps  = make(map[string]*sql.Conn) // 保持每个内存库至少有一个连接,避免连接全部关闭后数据丢失 key:库名
+)
+
+// Driver gdb 驱动,除打开连接外与 gf 的 sqlite 驱动一致
+type Driver struct {
+	*sqlite.Driver
+}
+
+// New 创建 gdb 驱动
+func New() gdb.Driver {
+	return &Driver{}
+}
+
+// New 创建数据库对象
+func (d *Driver) New(core *gdb.Core, node *gdb.ConfigNode) (gdb.DB, error) {
+	return &Driver{
+		Driver: &sqlite.Driver{Core: core},
+	}, nil
+}
+
+// Open 打开内存库连接
+func (d *Driver) Open(config *gdb.ConfigNode) (db *sql.DB, err error) {
+	source, err := Source(config)
+	if err != nil {
+		return nil, err
+	}
+	if err = keep(config.Name, source); err != nil {
+		return nil, err
+	}
+	if db, err = sql.Open(underlyingDriverName, source); err != nil {
+		err = gerror.WrapCodef(
+			gcode.CodeDbOperationError, err,
+			`sql.Open failed for driver "%s" by source "%s"`, underlyingDriverName, source,
+		)
+		return nil, err
+	}
+	return
+}
+
+// DriverGorm gorm 驱动
+type DriverGorm struct {
+}
+
+// NewGorm 创建 gorm 驱动
+func NewGorm() coredb.Driver {
+	return &DriverGorm{}
+}
+
+// GetConn 打开内存库的 gorm 连接
+func (d *DriverGorm) GetConn(config *gdb.ConfigNode) (db *gorm.DB, err error) {
+	source, err := Source(config)
+	if err != nil {
+		return nil, err
+	}
+	if err = keep(config.Name, source); err != nil {
+		return nil, err
+	}
+	return gorm.Open(gormsqlite.New(gormsqlite.Config{
+		DriverName: underlyingDriverName,
+		DSN:        source,
+	}), coredb.GormConfig())
+}
+
+// Source 内存库的连接地址,extra 与 sqlite 驱动一样作为 pragma
+func Source(config *gdb.ConfigNode) (string, error) {
+	name := config.Name
+	if name == "" {
+		name = defaultName
+	}
+	source := fmt.Sprintf("file:%s?mode=memory&cache=shared&_pragma=read_uncommitted(1)", gurl.Encode(name))
+	if config.Extra != "" {
+		extraMap, err := gstr.Parse(config.Extra)
+		if err != nil {
+			return "", err
+		}
+		keys := make([]string, 0, len(extraMap))
+		for k := range extraMap {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			source += fmt.Sprintf(`&_pragma=%s(%s)`, k, gurl.Encode(gconv.String(extraMap[k])))
+		}
+	}
+	return source, nil
+}
+
+// 打开并保持一个连接
+func keep(name, source string) error {
+	keepMu.Lock()
+	defer keepMu.Unlock()
+	if _, ok := keeps[name]; ok {
+		return nil
+	}
+	db, err := sql.Open(underlyingDriverName, source)
+	if err != nil {
+		return err
+	}
+	conn, err := db.Conn(context.Background())
+	if err != nil {
+		return err
+	}
+	keeps[name] = conn
+	return nil
+}
+
+func init() {
+	if err := gdb.Register(DriverName, New()); err != nil {
+		panic(err)
+	}
+	if err := coredb.Register(DriverName, NewGorm()); err != nil {
+		panic(err)
+	}
+}
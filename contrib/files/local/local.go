@@ -36,6 +36,7 @@ func init() {
 			panic(err)
 		}
 	}
+	corefile.RegisterChecker("local", Check)
 
 	g.Log().Debug(ctx, "------------ local init end")
 }
@@ -59,6 +60,19 @@ func NewInit() {
 
 }
 
+// Check 检查上传目录是否可写
+func Check(ctx g.Ctx) (string, error) {
+	tool := util.NewToolUtil()
+	uploadPath := coreconfig.Config.Core.File.UploadPath
+	if !filepath.IsAbs(uploadPath) {
+		uploadPath = filepath.Join(tool.GetRootPath(coreconfig.Config.Core.IsProd, coreconfig.Config.Core.AppName, coreconfig.Config.Core.IsDesktop), uploadPath)
+	}
+	if err := tool.CheckWritable(uploadPath); err != nil {
+		return "", err
+	}
+	return "上传目录: " + uploadPath, nil
+}
+
 func New() corefile.Driver {
 	return &Local{}
 }
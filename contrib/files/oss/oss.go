@@ -5,6 +5,7 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"sort"
 
 	"github.com/gogf/gf/v2/os/gctx"
 
@@ -28,6 +29,10 @@ type Oss struct {
 	Bucket *oss.Bucket
 }
 
+func init() {
+	corefile.RegisterChecker("oss", Check)
+}
+
 func NewInit() {
 	g.Log().Debug(ctx, "------------ oss NewInit start")
 	var (
@@ -88,6 +93,40 @@ func New() corefile.Driver {
 	return &Oss{Client: client, Bucket: bucket}
 }
 
+// Check 检查 oss 配置和存储桶,存储桶不存在时启动会自动创建
+func Check(ctx g.Ctx) (string, error) {
+	var (
+		cfg     = coreconfig.Config.Core.File.Oss
+		missing []string
+	)
+	for key, value := range map[string]string{
+		"endpoint":        cfg.Endpoint,
+		"accessKeyID":     cfg.AccessKeyID,
+		"secretAccessKey": cfg.SecretAccessKey,
+		"bucketName":      cfg.BucketName,
+	} {
+		if value == "" {
+			missing = append(missing, "core.file.oss."+key)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return "", gerror.Newf("缺少配置 %s", gstr.Join(missing, "、"))
+	}
+	client, err := oss.New(cfg.Endpoint, cfg.AccessKeyID, cfg.SecretAccessKey)
+	if err != nil {
+		return "", err
+	}
+	exist, err := client.IsBucketExist(cfg.BucketName)
+	if err != nil {
+		return "", err
+	}
+	if !exist {
+		return fmt.Sprintf("存储桶 %s 不存在,启动时创建", cfg.BucketName), nil
+	}
+	return fmt.Sprintf("存储桶 %s 可以访问", cfg.BucketName), nil
+}
+
 func (m *Oss) GetMode() (data interface{}, err error) {
 	data = g.MapStrStr{
 		"mode": "local",
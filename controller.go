@@ -22,9 +22,12 @@ type IController interface {
 	Add(ctx context.Context, req *AddReq) (res *BaseRes, err error)
 	Delete(ctx context.Context, req *DeleteReq) (res *BaseRes, err error)
 	Update(ctx context.Context, req *UpdateReq) (res *BaseRes, err error)
+	UpdateBatch(ctx context.Context, req *UpdateBatchReq) (res *BaseRes, err error)
+	Upsert(ctx context.Context, req *UpsertReq) (res *BaseRes, err error)
 	Info(ctx context.Context, req *InfoReq) (res *BaseRes, err error)
 	List(ctx context.Context, req *ListReq) (res *BaseRes, err error)
 	Page(ctx context.Context, req *PageReq) (res *BaseRes, err error)
+	Options(ctx context.Context, req *OptionsReq) (res *BaseRes, err error)
 }
 type Controller struct {
 	Prefix  string     `json:"prefix"`
@@ -66,6 +69,16 @@ func (u UpdateReq) Value(key any) any {
 	panic("implement me")
 }
 
+type UpdateBatchReq struct {
+	g.Meta `path:"/updateBatch" method:"POST"`
+	Ids    []string `json:"ids" v:"required#请选择要修改的数据"`
+	Data   g.Map    `json:"data" v:"required#请填写要修改的字段"`
+}
+
+type UpsertReq struct {
+	g.Meta `path:"/upsert" method:"POST"`
+}
+
 type InfoReq struct {
 	g.Meta `path:"/info" method:"GET"`
 	Id     int `json:"id" v:"integer|required#请选择要查询的数据"`
@@ -92,10 +105,36 @@ type PageReq struct {
 	MaxExportLimit int    `json:"maxExportLimit"` // 最大导出条数,不传或者小于等于0则不限制
 }
 
+type OptionsReq struct {
+	g.Meta   `path:"/options" method:"GET"`
+	KeyWord  string `json:"keyWord"`     // 搜索关键字
+	Page     int    `d:"1" json:"page"`  // 页码
+	Size     int    `d:"20" json:"size"` // 每页条数
+	Ids      string `json:"ids"`         // 已选中的值,多个用逗号隔开,不为空时只返回对应的选项
+	Distinct string `json:"distinct"`    // 获取该字段的去重值
+}
+
+// 事务使用 Service 所在分组的数据库
+func (c *Controller) db() gdb.DB {
+	group, _ := c.groupTable()
+	return g.DB(group)
+}
+
+// Service 的分组和表名
+func (c *Controller) groupTable() (group, table string) {
+	if sv, ok := c.Service.(interface{ GetService() *Service }); ok && sv.GetService() != nil {
+		return sv.GetService().groupTable()
+	}
+	if model := c.Service.GetModel(); model != nil {
+		return model.GroupName(), model.TableName()
+	}
+	return
+}
+
 func (c *Controller) Add(ctx context.Context, req *AddReq) (res *BaseRes, err error) {
 	if garray.NewStrArrayFrom(c.Api).Contains("Add") {
 		var data interface{}
-		err = g.DB().Ctx(ctx).Transaction(ctx, func(ctx context.Context, tx gdb.TX) error {
+		err = c.db().Ctx(ctx).Transaction(ctx, func(ctx context.Context, tx gdb.TX) error {
 			err = c.Service.ModifyBefore(ctx, "Add", g.RequestFromCtx(ctx).GetMap())
 			if err != nil {
 				return err
@@ -124,7 +163,7 @@ func (c *Controller) Add(ctx context.Context, req *AddReq) (res *BaseRes, err er
 func (c *Controller) Delete(ctx context.Context, req *DeleteReq) (res *BaseRes, err error) {
 	if garray.NewStrArrayFrom(c.Api).Contains("Delete") {
 		var data interface{}
-		err = g.DB().Ctx(ctx).Transaction(ctx, func(ctx context.Context, tx gdb.TX) error {
+		err = c.db().Ctx(ctx).Transaction(ctx, func(ctx context.Context, tx gdb.TX) error {
 			if err = c.Service.ModifyBefore(ctx, "Delete", g.RequestFromCtx(ctx).GetMap()); err != nil {
 				return err
 			}
@@ -150,7 +189,7 @@ func (c *Controller) Delete(ctx context.Context, req *DeleteReq) (res *BaseRes,
 func (c *Controller) Update(ctx context.Context, req *UpdateReq) (res *BaseRes, err error) {
 	if garray.NewStrArrayFrom(c.Api).Contains("Update") {
 		var data interface{}
-		err = g.DB().Ctx(ctx).Transaction(ctx, func(ctx context.Context, tx gdb.TX) error {
+		err = c.db().Ctx(ctx).Transaction(ctx, func(ctx context.Context, tx gdb.TX) error {
 			if err = c.Service.ModifyBefore(ctx, "Update", g.RequestFromCtx(ctx).GetMap()); err != nil {
 				return err
 			}
@@ -175,6 +214,67 @@ func (c *Controller) Update(ctx context.Context, req *UpdateReq) (res *BaseRes,
 	g.RequestFromCtx(ctx).Response.Status = 404
 	return nil, nil
 }
+func (c *Controller) UpdateBatch(ctx context.Context, req *UpdateBatchReq) (res *BaseRes, err error) {
+	if garray.NewStrArrayFrom(c.Api).Contains("UpdateBatch") {
+		var data interface{}
+		err = c.db().Ctx(ctx).Transaction(ctx, func(ctx context.Context, tx gdb.TX) error {
+			// 每条数据按 Update 调用 ModifyBefore、ModifyAfter
+			params := make([]g.MapStrAny, 0, len(req.Ids))
+			for _, id := range req.Ids {
+				param := g.MapStrAny{}
+				for k, v := range req.Data {
+					param[k] = v
+				}
+				param["id"] = id
+				params = append(params, param)
+			}
+			for _, param := range params {
+				if err = c.Service.ModifyBefore(ctx, "Update", param); err != nil {
+					return err
+				}
+			}
+			if data, err = c.Service.ServiceUpdateBatch(ctx, req); err != nil {
+				return err
+			}
+			for _, param := range params {
+				if err = c.Service.ModifyAfter(ctx, "Update", param); err != nil {
+					return err
+				}
+			}
+			if err = c.Service.CacheDo(ctx, "Update", g.RequestFromCtx(ctx).GetMap()); err != nil {
+				return err
+			}
+			return err
+		})
+		if err != nil {
+			return Fail(err.Error()), err
+		}
+		return Ok(data), err
+	}
+	g.RequestFromCtx(ctx).Response.Status = 404
+	return nil, nil
+}
+func (c *Controller) Upsert(ctx context.Context, req *UpsertReq) (res *BaseRes, err error) {
+	if garray.NewStrArrayFrom(c.Api).Contains("Upsert") {
+		var data interface{}
+		err = c.db().Ctx(ctx).Transaction(ctx, func(ctx context.Context, tx gdb.TX) error {
+			// 每条数据按 Add 或 Update 调用 ModifyBefore、ModifyAfter,由 ServiceUpsert 判断
+			if data, err = c.Service.ServiceUpsert(withModifyHooks(ctx, c.Service), req); err != nil {
+				return err
+			}
+			if err = c.Service.CacheDo(ctx, "Update", g.RequestFromCtx(ctx).GetMap()); err != nil {
+				return err
+			}
+			return err
+		})
+		if err != nil {
+			return Fail(err.Error()), err
+		}
+		return Ok(data), err
+	}
+	g.RequestFromCtx(ctx).Response.Status = 404
+	return nil, nil
+}
 func (c *Controller) Info(ctx context.Context, req *InfoReq) (res *BaseRes, err error) {
 	if garray.NewStrArrayFrom(c.Api).Contains("Info") {
 		data, err := c.Service.ServiceInfo(ctx, req)
@@ -200,6 +300,15 @@ func (c *Controller) Page(ctx context.Context, req *PageReq) (res *BaseRes, err
 	return nil, nil
 }
 
+func (c *Controller) Options(ctx context.Context, req *OptionsReq) (res *BaseRes, err error) {
+	if garray.NewStrArrayFrom(c.Api).Contains("Options") {
+		data, err := c.Service.ServiceOptions(ctx, req)
+		return Ok(data), err
+	}
+	g.RequestFromCtx(ctx).Response.Status = 404
+	return nil, nil
+}
+
 // 添加Controller到Controllers数组
 func AddController(c IController) {
 	Controllers = append(Controllers, c)
@@ -221,10 +330,11 @@ func RegisterController(c IController) {
 		return
 	}
 	if coreconfig.Config.Core.Eps {
-		//dao := sController.Service.GetDao()
-		//columns := getModelInfo(ctx, sController.Prefix, dao)
-		model := sController.Service.GetModel()
-		columns := getModelInfo(ctx, sController.Prefix, model)
+		group, table := sController.groupTable()
+		columns, err := getModelInfo(ctx, group, table)
+		if err != nil {
+			panic(err)
+		}
 		ModelInfo[sController.Prefix] = columns
 	}
 	g.Server().Group(
@@ -249,11 +359,11 @@ type ColumnInfo struct {
 var ModelInfo = make(map[string][]*ColumnInfo)
 
 // getModelInfo 获取模型信息
-func getModelInfo(ctx g.Ctx, prefix string, model IModel) (columns []*ColumnInfo) {
+func getModelInfo(ctx g.Ctx, group, table string) (columns []*ColumnInfo, err error) {
 
-	fields, err := g.DB(model.GroupName()).TableFields(ctx, model.TableName())
+	fields, err := g.DB(group).TableFields(ctx, table)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
 	// RunLogger.Info(ctx, "fields", fields)
 	sortedFields := garray.NewArraySize(len(fields), len(fields))
@@ -278,7 +388,7 @@ func getModelInfo(ctx g.Ctx, prefix string, model IModel) (columns []*ColumnInfo
 		columnType := gstr.Replace(field.(*gdb.TableField).Type, "("+length+")", "")
 		column := &ColumnInfo{
 			Comment:      comment,
-			Length:       "",
+			Length:       length,
 			Nullable:     field.(*gdb.TableField).Null,
 			PropertyName: field.(*gdb.TableField).Name,
 			Type:         columnType,
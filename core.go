@@ -3,13 +3,17 @@ package dzhcore
 import (
 	"context"
 	"path/filepath"
+	"sort"
+	"time"
 
 	"github.com/gzdzh-cn/dzhcore/coreconfig"
+	"github.com/gzdzh-cn/dzhcore/coredb"
 	"github.com/gzdzh-cn/dzhcore/envconfig"
 	"github.com/gzdzh-cn/dzhcore/log"
 	"github.com/gzdzh-cn/dzhcore/utility/env"
 	"github.com/gzdzh-cn/dzhcore/utility/util"
 	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
 
 	"github.com/bwmarrin/snowflake"
 	"github.com/gogf/gf/v2/database/gdb"
@@ -44,6 +48,7 @@ var (
 	RedisConfig    = &gredis.Config{}
 	DbExpire       uint
 	Redis          *gredis.Redis
+	DbGroups       = []string{"default"} // 已配置的数据库分组
 )
 
 func init() {
@@ -102,8 +107,14 @@ func NewInit() {
 		}
 	}
 
+	// 检查数据库完整性
+	checkDatabase()
 	// 创建全部表
 	InitModels()
+	// 定时备份
+	startBackup()
+	// 定时检查数据库连接
+	startDbHealth()
 	// 注册路由
 	RegisterControllers()
 	RegisterControllerSimples()
@@ -124,6 +135,15 @@ func NewInit() {
 
 }
 
+// NewInitDataBase 只初始化配置、日志和数据库连接,不建表、不注册路由,供命令行工具使用
+func NewInitDataBase() {
+	coreconfig.LoadEnv()
+	getConfig()
+	setDataBase()
+	setLogger()
+	NodeSnowflake = CreateSnowflake(ctx)
+}
+
 // 创建雪花ID
 func CreateSnowflakeId() string {
 	return NodeSnowflake.Generate().String()
@@ -167,21 +187,68 @@ func setDataBase() {
 	setSqlLogger()
 }
 
-// database.default 配置
+// 读取 database 下全部分组的配置
 func setDbConfig() {
-	// 读取 database.default 配置
-	dbConfVar, err := g.Cfg().Get(ctx, "database.default")
+	dbConfVar, err := g.Cfg().Get(ctx, "database")
 	if err != nil {
 		g.Log().Error(ctx, "读取数据库配置失败", err)
 		return
 	}
-	if dbConfVar.IsEmpty() {
+	dbMap := dbConfVar.Map()
+	// 未分组的写法视为 default 分组
+	if _, ok := dbMap["link"]; ok {
+		dbMap = g.Map{"default": dbMap}
+	} else if _, ok := dbMap["type"]; ok {
+		dbMap = g.Map{"default": dbMap}
+	}
+	if _, ok := dbMap["default"]; !ok {
 		g.Log().Error(ctx, "未找到数据库配置 database.default")
 		return
 	}
-	var dbNode *gdb.ConfigNode
-	dbConfVar.Struct(&dbNode)
 
+	config := gdb.Config{}
+	for group, value := range dbMap {
+		// database.logger 为 gf 的日志配置
+		if group == "logger" {
+			continue
+		}
+		var (
+			groupVar = g.NewVar(value)
+			nodes    gdb.ConfigGroup
+		)
+		if groupVar.IsSlice() {
+			err = groupVar.Structs(&nodes)
+		} else {
+			var dbNode gdb.ConfigNode
+			err = groupVar.Struct(&dbNode)
+			nodes = append(nodes, dbNode)
+		}
+		if err != nil {
+			g.Log().Errorf(ctx, "读取数据库配置 database.%s 失败: %v", group, err)
+			continue
+		}
+		for i := range nodes {
+			setDbNode(group, &nodes[i])
+		}
+		config[group] = nodes
+	}
+	// 开启SQL观测时包装数据库驱动统计sql
+	if coreconfig.Config.Core.SQLObserver.Enable {
+		observeDrivers(config)
+	}
+	if err = gdb.SetConfig(config); err != nil {
+		g.Log().Error(ctx, "设置数据库配置失败", err)
+		return
+	}
+	DbGroups = make([]string, 0, len(config))
+	for group := range config {
+		DbGroups = append(DbGroups, group)
+	}
+	sort.Strings(DbGroups)
+}
+
+// 处理单个数据库节点的配置
+func setDbNode(group string, dbNode *gdb.ConfigNode) {
 	// sqlite 只需要 type、name、extra、createdAt、updatedAt、deletedAt、debug
 	if dbNode.Type == "sqlite" {
 		dbNode.Host = ""
@@ -192,7 +259,7 @@ func setDbConfig() {
 		dbNode.Timezone = ""
 	}
 
-	if envconfig.IsDesktop && envconfig.IsProd {
+	if dbNode.Type == "sqlite" && envconfig.IsDesktop && envconfig.IsProd {
 		var (
 			source string
 		)
@@ -206,15 +273,12 @@ func setDbConfig() {
 		dbNode.Name = util.NewToolUtil().GetDataBasePath(dbFileName, envconfig.IsProd, envconfig.AppName, envconfig.IsDesktop, source)
 
 	}
-	g.Log().Debugf(ctx, "sqlite sourcePath:%v", dbNode.Name)
-	gdb.SetConfig(gdb.Config{
-		"default": gdb.ConfigGroup{
-			*dbNode,
-		},
-	})
+	if dbNode.Type == "sqlite" {
+		g.Log().Debugf(ctx, "%s sqlite sourcePath:%v", group, dbNode.Name)
+	}
 }
 
-// 设置sql日志
+// 设置sql日志,全部分组共用
 func setSqlLogger() {
 	defaultPath := env.GetCfgWithDefault(ctx, "core.sqlLogger.path", g.NewVar("path")).String()
 	logPath := util.NewToolUtil().GetSqlLoggerPath(envconfig.IsProd, envconfig.AppName, envconfig.IsDesktop, defaultPath)
@@ -229,7 +293,17 @@ func setSqlLogger() {
 	}
 	dbLogger := glog.New()
 	dbLogger.SetConfigWithMap(configMap)
-	g.DB().SetLogger(dbLogger)
+	// gorm 连接使用同一日志,未开启 debug 时与 g.DB() 一致只记录慢查询和错误
+	if coredb.Options.Logger == nil {
+		gormLogger := coredb.NewGormLogger(dbLogger, time.Duration(coreconfig.Config.Core.SQLLogger.SlowThreshold)*time.Millisecond)
+		if !g.DB().GetConfig().Debug {
+			gormLogger.LogLevel = logger.Warn
+		}
+		coredb.Options.Logger = gormLogger
+	}
+	for _, group := range DbGroups {
+		g.DB(group).SetLogger(dbLogger)
+	}
 }
 
 // 自定义日志
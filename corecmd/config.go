@@ -0,0 +1,108 @@
+package corecmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gogf/gf/v2/encoding/gjson"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gcfg"
+	"github.com/gogf/gf/v2/os/gcmd"
+	"github.com/gogf/gf/v2/os/gfile"
+	"github.com/gzdzh-cn/dzhcore/coreconfig"
+)
+
+// 配置来源的显示名称
+var configSources = map[string]string{
+	coreconfig.SourceFile:    "配置文件",
+	coreconfig.SourceEnv:     "环境变量",
+	coreconfig.SourceDotEnv:  ".env",
+	coreconfig.SourceDefault: "默认值",
+}
+
+var (
+	// Config 配置命令
+	Config = &gcmd.Command{
+		Name:  "config",
+		Usage: "config show|schema",
+		Brief: "查看生效的配置和来源,生成配置文件的 JSON Schema",
+	}
+
+	configShow = &gcmd.Command{
+		Name:  "show",
+		Usage: "config show [-k 前缀] [-o 文件]",
+		Brief: "列出生效的配置和来源(配置文件、环境变量、.env、默认值),密钥类的配置项隐藏",
+		Arguments: []gcmd.Argument{
+			{Name: "key", Short: "k", Brief: "只列出该前缀的配置项,如 core.file"},
+			{Name: "output", Short: "o", Brief: "同时以 json 写入文件"},
+		},
+		Func: func(ctx context.Context, parser *gcmd.Parser) (err error) {
+			coreconfig.LoadEnv()
+			items, err := coreconfig.ConfigItems(ctx)
+			if err != nil {
+				return err
+			}
+			if prefix := parser.GetOpt("key").String(); prefix != "" {
+				filtered := items[:0]
+				for _, item := range items {
+					if item.Key == prefix || strings.HasPrefix(item.Key, prefix+".") {
+						filtered = append(filtered, item)
+					}
+				}
+				items = filtered
+			}
+			fmt.Println()
+			if adapter, ok := g.Cfg().GetAdapter().(*gcfg.AdapterFile); ok {
+				path, _ := adapter.GetFilePath()
+				fmt.Printf("配置文件: %s\n", orDash(path))
+			}
+			width := 0
+			for _, item := range items {
+				width = max(width, len(item.Key))
+			}
+			for _, item := range items {
+				source := configSources[item.Source]
+				if item.Source == coreconfig.SourceEnv || item.Source == coreconfig.SourceDotEnv {
+					source += " " + item.Env
+				}
+				fmt.Printf("%-*s  %-30s  %s\n", width, item.Key, gjson.MustEncodeString(item.Value), source)
+			}
+			if output := parser.GetOpt("output").String(); output != "" {
+				content, err := gjson.New(items).ToJsonIndentString()
+				if err != nil {
+					return err
+				}
+				return gfile.PutContents(output, content)
+			}
+			return nil
+		},
+	}
+
+	configSchema = &gcmd.Command{
+		Name:  "schema",
+		Usage: "config schema [-o 文件]",
+		Brief: "按 defineStruct.Config 生成配置文件的 JSON Schema,供编辑器校验和提示",
+		Arguments: []gcmd.Argument{
+			{Name: "output", Short: "o", Brief: "写入的文件,默认 manifest/config/config.schema.json"},
+		},
+		Func: func(ctx context.Context, parser *gcmd.Parser) (err error) {
+			output := parser.GetOpt("output", "manifest/config/config.schema.json").String()
+			content, err := gjson.New(coreconfig.JsonSchema()).ToJsonIndentString()
+			if err != nil {
+				return err
+			}
+			if err = gfile.PutContents(output, content); err != nil {
+				return err
+			}
+			fmt.Printf("已生成 %s\n", output)
+			return nil
+		},
+	}
+)
+
+func init() {
+	if err := Config.AddCommand(configShow, configSchema); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,37 @@
+// Package corecmd 提供可挂载到项目主命令上的 dzhcore 命令行工具,如数据库迁移、表结构对比、种子数据、读取表结构、导出接口描述、检查运行环境、查看配置、生成模拟数据
+//
+//	func init() {
+//		corecmd.AddCommands(&Main)
+//	}
+//
+// 挂载后可通过 go run . migrate up 或 dzhgo migrate up 执行
+package corecmd
+
+import (
+	"github.com/gogf/gf/v2/os/gcmd"
+	"github.com/gzdzh-cn/dzhcore"
+)
+
+var (
+	// Commands 全部 dzhcore 命令
+	Commands = []*gcmd.Command{
+		Migrate,
+		Schema,
+		Seed,
+		Table,
+		Eps,
+		Doctor,
+		Config,
+		Fake,
+	}
+)
+
+// AddCommands 添加 dzhcore 命令到项目的主命令
+func AddCommands(parent *gcmd.Command) error {
+	return parent.AddCommand(Commands...)
+}
+
+// 初始化数据库连接
+func initDataBase() {
+	dzhcore.NewInitDataBase()
+}
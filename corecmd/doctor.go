@@ -0,0 +1,62 @@
+package corecmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/gogf/gf/v2/encoding/gjson"
+	"github.com/gogf/gf/v2/os/gcmd"
+	"github.com/gogf/gf/v2/os/gfile"
+	"github.com/gzdzh-cn/dzhcore"
+)
+
+// 检查结果的显示名称
+var doctorStatus = map[string]string{
+	dzhcore.DoctorPass: "[通过]",
+	dzhcore.DoctorWarn: "[警告]",
+	dzhcore.DoctorFail: "[失败]",
+}
+
+var (
+	// Doctor 检查配置和运行环境命令
+	Doctor = &gcmd.Command{
+		Name:  "doctor",
+		Usage: "doctor [-s] [-o 文件]",
+		Brief: "检查配置、数据库、redis、文件上传和目录权限,存在失败项时退出码为 1",
+		Arguments: []gcmd.Argument{
+			{Name: "strict", Short: "s", Brief: "存在警告时退出码也为 1,用于 CI", Orphan: true},
+			{Name: "output", Short: "o", Brief: "同时以 json 写入文件,供 CI 读取"},
+		},
+		Func: func(ctx context.Context, parser *gcmd.Parser) (err error) {
+			var (
+				list  = dzhcore.RunDoctor(ctx)
+				count = make(map[string]int)
+			)
+			for _, result := range list {
+				count[result.Status]++
+			}
+			fmt.Println()
+			for _, result := range list {
+				fmt.Printf("%s %s: %s\n", doctorStatus[result.Status], result.Name, result.Message)
+				if result.Hint != "" && result.Status != dzhcore.DoctorPass {
+					fmt.Printf("       建议: %s\n", result.Hint)
+				}
+			}
+			fmt.Printf("\n通过 %d 项,警告 %d 项,失败 %d 项\n", count[dzhcore.DoctorPass], count[dzhcore.DoctorWarn], count[dzhcore.DoctorFail])
+			if output := parser.GetOpt("output").String(); output != "" {
+				content, err := gjson.New(list).ToJsonIndentString()
+				if err != nil {
+					return err
+				}
+				if err = gfile.PutContents(output, content); err != nil {
+					return err
+				}
+			}
+			if count[dzhcore.DoctorFail] > 0 || (parser.GetOpt("strict") != nil && count[dzhcore.DoctorWarn] > 0) {
+				os.Exit(1)
+			}
+			return nil
+		},
+	}
+)
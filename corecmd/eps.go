@@ -0,0 +1,39 @@
+package corecmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gogf/gf/v2/encoding/gjson"
+	"github.com/gogf/gf/v2/os/gcmd"
+	"github.com/gogf/gf/v2/os/gfile"
+	"github.com/gzdzh-cn/dzhcore"
+)
+
+var (
+	// Eps 前端接口描述命令
+	Eps = &gcmd.Command{
+		Name:  "eps",
+		Usage: "eps [-o 文件]",
+		Brief: "导出全部控制器开启的接口和模型的列,供 dzhgo eps 生成 TypeScript 类型和请求方法",
+		Arguments: []gcmd.Argument{
+			{Name: "output", Short: "o", Brief: "以 json 写入文件,为空时打印"},
+		},
+		Func: func(ctx context.Context, parser *gcmd.Parser) (err error) {
+			initDataBase()
+			list, err := dzhcore.GetEps(ctx)
+			if err != nil {
+				return err
+			}
+			content, err := gjson.New(list).ToJsonIndentString()
+			if err != nil {
+				return err
+			}
+			if output := parser.GetOpt("output").String(); output != "" {
+				return gfile.PutContents(output, content)
+			}
+			fmt.Println(content)
+			return nil
+		},
+	}
+)
@@ -0,0 +1,56 @@
+package corecmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/os/gcmd"
+	"github.com/gogf/gf/v2/text/gstr"
+	"github.com/gzdzh-cn/dzhcore"
+	"github.com/gzdzh-cn/dzhcore/coreconfig"
+)
+
+var (
+	// Fake 模拟数据命令
+	Fake = &gcmd.Command{
+		Name:  "fake",
+		Usage: "fake -m 模型 [-n 条数] [-b 每批条数] [-s 随机数种子]",
+		Brief: "按模型的结构和列注释生成模拟数据,用于演示和压测",
+		Arguments: []gcmd.Argument{
+			{Name: "model", Short: "m", Brief: "表名或模型结构体名,多个用逗号分隔,按顺序生成,被关联的模型放在前面"},
+			{Name: "count", Short: "n", Brief: "每个模型生成的条数,默认 100"},
+			{Name: "batch", Short: "b", Brief: "每批插入的条数,默认 500"},
+			{Name: "seed", Short: "s", Brief: "随机数种子,相同的种子生成相同的数据"},
+		},
+		Func: func(ctx context.Context, parser *gcmd.Parser) (err error) {
+			names := gstr.SplitAndTrim(parser.GetOpt("model").String(), ",")
+			if len(names) == 0 {
+				return gerror.New("请使用 -m 指定模型")
+			}
+			initDataBase()
+			if coreconfig.Config.Core.AutoMigrate {
+				dzhcore.AutoMigrateModels()
+			}
+			opt := &dzhcore.FakeOption{
+				Count:     parser.GetOpt("count", 100).Int(),
+				BatchSize: parser.GetOpt("batch").Int(),
+				Seed:      parser.GetOpt("seed").Int64(),
+			}
+			for _, name := range names {
+				model, err := dzhcore.FindModel(name)
+				if err != nil {
+					return err
+				}
+				start := time.Now()
+				count, err := dzhcore.FakeModel(ctx, model, opt)
+				if err != nil {
+					return err
+				}
+				fmt.Printf("已生成 %s %d 条,耗时 %s\n", model.TableName(), count, time.Since(start).Round(time.Millisecond))
+			}
+			return nil
+		},
+	}
+)
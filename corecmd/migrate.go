@@ -0,0 +1,106 @@
+package corecmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gogf/gf/v2/os/gcmd"
+	"github.com/gzdzh-cn/dzhcore"
+	"github.com/gzdzh-cn/dzhcore/coreconfig"
+)
+
+var (
+	// Migrate 数据库迁移命令
+	Migrate = &gcmd.Command{
+		Name:  "migrate",
+		Usage: "migrate up|down|status",
+		Brief: "数据库迁移",
+	}
+
+	migrateUp = &gcmd.Command{
+		Name:  "up",
+		Usage: "migrate up [-n 步数]",
+		Brief: "执行未执行的迁移,开启 core.autoMigrate 时先自动建表",
+		Arguments: []gcmd.Argument{
+			{Name: "steps", Short: "n", Brief: "最多执行的迁移个数,默认全部"},
+		},
+		Func: func(ctx context.Context, parser *gcmd.Parser) (err error) {
+			initDataBase()
+			if coreconfig.Config.Core.AutoMigrate {
+				dzhcore.AutoMigrateModels()
+			}
+			versions, err := dzhcore.MigrateUp(ctx, parser.GetOpt("steps").Int())
+			for _, version := range versions {
+				fmt.Printf("已执行: %s\n", version)
+			}
+			if err != nil {
+				return err
+			}
+			if len(versions) == 0 {
+				fmt.Println("没有需要执行的迁移")
+			}
+			return nil
+		},
+	}
+
+	migrateDown = &gcmd.Command{
+		Name:  "down",
+		Usage: "migrate down [-n 步数]",
+		Brief: "回滚最近执行的迁移",
+		Arguments: []gcmd.Argument{
+			{Name: "steps", Short: "n", Brief: "回滚的迁移个数,默认 1"},
+		},
+		Func: func(ctx context.Context, parser *gcmd.Parser) (err error) {
+			initDataBase()
+			versions, err := dzhcore.MigrateDown(ctx, parser.GetOpt("steps").Int())
+			for _, version := range versions {
+				fmt.Printf("已回滚: %s\n", version)
+			}
+			if err != nil {
+				return err
+			}
+			if len(versions) == 0 {
+				fmt.Println("没有可以回滚的迁移")
+			}
+			return nil
+		},
+	}
+
+	migrateStatus = &gcmd.Command{
+		Name:  "status",
+		Usage: "migrate status",
+		Brief: "查看迁移执行状态",
+		Func: func(ctx context.Context, parser *gcmd.Parser) (err error) {
+			initDataBase()
+			list, err := dzhcore.GetMigrationStatus(ctx)
+			if err != nil {
+				return err
+			}
+			if len(list) == 0 {
+				fmt.Println("没有注册任何迁移")
+				return nil
+			}
+			fmt.Printf("%-8s %-6s %-20s %-12s %-10s %s\n", "状态", "批次", "执行时间", "模块", "分组", "版本")
+			for _, item := range list {
+				var (
+					status    = "未执行"
+					appliedAt = "-"
+				)
+				if item.Applied {
+					status = "已执行"
+					if item.AppliedAt != nil {
+						appliedAt = item.AppliedAt.String()
+					}
+				}
+				fmt.Printf("%-8s %-6d %-20s %-12s %-10s %s\n", status, item.Batch, appliedAt, item.Module, item.Group, item.Version)
+			}
+			return nil
+		},
+	}
+)
+
+func init() {
+	if err := Migrate.AddCommand(migrateUp, migrateDown, migrateStatus); err != nil {
+		panic(err)
+	}
+}
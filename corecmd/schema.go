@@ -0,0 +1,73 @@
+package corecmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/os/gcmd"
+	"github.com/gzdzh-cn/dzhcore"
+)
+
+var (
+	// Schema 表结构命令
+	Schema = &gcmd.Command{
+		Name:  "schema",
+		Usage: "schema diff",
+		Brief: "表结构工具",
+	}
+
+	schemaDiff = &gcmd.Command{
+		Name:  "diff",
+		Usage: "schema diff [-s]",
+		Brief: "对比已注册模型与数据库表结构,只读取不修改",
+		Arguments: []gcmd.Argument{
+			{Name: "strict", Short: "s", Brief: "存在破坏性差异时返回错误,用于部署前检查", Orphan: true},
+		},
+		Func: func(ctx context.Context, parser *gcmd.Parser) (err error) {
+			initDataBase()
+			list, err := dzhcore.DiffSchema(ctx)
+			if err != nil {
+				return err
+			}
+			if len(list) == 0 {
+				fmt.Println("模型与数据库表结构一致")
+				return nil
+			}
+			destructive := 0
+			for _, diff := range list {
+				fmt.Printf("[%s] %s\n", diff.Group, diff.Table)
+				for _, change := range diff.Changes {
+					flag := " "
+					if change.Destructive {
+						flag = "!"
+						destructive++
+					}
+					fmt.Printf("  %s %-16s %-24s %s", flag, change.Kind, change.Name, change.Message)
+					if change.Expected != "" || change.Actual != "" {
+						fmt.Printf(" (数据库: %s, 模型: %s)", orDash(change.Actual), orDash(change.Expected))
+					}
+					fmt.Println()
+				}
+			}
+			fmt.Printf("共 %d 张表存在差异,破坏性变更 %d 项(以 ! 标记)\n", len(list), destructive)
+			if destructive > 0 && parser.GetOpt("strict") != nil {
+				return gerror.Newf("存在 %d 项破坏性变更", destructive)
+			}
+			return nil
+		},
+	}
+)
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func init() {
+	if err := Schema.AddCommand(schemaDiff); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,175 @@
+package corecmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/os/gcmd"
+	"github.com/gogf/gf/v2/os/gfile"
+	"github.com/gogf/gf/v2/text/gstr"
+	"github.com/gzdzh-cn/dzhcore"
+	"github.com/gzdzh-cn/dzhcore/coreconfig"
+)
+
+var (
+	// Seed 种子数据命令
+	Seed = &gcmd.Command{
+		Name:  "seed",
+		Usage: "seed apply|status|export|import",
+		Brief: "种子数据",
+	}
+
+	seedApply = &gcmd.Command{
+		Name:  "apply",
+		Usage: "seed apply [-n 名称] [-f]",
+		Brief: "执行版本变化的种子数据,开启 core.autoMigrate 时先自动建表",
+		Arguments: []gcmd.Argument{
+			{Name: "name", Short: "n", Brief: "只执行指定的种子数据,多个用逗号分隔,格式为 名称 或 模块/名称"},
+			{Name: "force", Short: "f", Brief: "忽略版本号重新执行", Orphan: true},
+		},
+		Func: func(ctx context.Context, parser *gcmd.Parser) (err error) {
+			initDataBase()
+			if coreconfig.Config.Core.AutoMigrate {
+				dzhcore.AutoMigrateModels()
+			}
+			names := gstr.SplitAndTrim(parser.GetOpt("name").String(), ",")
+			applied, err := dzhcore.ApplySeeds(ctx, parser.GetOpt("force") != nil, names...)
+			for _, name := range applied {
+				fmt.Printf("已执行: %s\n", name)
+			}
+			if err != nil {
+				return err
+			}
+			if len(applied) == 0 {
+				fmt.Println("没有需要执行的种子数据")
+			}
+			return nil
+		},
+	}
+
+	seedStatus = &gcmd.Command{
+		Name:  "status",
+		Usage: "seed status",
+		Brief: "查看种子数据执行状态",
+		Func: func(ctx context.Context, parser *gcmd.Parser) (err error) {
+			initDataBase()
+			list, err := dzhcore.GetSeedStatus(ctx)
+			if err != nil {
+				return err
+			}
+			if len(list) == 0 {
+				fmt.Println("没有注册任何种子数据")
+				return nil
+			}
+			fmt.Printf("%-8s %-6s %-20s %-12s %-10s %s\n", "状态", "条数", "执行时间", "模块", "分组", "名称")
+			for _, item := range list {
+				var (
+					status    = "已执行"
+					appliedAt = "-"
+				)
+				if item.AppliedVersion == "" {
+					status = "未执行"
+				} else if item.Pending() {
+					status = "有更新"
+				}
+				if item.AppliedAt != nil {
+					appliedAt = item.AppliedAt.String()
+				}
+				fmt.Printf("%-8s %-6d %-20s %-12s %-10s %s\n", status, item.Rows, appliedAt, item.Module, item.Group, item.Name)
+			}
+			return nil
+		},
+	}
+
+	seedExport = &gcmd.Command{
+		Name:  "export",
+		Usage: "seed export -t 表名 [-w 条件] [-a 插件] [-o 文件] [-e 列] [-g 分组] [--keep-time]",
+		Brief: "导出表中的数据为种子数据文件,默认写入 internal/resource/initjson/<表名>.json",
+		Arguments: []gcmd.Argument{
+			{Name: "table", Short: "t", Brief: "表名"},
+			{Name: "where", Short: "w", Brief: "条件,如 \"status = 1\""},
+			{Name: "addons", Short: "a", Brief: "写入插件的 resource/initjson 目录"},
+			{Name: "output", Short: "o", Brief: "写入的文件,优先于 -a"},
+			{Name: "exclude", Short: "e", Brief: "不导出的列,多个用逗号分隔"},
+			{Name: "order", Brief: "排序,默认按 id"},
+			{Name: "group", Short: "g", Brief: "数据库分组,默认 default"},
+			{Name: "keep-time", Brief: "同时导出创建、更新、删除时间列", Orphan: true},
+		},
+		Func: func(ctx context.Context, parser *gcmd.Parser) (err error) {
+			table := parser.GetOpt("table").String()
+			if table == "" {
+				return gerror.New("请使用 -t 指定表名")
+			}
+			initDataBase()
+			rows, err := dzhcore.ExportSeed(ctx, &dzhcore.SeedExportOption{
+				Group:    parser.GetOpt("group").String(),
+				Table:    table,
+				Where:    parser.GetOpt("where").String(),
+				Order:    parser.GetOpt("order").String(),
+				Exclude:  gstr.SplitAndTrim(parser.GetOpt("exclude").String(), ","),
+				KeepTime: parser.GetOpt("keep-time") != nil,
+			})
+			if err != nil {
+				return err
+			}
+			output := parser.GetOpt("output").String()
+			if output == "" {
+				dir := "internal"
+				if addons := parser.GetOpt("addons").String(); addons != "" {
+					dir = "addons/" + addons
+				}
+				output = dir + "/resource/initjson/" + table + ".json"
+			}
+			content, err := json.MarshalIndent(rows, "", "\t")
+			if err != nil {
+				return err
+			}
+			if err = gfile.PutBytes(output, append(content, '\n')); err != nil {
+				return err
+			}
+			fmt.Printf("已导出 %d 条数据: %s\n", len(rows), output)
+			return nil
+		},
+	}
+
+	seedImport = &gcmd.Command{
+		Name:  "import",
+		Usage: "seed import -i 文件 [-t 表名] [-k 唯一键] [-g 分组]",
+		Brief: "导入种子数据文件,按唯一键新增或更新,不写入执行记录",
+		Arguments: []gcmd.Argument{
+			{Name: "input", Short: "i", Brief: "数据文件,支持 json、yaml、yml、csv"},
+			{Name: "table", Short: "t", Brief: "表名,默认为文件名"},
+			{Name: "keys", Short: "k", Brief: "唯一键,多个用逗号分隔,默认 id"},
+			{Name: "group", Short: "g", Brief: "数据库分组,默认 default"},
+		},
+		Func: func(ctx context.Context, parser *gcmd.Parser) (err error) {
+			input := parser.GetOpt("input").String()
+			if input == "" {
+				return gerror.New("请使用 -i 指定数据文件")
+			}
+			if !gfile.Exists(input) {
+				return gerror.Newf("文件不存在: %s", input)
+			}
+			rows, err := dzhcore.ParseSeedFile(input, gfile.GetBytes(input))
+			if err != nil {
+				return err
+			}
+			table := parser.GetOpt("table", gfile.Name(input)).String()
+			initDataBase()
+			keys := gstr.SplitAndTrim(parser.GetOpt("keys").String(), ",")
+			if err = dzhcore.ImportSeed(ctx, parser.GetOpt("group").String(), table, rows, keys...); err != nil {
+				return err
+			}
+			fmt.Printf("已导入 %d 条数据到 %s\n", len(rows), table)
+			return nil
+		},
+	}
+)
+
+func init() {
+	if err := Seed.AddCommand(seedApply, seedStatus, seedExport, seedImport); err != nil {
+		panic(err)
+	}
+}
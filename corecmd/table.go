@@ -0,0 +1,100 @@
+package corecmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gogf/gf/v2/encoding/gjson"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/os/gcmd"
+	"github.com/gogf/gf/v2/os/gfile"
+	"github.com/gogf/gf/v2/text/gstr"
+	"github.com/gzdzh-cn/dzhcore"
+)
+
+var (
+	// Table 数据表命令
+	Table = &gcmd.Command{
+		Name:  "table",
+		Usage: "table inspect",
+		Brief: "数据表工具",
+	}
+
+	tableInspect = &gcmd.Command{
+		Name:  "inspect",
+		Usage: "table inspect -n 表名 | -A [-g 分组] [-o 文件]",
+		Brief: "读取数据库中已有表的列、类型、是否可空、默认值、注释和索引",
+		Arguments: []gcmd.Argument{
+			{Name: "name", Short: "n", Brief: "表名,多个用逗号分隔"},
+			{Name: "all", Short: "A", Brief: "全部业务表,不包括 dzhcore 内置表", Orphan: true},
+			{Name: "group", Short: "g", Brief: "数据库分组,默认 default"},
+			{Name: "output", Short: "o", Brief: "以 json 写入文件,供 dzhgo gen 使用"},
+		},
+		Func: func(ctx context.Context, parser *gcmd.Parser) (err error) {
+			var (
+				group = parser.GetOpt("group").String()
+				names = gstr.SplitAndTrim(parser.GetOpt("name").String(), ",")
+			)
+			if len(names) == 0 && parser.GetOpt("all") == nil {
+				return gerror.New("请使用 -n 指定表名或 -A 读取全部表")
+			}
+			initDataBase()
+			if len(names) == 0 {
+				if names, err = dzhcore.GetTableNames(ctx, group); err != nil {
+					return err
+				}
+			}
+			list := make([]*dzhcore.TableInfo, 0, len(names))
+			for _, name := range names {
+				info, err := dzhcore.GetTableInfo(ctx, group, name)
+				if err != nil {
+					return err
+				}
+				list = append(list, info)
+			}
+
+			if output := parser.GetOpt("output").String(); output != "" {
+				content, err := gjson.New(list).ToJsonIndentString()
+				if err != nil {
+					return err
+				}
+				return gfile.PutContents(output, content)
+			}
+			for _, info := range list {
+				fmt.Printf("[%s] %s %s\n", info.Group, info.Name, info.Comment)
+				for _, column := range info.Columns {
+					flag := " "
+					if column.PrimaryKey {
+						flag = "*"
+					}
+					nullable := "NOT NULL"
+					if column.Nullable {
+						nullable = "NULL"
+					}
+					fmt.Printf("  %s %-24s %-20s %-8s", flag, column.Name, column.ColumnType, nullable)
+					if column.HasDefault {
+						fmt.Printf(" 默认: %s", column.Default)
+					}
+					if column.Comment != "" {
+						fmt.Printf(" %s", column.Comment)
+					}
+					fmt.Println()
+				}
+				for _, index := range info.Indexes {
+					kind := "INDEX"
+					if index.Unique {
+						kind = "UNIQUE"
+					}
+					fmt.Printf("    %-6s %s (%s)\n", kind, index.Name, gstr.Join(index.Columns, ","))
+				}
+			}
+			return nil
+		},
+	}
+)
+
+func init() {
+	if err := Table.AddCommand(tableInspect); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,221 @@
+package coreconfig
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gzdzh-cn/dzhcore/defineStruct"
+)
+
+// 配置检查,按 defineStruct.Config 的 yaml 标签检查配置文件中的配置项
+
+const (
+	IssueWarn = "warn" // 未知的配置项
+	IssueFail = "fail" // 类型不正确
+)
+
+// 允许有其他配置项的节点,如 gf 的 server、database 配置和业务模块的配置
+var openKeys = []string{"server", "database", "modules"}
+
+// ConfigIssue 配置项的问题
+type ConfigIssue struct {
+	Key     string `json:"key"`     // 配置项,如 core.file.mode
+	Level   string `json:"level"`   // warn、fail
+	Message string `json:"message"` // 说明
+}
+
+// CheckConfig 检查配置文件中的配置项,未知的配置项为警告,类型不正确为错误
+func CheckConfig(ctx context.Context) (issues []*ConfigIssue, err error) {
+	data, err := g.Cfg().Data(ctx)
+	if err != nil {
+		return nil, err
+	}
+	fields := structFields(reflect.TypeOf(defineStruct.Config{}))
+	for _, key := range sortedKeys(data) {
+		// 顶层的其他配置项如 logger 由 gf 使用
+		if field, ok := fields[key]; ok {
+			issues = append(issues, checkValue(key, data[key], field.Type)...)
+		}
+	}
+	return
+}
+
+// 结构体的配置项,key 为 yaml 标签,嵌入的结构体与 newConfig 一致展开到当前层级
+func structFields(t reflect.Type) map[string]reflect.StructField {
+	fields := make(map[string]reflect.StructField)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			for key, f := range structFields(field.Type) {
+				fields[key] = f
+			}
+			continue
+		}
+		key, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if key == "" || key == "-" {
+			continue
+		}
+		fields[key] = field
+	}
+	return fields
+}
+
+func checkValue(key string, value interface{}, t reflect.Type) (issues []*ConfigIssue) {
+	fail := func(format string, args ...interface{}) []*ConfigIssue {
+		return []*ConfigIssue{{Key: key, Level: IssueFail, Message: fmt.Sprintf(format, args...)}}
+	}
+	if value == nil {
+		return nil
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		data, ok := value.(map[string]interface{})
+		if !ok {
+			return fail("应为对象,当前为 %v", value)
+		}
+		// database 下为分组,每个分组为一个或多个节点,未分组时为 default 分组
+		if key == "database" {
+			if _, ok = data["type"]; !ok {
+				if _, ok = data["link"]; !ok {
+					return checkDatabaseGroups(data, t)
+				}
+			}
+		}
+		return checkStruct(key, data, t)
+	case reflect.Bool:
+		switch v := value.(type) {
+		case bool:
+			return nil
+		case string:
+			if _, err := strconv.ParseBool(v); err == nil {
+				return nil
+			}
+		// 配置文件中的数字读取为 json.Number
+		case int, int64, uint64, float64, json.Number:
+			if n := fmt.Sprint(v); n == "0" || n == "1" {
+				return nil
+			}
+		}
+		return fail("应为 true 或 false,当前为 %v", value)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseFloat(strings.TrimSpace(fmt.Sprint(value)), 64)
+		if err != nil || n != float64(int64(n)) {
+			return fail("应为整数,当前为 %v", value)
+		}
+		if n < 0 && t.Kind() >= reflect.Uint {
+			return fail("不能为负数,当前为 %v", value)
+		}
+	case reflect.String:
+		switch value.(type) {
+		case map[string]interface{}, []interface{}:
+			return fail("应为字符串,当前为 %v", value)
+		}
+	case reflect.Slice:
+		if _, ok := value.(map[string]interface{}); ok {
+			return fail("应为列表,当前为 %v", value)
+		}
+	}
+	return nil
+}
+
+func checkStruct(key string, data map[string]interface{}, t reflect.Type) (issues []*ConfigIssue) {
+	fields := structFields(t)
+	for _, name := range sortedKeys(data) {
+		if field, ok := fields[name]; ok {
+			issues = append(issues, checkValue(key+"."+name, data[name], field.Type)...)
+			continue
+		}
+		if isOpenKey(key) {
+			continue
+		}
+		message := "未知的配置项,不会生效"
+		if similar := similarKey(name, fields); similar != "" {
+			message += fmt.Sprintf(",是否为 %s.%s", key, similar)
+		}
+		issues = append(issues, &ConfigIssue{Key: key + "." + name, Level: IssueWarn, Message: message})
+	}
+	return
+}
+
+func checkDatabaseGroups(data map[string]interface{}, t reflect.Type) (issues []*ConfigIssue) {
+	for _, group := range sortedKeys(data) {
+		// database.logger 为 gf 的日志配置
+		if group == "logger" {
+			continue
+		}
+		key := "database." + group
+		switch nodes := data[group].(type) {
+		case map[string]interface{}:
+			issues = append(issues, checkStruct(key, nodes, t)...)
+		case []interface{}:
+			for i, node := range nodes {
+				issues = append(issues, checkValue(fmt.Sprintf("%s.%d", key, i), node, t)...)
+			}
+		default:
+			issues = append(issues, &ConfigIssue{Key: key, Level: IssueFail, Message: fmt.Sprintf("应为数据库节点配置,当前为 %v", nodes)})
+		}
+	}
+	return
+}
+
+func isOpenKey(key string) bool {
+	for _, open := range openKeys {
+		if key == open || strings.HasPrefix(key, open+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// 相似的配置项,忽略大小写相同或编辑距离不超过 2
+func similarKey(name string, fields map[string]reflect.StructField) (similar string) {
+	best := 3
+	for key := range fields {
+		if strings.EqualFold(key, name) {
+			return key
+		}
+		if d := editDistance(strings.ToLower(key), strings.ToLower(name)); d < best || (d == best && key < similar) {
+			best, similar = d, key
+		}
+	}
+	if best > 2 {
+		return ""
+	}
+	return
+}
+
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+func sortedKeys(data map[string]interface{}) []string {
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,97 @@
+package coreconfig_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gcfg"
+	"github.com/gzdzh-cn/dzhcore/coreconfig"
+)
+
+// 使用 yaml 内容作为配置
+func setConfig(t *testing.T, content string) {
+	t.Helper()
+	adapter, err := gcfg.NewAdapterContent(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	old := g.Cfg().GetAdapter()
+	g.Cfg().SetAdapter(adapter)
+	t.Cleanup(func() { g.Cfg().SetAdapter(old) })
+}
+
+func TestCheckConfig(t *testing.T) {
+	setConfig(t, `
+logger:
+  level: all
+server:
+  anything: 1
+database:
+  default:
+    type: sqlite
+    debug: "yes"
+  log:
+    - type: mysql
+      port: 3306
+    - type: mysql
+      debug: [1]
+      other: 1
+  bad: 1
+core:
+  autoMigrate: "true"
+  autoMigrat: true
+  eps: 1
+  file:
+    mode: [local]
+  seed:
+    autoRun: true
+  sqlObserver:
+    maxStats: -1
+modules:
+  shop:
+    anything: 1
+redis:
+  enable: ok
+`)
+	issues, err := coreconfig.CheckConfig(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, issue := range issues {
+		got = append(got, issue.Level+" "+issue.Key)
+	}
+	want := []string{
+		"warn core.autoMigrat",
+		"fail core.file.mode",
+		"fail database.bad",
+		"fail database.default.debug",
+		"fail database.log.1.debug",
+		"fail redis.enable",
+	}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("检查结果:\n%s\n期望:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if !strings.Contains(issues[0].Message, "core.autoMigrate") {
+		t.Fatalf("没有提示相似的配置项: %s", issues[0].Message)
+	}
+}
+
+// 未分组的数据库配置视为 default 分组,gf 的其他节点配置不提示
+func TestCheckConfigDatabaseNode(t *testing.T) {
+	setConfig(t, `
+database:
+  type: sqlite
+  maxIdle: 10
+  debug: 2
+`)
+	issues, err := coreconfig.CheckConfig(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) != 1 || issues[0].Key != "database.debug" || issues[0].Level != coreconfig.IssueFail {
+		t.Fatalf("检查结果 %+v", issues)
+	}
+}
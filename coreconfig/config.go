@@ -1,6 +1,8 @@
 package coreconfig
 
 import (
+	"os"
+
 	"github.com/gogf/gf/v2/frame/g"
 	"github.com/gogf/gf/v2/os/gctx"
 	"github.com/gzdzh-cn/dzhcore/defineStruct"
@@ -17,11 +19,31 @@ func init() {
 	Config = newConfig()
 }
 
+// Reload 重新读取配置,替换 g.Cfg() 的配置来源后调用
+func Reload() {
+	Config = newConfig()
+}
+
+// 从 .env 读取的环境变量,已存在的环境变量不会被 .env 覆盖
+var dotEnvKeys = make(map[string]bool)
+
+// LoadEnv 读取 .env 文件到环境变量,读取后重新生成配置,使配置文件中没有的项可以从 .env 中读取
 func LoadEnv() {
-	err := godotenv.Load()
+	values, err := godotenv.Read()
 	if err != nil {
 		g.Log().Debug(ctx, "未找到.env文件，使用默认环境变量")
+		return
+	}
+	for key := range values {
+		if _, ok := os.LookupEnv(key); !ok {
+			dotEnvKeys[key] = true
+		}
+	}
+	if err = godotenv.Load(); err != nil {
+		g.Log().Debug(ctx, "读取.env文件失败", err)
+		return
 	}
+	Reload()
 }
 
 // NewConfig new config
@@ -84,11 +106,19 @@ func newConfig() *defineStruct.Config {
 				Enable: env.GetCfgWithDefault(ctx, "core.notice.enable", g.NewVar(true)).Bool(),
 				Send:   env.GetCfgWithDefault(ctx, "core.notice.send", g.NewVar(5)).Int(),
 			},
-			SQLLogger: defineStruct.LoggerConfig{
-				Path:   env.GetCfgWithDefault(ctx, "core.sqlLogger.path", g.NewVar("./data/logs/sql")).String(),
-				File:   env.GetCfgWithDefault(ctx, "core.sqlLogger.file", g.NewVar("sql-{Y-m-d}.log")).String(),
-				Level:  env.GetCfgWithDefault(ctx, "core.sqlLogger.level", g.NewVar("all")).String(),
-				Stdout: env.GetCfgWithDefault(ctx, "core.sqlLogger.stdout", g.NewVar(false)).Bool(),
+			SQLLogger: defineStruct.SQLLogger{
+				Path:          env.GetCfgWithDefault(ctx, "core.sqlLogger.path", g.NewVar("./data/logs/sql")).String(),
+				File:          env.GetCfgWithDefault(ctx, "core.sqlLogger.file", g.NewVar("sql-{Y-m-d}.log")).String(),
+				Level:         env.GetCfgWithDefault(ctx, "core.sqlLogger.level", g.NewVar("all")).String(),
+				Stdout:        env.GetCfgWithDefault(ctx, "core.sqlLogger.stdout", g.NewVar(false)).Bool(),
+				SlowThreshold: env.GetCfgWithDefault(ctx, "core.sqlLogger.slowThreshold", g.NewVar(500)).Int(),
+			},
+			SQLObserver: defineStruct.SQLObserver{
+				Enable:        env.GetCfgWithDefault(ctx, "core.sqlObserver.enable", g.NewVar(false)).Bool(),
+				SlowThreshold: env.GetCfgWithDefault(ctx, "core.sqlObserver.slowThreshold", g.NewVar(500)).Int(),
+				NPlusOne:      env.GetCfgWithDefault(ctx, "core.sqlObserver.nPlusOne", g.NewVar(5)).Int(),
+				TopSize:       env.GetCfgWithDefault(ctx, "core.sqlObserver.topSize", g.NewVar(20)).Int(),
+				MaxStats:      env.GetCfgWithDefault(ctx, "core.sqlObserver.maxStats", g.NewVar(1000)).Int(),
 			},
 			GFLogger: defineStruct.LoggerConfig{
 				Path:   env.GetCfgWithDefault(ctx, "core.gfLogger.path", g.NewVar("./data/logs/")).String(),
@@ -108,6 +138,34 @@ func newConfig() *defineStruct.Config {
 				Enable:     env.GetCfgWithDefault(ctx, "core.runLogger.enable", g.NewVar(true)).Bool(),
 				RotateSize: env.GetCfgWithDefault(ctx, "core.runLogger.rotateSize", g.NewVar("3MB")).String(),
 			},
+			Dict: defineStruct.DictConfig{
+				Enable: env.GetCfgWithDefault(ctx, "core.dict.enable", g.NewVar(false)).Bool(),
+				Expire: env.GetCfgWithDefault(ctx, "core.dict.expire", g.NewVar(0)).Int(),
+			},
+			Migration: defineStruct.MigrationConfig{
+				AutoRun:     env.GetCfgWithDefault(ctx, "core.migration.autoRun", g.NewVar(true)).Bool(),
+				LockTimeout: env.GetCfgWithDefault(ctx, "core.migration.lockTimeout", g.NewVar(600)).Int(),
+			},
+			SchemaCheck: defineStruct.SchemaCheckConfig{
+				Enable: env.GetCfgWithDefault(ctx, "core.schemaCheck.enable", g.NewVar(false)).Bool(),
+			},
+			Seed: defineStruct.SeedConfig{
+				AutoRun: env.GetCfgWithDefault(ctx, "core.seed.autoRun", g.NewVar(true)).Bool(),
+			},
+			Backup: defineStruct.BackupConfig{
+				Enable:       env.GetCfgWithDefault(ctx, "core.backup.enable", g.NewVar(false)).Bool(),
+				Cron:         env.GetCfgWithDefault(ctx, "core.backup.cron", g.NewVar("@every 6h")).String(),
+				Keep:         env.GetCfgWithDefault(ctx, "core.backup.keep", g.NewVar(7)).Int(),
+				Path:         env.GetCfgWithDefault(ctx, "core.backup.path", g.NewVar("./data/backup")).String(),
+				CheckOnStart: env.GetCfgWithDefault(ctx, "core.backup.checkOnStart", g.NewVar(true)).Bool(),
+				AutoRestore:  env.GetCfgWithDefault(ctx, "core.backup.autoRestore", g.NewVar(true)).Bool(),
+			},
+			DbHealth: defineStruct.DbHealthConfig{
+				Enable:         env.GetCfgWithDefault(ctx, "core.dbHealth.enable", g.NewVar(true)).Bool(),
+				Interval:       env.GetCfgWithDefault(ctx, "core.dbHealth.interval", g.NewVar(30)).Int(),
+				Timeout:        env.GetCfgWithDefault(ctx, "core.dbHealth.timeout", g.NewVar(3)).Int(),
+				ReconnectAfter: env.GetCfgWithDefault(ctx, "core.dbHealth.reconnectAfter", g.NewVar(3)).Int(),
+			},
 			File: defineStruct.FileConfig{
 				Mode:       env.GetCfgWithDefault(ctx, "core.file.mode", g.NewVar("local")).String(),
 				FilePrefix: env.GetCfgWithDefault(ctx, "core.file.filePrefix", g.NewVar("")).String(),
@@ -0,0 +1,95 @@
+package coreconfig
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gzdzh-cn/dzhcore/defineStruct"
+	"github.com/gzdzh-cn/dzhcore/utility/env"
+)
+
+// 配置文件的 JSON Schema,按 defineStruct.Config 的 yaml 标签生成,供编辑器校验和提示
+
+// SchemaDraft JSON Schema 版本
+const SchemaDraft = "http://json-schema.org/draft-07/schema#"
+
+// JsonSchema 生成配置文件的 JSON Schema,说明为字段注释,默认值为 newConfig 中的默认值
+func JsonSchema() map[string]interface{} {
+	schema := structSchema("", reflect.TypeOf(defineStruct.Config{}), defineStruct.Comments(), env.Defaults())
+	schema["$schema"] = SchemaDraft
+	schema["title"] = "dzhcore config"
+	// 顶层的其他配置项如 logger 由 gf 使用
+	schema["additionalProperties"] = true
+	return schema
+}
+
+func structSchema(key string, t reflect.Type, comments map[string]string, defaults map[string]*g.Var) map[string]interface{} {
+	properties := make(map[string]interface{})
+	addFields(key, t, properties, comments, defaults)
+	schema := map[string]interface{}{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": isOpenKey(key),
+	}
+	// database 可以为单个节点,或按分组配置,每个分组为一个或多个节点
+	if key == "database" {
+		node := structSchema("database.default", t, comments, nil)
+		return map[string]interface{}{
+			"anyOf": []interface{}{
+				schema,
+				map[string]interface{}{
+					"type": "object",
+					"additionalProperties": map[string]interface{}{
+						"oneOf": []interface{}{
+							node,
+							map[string]interface{}{"type": "array", "items": node},
+						},
+					},
+				},
+			},
+		}
+	}
+	return schema
+}
+
+// 结构体字段的 schema,嵌入的结构体展开到当前层级
+func addFields(key string, t reflect.Type, properties map[string]interface{}, comments map[string]string, defaults map[string]*g.Var) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			addFields(key, field.Type, properties, comments, defaults)
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		path := name
+		if key != "" {
+			path = key + "." + name
+		}
+		var schema map[string]interface{}
+		switch field.Type.Kind() {
+		case reflect.Struct:
+			schema = structSchema(path, field.Type, comments, defaults)
+		case reflect.Bool:
+			schema = map[string]interface{}{"type": "boolean"}
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			schema = map[string]interface{}{"type": "integer"}
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			schema = map[string]interface{}{"type": "integer", "minimum": 0}
+		case reflect.Slice:
+			schema = map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}}
+		default:
+			schema = map[string]interface{}{"type": "string"}
+		}
+		if comment := comments[t.Name()+"."+field.Name]; comment != "" {
+			schema["description"] = comment
+		}
+		if def, ok := defaults[path]; ok && field.Type.Kind() != reflect.Struct {
+			schema["default"] = def.Val()
+		}
+		properties[name] = schema
+	}
+}
@@ -0,0 +1,33 @@
+package coreconfig_test
+
+import (
+	"testing"
+
+	"github.com/gogf/gf/v2/encoding/gjson"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gzdzh-cn/dzhcore/coreconfig"
+)
+
+func TestJsonSchema(t *testing.T) {
+	j := gjson.New(coreconfig.JsonSchema())
+	for path, want := range map[string]interface{}{
+		"$schema": coreconfig.SchemaDraft,
+		"properties.core.properties.file.properties.mode.type":                                         "string",
+		"properties.core.properties.file.properties.mode.default":                                      "local",
+		"properties.core.properties.eps.type":                                                          "boolean",
+		"properties.modules.properties.base.properties.jwt.properties.token.properties.expire.minimum": 0,
+		"properties.core.additionalProperties":                                                         false,
+		"properties.modules.additionalProperties":                                                      true,
+	} {
+		if got := j.Get(path); got.String() != g.NewVar(want).String() {
+			t.Errorf("%s = %v, 期望 %v", path, got, want)
+		}
+	}
+	if j.Get("properties.core.properties.file.properties.mode.description").String() == "" {
+		t.Error("core.file.mode 没有说明")
+	}
+	// database 可以是单个节点或分组
+	if len(j.Get("properties.database.anyOf").Array()) != 2 {
+		t.Errorf("database 的 schema %s", j.Get("properties.database").String())
+	}
+}
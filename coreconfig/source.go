@@ -0,0 +1,132 @@
+package coreconfig
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/genv"
+	"github.com/gzdzh-cn/dzhcore/utility/env"
+)
+
+// 配置的来源,与 env.GetCfgWithDefault 的读取顺序一致:
+// 配置文件中有值时使用配置文件,配置文件中没有该项时使用环境变量,都为空时使用默认值
+const (
+	SourceFile    = "file"    // 配置文件
+	SourceEnv     = "env"     // 环境变量
+	SourceDotEnv  = "dotenv"  // .env 文件
+	SourceDefault = "default" // newConfig 中的默认值
+)
+
+// 密钥类的配置项,显示时隐藏
+var secretKeyPattern = regexp.MustCompile(`(?i)(pass|password|secret|accesskeyid|secretaccesskey|link)$`)
+
+// 隐藏后的值
+const secretMask = "******"
+
+// ConfigItem 生效的配置项
+type ConfigItem struct {
+	Key    string      `json:"key"`    // 配置项,如 core.file.mode
+	Value  interface{} `json:"value"`  // 生效的值,密钥类的配置项隐藏
+	Source string      `json:"source"` // 来源 file、env、dotenv、default
+	Env    string      `json:"env"`    // 对应的环境变量名,如 CORE_FILE_MODE
+}
+
+// ConfigItems 列出 newConfig 读取的配置项的生效值和来源,密钥类的配置项隐藏
+// database 使用分组配置时按配置文件中的分组列出
+func ConfigItems(ctx context.Context) (items []*ConfigItem, err error) {
+	grouped, err := databaseGrouped(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for key, def := range env.Defaults() {
+		if grouped && strings.HasPrefix(key, "database.") {
+			continue
+		}
+		item := &ConfigItem{
+			Key:    key,
+			Source: SourceDefault,
+			Env:    envKey(key),
+			Value:  def.Val(),
+		}
+		value, err := g.Cfg().Get(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		if value != nil {
+			if !value.IsEmpty() {
+				item.Value, item.Source = value.Val(), SourceFile
+			}
+		} else if v := genv.Get(item.Env); v != nil && !v.IsEmpty() {
+			item.Value, item.Source = v.Val(), SourceEnv
+			if dotEnvKeys[item.Env] {
+				item.Source = SourceDotEnv
+			}
+		}
+		items = append(items, item)
+	}
+	if grouped {
+		dbItems, err := databaseItems(ctx)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, dbItems...)
+	}
+	for _, item := range items {
+		if isSecretKey(item.Key) && !g.NewVar(item.Value).IsEmpty() {
+			item.Value = secretMask
+		}
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Key < items[j].Key
+	})
+	return
+}
+
+// database 是否使用分组配置,如 database.default.type
+func databaseGrouped(ctx context.Context) (bool, error) {
+	value, err := g.Cfg().Get(ctx, "database")
+	if err != nil || value == nil {
+		return false, err
+	}
+	data := value.Map()
+	_, hasType := data["type"]
+	_, hasLink := data["link"]
+	return !hasType && !hasLink, nil
+}
+
+// 配置文件中 database 各分组的配置项
+func databaseItems(ctx context.Context) (items []*ConfigItem, err error) {
+	value, err := g.Cfg().Get(ctx, "database")
+	if err != nil {
+		return nil, err
+	}
+	var walk func(key string, value interface{})
+	walk = func(key string, value interface{}) {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			for k, item := range v {
+				walk(key+"."+k, item)
+			}
+		case []interface{}:
+			for i, item := range v {
+				walk(key+"."+g.NewVar(i).String(), item)
+			}
+		default:
+			items = append(items, &ConfigItem{Key: key, Value: v, Source: SourceFile})
+		}
+	}
+	walk("database", value.Map())
+	return
+}
+
+// 配置项对应的环境变量名,与 gcfg.GetWithEnv 一致
+func envKey(key string) string {
+	return strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+func isSecretKey(key string) bool {
+	return secretKeyPattern.MatchString(key)
+}
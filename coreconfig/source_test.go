@@ -0,0 +1,146 @@
+package coreconfig_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gogf/gf/v2/util/gconv"
+	"github.com/gzdzh-cn/dzhcore/coreconfig"
+)
+
+// 配置项 key:配置项
+func configItems(t *testing.T) map[string]*coreconfig.ConfigItem {
+	t.Helper()
+	list, err := coreconfig.ConfigItems(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	items := make(map[string]*coreconfig.ConfigItem, len(list))
+	for _, item := range list {
+		items[item.Key] = item
+	}
+	return items
+}
+
+// 来源与 newConfig 读取的值一致:配置文件有值时使用配置文件,没有该项时使用环境变量,否则使用默认值
+func TestConfigItemsSource(t *testing.T) {
+	setConfig(t, `
+core:
+  file:
+    mode: oss
+  appName: ""
+  gfLogger:
+    path: ./logs
+server:
+  address: ":9000"
+`)
+	t.Setenv("CORE_FILE_MODE", "cos")
+	t.Setenv("CORE_APPNAME", "fromEnv")
+	t.Setenv("SERVER_ADDRESS", "")
+	t.Setenv("CORE_EPS", "false")
+	coreconfig.Reload()
+	t.Cleanup(coreconfig.Reload)
+
+	items := configItems(t)
+	for _, tc := range []struct {
+		key    string
+		source string
+		value  string
+		actual string // newConfig 读取的值
+	}{
+		{"core.file.mode", coreconfig.SourceFile, "oss", coreconfig.Config.Core.File.Mode},
+		{"server.address", coreconfig.SourceFile, ":9000", coreconfig.Config.Server.Address},
+		// 配置文件中为空值时使用默认值,不读取环境变量
+		{"core.appName", coreconfig.SourceDefault, "dzhgo", coreconfig.Config.Core.AppName},
+		{"core.eps", coreconfig.SourceEnv, "false", boolText(coreconfig.Config.Core.Eps)},
+		{"core.autoMigrate", coreconfig.SourceDefault, "true", boolText(coreconfig.Config.Core.AutoMigrate)},
+	} {
+		item := items[tc.key]
+		if item == nil {
+			t.Errorf("没有配置项 %s", tc.key)
+			continue
+		}
+		if item.Source != tc.source || stringValue(item.Value) != tc.value {
+			t.Errorf("%s 来源 %s 值 %v, 期望 %s %s", tc.key, item.Source, item.Value, tc.source, tc.value)
+		}
+		if tc.actual != tc.value {
+			t.Errorf("%s 生效的值 %s, 期望 %s", tc.key, tc.actual, tc.value)
+		}
+	}
+	if env := items["core.eps"].Env; env != "CORE_EPS" {
+		t.Errorf("core.eps 的环境变量 %s", env)
+	}
+}
+
+// .env 中的配置项来源为 dotenv,已存在的环境变量不被覆盖
+func TestConfigItemsDotEnv(t *testing.T) {
+	setConfig(t, "core:\n  eps: true\n")
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, ".env"), []byte("CORE_APPNAME=fromDotEnv\nCORE_AUTOMIGRATE=false\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(dir)
+	t.Setenv("CORE_AUTOMIGRATE", "true")
+	t.Cleanup(func() {
+		os.Unsetenv("CORE_APPNAME")
+		coreconfig.Reload()
+	})
+	coreconfig.LoadEnv()
+
+	items := configItems(t)
+	if item := items["core.appName"]; item.Source != coreconfig.SourceDotEnv || stringValue(item.Value) != "fromDotEnv" {
+		t.Errorf("core.appName 来源 %s 值 %v", item.Source, item.Value)
+	}
+	if item := items["core.autoMigrate"]; item.Source != coreconfig.SourceEnv || stringValue(item.Value) != "true" {
+		t.Errorf("core.autoMigrate 来源 %s 值 %v", item.Source, item.Value)
+	}
+}
+
+// 密钥类的配置项有值时隐藏,分组的数据库配置按配置文件列出
+func TestConfigItemsSecret(t *testing.T) {
+	setConfig(t, `
+database:
+  default:
+    type: mysql
+    pass: "123456"
+  log:
+    - link: "mysql:root:123456@tcp(127.0.0.1:3306)/log"
+modules:
+  base:
+    jwt:
+      secret: abc
+`)
+	t.Setenv("CORE_FILE_OSS_SECRETACCESSKEY", "oss-secret")
+	items := configItems(t)
+	for key, want := range map[string]string{
+		"database.default.type":         "mysql",
+		"database.default.pass":         "******",
+		"database.log.0.link":           "******",
+		"modules.base.jwt.secret":       "******",
+		"redis.cfRedis.pass":            "",
+		"core.file.oss.secretAccessKey": "******",
+	} {
+		item := items[key]
+		if item == nil {
+			t.Errorf("没有配置项 %s", key)
+			continue
+		}
+		if got := stringValue(item.Value); got != want {
+			t.Errorf("%s 值 %s, 期望 %s", key, got, want)
+		}
+	}
+	// 分组配置时不列出未分组的默认值
+	if _, ok := items["database.type"]; ok {
+		t.Error("分组配置时列出了 database.type")
+	}
+}
+
+func boolText(b bool) string {
+	return gconv.String(b)
+}
+
+func stringValue(v interface{}) string {
+	return gconv.String(v)
+}
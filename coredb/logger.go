@@ -0,0 +1,80 @@
+package coredb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gogf/gf/v2/os/glog"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// GormLogger 把 gorm 的日志写入 glog,与 g.DB() 共用 sql 日志配置
+// sql 以 debug 级别写入,慢查询以 warning 级别写入,错误以 error 级别写入,是否输出由 glog 的级别决定
+// ctx 中的链路 id 由 glog 输出
+type GormLogger struct {
+	Logger                    *glog.Logger    // 日志对象
+	LogLevel                  logger.LogLevel // gorm 日志级别
+	SlowThreshold             time.Duration   // 慢查询阈值,0 为不记录慢查询,默认使用 core.sqlLogger.slowThreshold
+	IgnoreRecordNotFoundError bool            // 是否忽略记录不存在的错误
+}
+
+// NewGormLogger 创建 gorm 日志
+func NewGormLogger(l *glog.Logger, slowThreshold time.Duration) *GormLogger {
+	return &GormLogger{
+		Logger:                    l,
+		LogLevel:                  logger.Info,
+		SlowThreshold:             slowThreshold,
+		IgnoreRecordNotFoundError: true,
+	}
+}
+
+// LogMode 设置日志级别
+func (l *GormLogger) LogMode(level logger.LogLevel) logger.Interface {
+	newLogger := *l
+	newLogger.LogLevel = level
+	return &newLogger
+}
+
+// Info 信息日志
+func (l *GormLogger) Info(ctx context.Context, msg string, data ...interface{}) {
+	if l.LogLevel >= logger.Info {
+		l.Logger.Infof(ctx, "[gorm] "+msg, data...)
+	}
+}
+
+// Warn 警告日志
+func (l *GormLogger) Warn(ctx context.Context, msg string, data ...interface{}) {
+	if l.LogLevel >= logger.Warn {
+		l.Logger.Warningf(ctx, "[gorm] "+msg, data...)
+	}
+}
+
+// Error 错误日志
+func (l *GormLogger) Error(ctx context.Context, msg string, data ...interface{}) {
+	if l.LogLevel >= logger.Error {
+		l.Logger.Errorf(ctx, "[gorm] "+msg, data...)
+	}
+}
+
+// Trace sql日志
+func (l *GormLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	if l.LogLevel <= logger.Silent {
+		return
+	}
+	var (
+		elapsed   = time.Since(begin)
+		sql, rows = fc()
+		content   = fmt.Sprintf("[%3d ms] [gorm] [rows:%-3d] %s", elapsed.Milliseconds(), rows, sql)
+	)
+	switch {
+	case err != nil && l.LogLevel >= logger.Error && !(l.IgnoreRecordNotFoundError && errors.Is(err, gorm.ErrRecordNotFound)):
+		l.Logger.Errorf(ctx, "%s, %v", content, err)
+	case l.SlowThreshold > 0 && elapsed > l.SlowThreshold && l.LogLevel >= logger.Warn:
+		l.Logger.Warningf(ctx, "慢查询 >= %v %s", l.SlowThreshold, content)
+	case l.LogLevel >= logger.Info:
+		l.Logger.Debug(ctx, content)
+	}
+}
@@ -0,0 +1,29 @@
+package coredb
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"gorm.io/gorm/schema"
+)
+
+// Options 各驱动创建 gorm 连接时共用的配置,需在创建连接前设置
+//
+//	coredb.Options.PrepareStmt = true
+//	coredb.Options.NamingStrategy = schema.NamingStrategy{SingularTable: true}
+var Options = &GormOptions{}
+
+// GormOptions gorm 连接配置
+type GormOptions struct {
+	NamingStrategy schema.Namer     // 命名策略,为空时使用 gorm 默认
+	Logger         logger.Interface // 日志,为空时使用 core.sqlLogger 配置的日志
+	PrepareStmt    bool             // 是否缓存预编译语句
+}
+
+// GormConfig 根据 Options 生成 gorm 配置
+func GormConfig() *gorm.Config {
+	return &gorm.Config{
+		NamingStrategy: Options.NamingStrategy,
+		Logger:         Options.Logger,
+		PrepareStmt:    Options.PrepareStmt,
+	}
+}
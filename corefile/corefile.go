@@ -15,8 +15,13 @@ type Driver interface {
 var (
 	// FileMap is the map for registered file drivers.
 	FileMap = map[string]Driver{}
+	// Checkers 驱动的配置检查,供 doctor 使用 key:上传类型
+	Checkers = map[string]Checker{}
 )
 
+// Checker 检查驱动的配置、连接和目录,返回检查结果的说明
+type Checker func(ctx g.Ctx) (message string, err error)
+
 func NewFile() (d Driver) {
 	if driver, ok := FileMap[coreconfig.Config.Core.File.Mode]; ok {
 		return driver.New()
@@ -36,3 +41,8 @@ func Register(name string, driver Driver) error {
 	FileMap[name] = driver
 	return nil
 }
+
+// RegisterChecker 注册驱动的配置检查
+func RegisterChecker(name string, checker Checker) {
+	Checkers[name] = checker
+}
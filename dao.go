@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/gogf/gf/v2/database/gdb"
+	"github.com/gogf/gf/v2/frame/g"
 )
 
 // IDao is the interface for DAO objects
@@ -52,3 +53,32 @@ func (w *DaoWrapper) Transaction(ctx context.Context, f func(ctx context.Context
 func NewDaoWrapper(dao interface{}) *DaoWrapper {
 	return &DaoWrapper{dao: dao}
 }
+
+// ModelDao 根据 IModel 实现的 IDao,供没有生成 dao 的模型使用
+type ModelDao struct {
+	model IModel
+}
+
+func (d *ModelDao) DB() gdb.DB {
+	return g.DB(d.model.GroupName())
+}
+
+func (d *ModelDao) Table() string {
+	return d.model.TableName()
+}
+
+func (d *ModelDao) Group() string {
+	return d.model.GroupName()
+}
+
+func (d *ModelDao) Ctx(ctx context.Context) *gdb.Model {
+	return d.DB().Model(d.model.TableName()).Safe().Ctx(ctx)
+}
+
+func (d *ModelDao) Transaction(ctx context.Context, f func(ctx context.Context, tx gdb.TX) error) (err error) {
+	return d.DB().Transaction(ctx, f)
+}
+
+func NewModelDao(model IModel) *ModelDao {
+	return &ModelDao{model: model}
+}
@@ -0,0 +1,289 @@
+package dzhcore
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+
+	"github.com/gogf/gf/v2/container/garray"
+	"github.com/gogf/gf/v2/database/gdb"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gtime"
+	"github.com/gogf/gf/v2/os/gtimer"
+	"github.com/gzdzh-cn/dzhcore/coreconfig"
+	"gorm.io/gorm"
+)
+
+// 数据库连接池与健康检查
+// gorm 连接使用与 g.DB() 相同的连接池配置 maxIdle、maxOpen、maxLifeTime
+// 按 core.dbHealth.interval 定时 ping 各分组,连续失败 core.dbHealth.reconnectAfter 次后关闭连接,下次使用时重新连接
+// 健康检查接口和 doctor 只报告状态,不关闭连接
+
+const (
+	DbStatusUp   = "up"   // 正常
+	DbStatusDown = "down" // 异常
+)
+
+// 与 gf 的连接池默认值保持一致
+const (
+	defaultPoolMaxIdle     = 10
+	defaultPoolMaxLifeTime = 30 * time.Second
+)
+
+var (
+	dbHealthMu  sync.Mutex
+	dbHealthMap = make(map[string]*DbHealth)
+)
+
+// DbPoolStats 连接池状态
+type DbPoolStats struct {
+	MaxOpen           int   `json:"maxOpen"`           // 最大连接数,0 为不限制
+	Open              int   `json:"open"`              // 当前连接数
+	InUse             int   `json:"inUse"`             // 使用中的连接数
+	Idle              int   `json:"idle"`              // 空闲连接数
+	WaitCount         int64 `json:"waitCount"`         // 等待连接的总次数
+	WaitDuration      int64 `json:"waitDuration"`      // 等待连接的总时长（毫秒）
+	MaxIdleClosed     int64 `json:"maxIdleClosed"`     // 因超过最大空闲数关闭的连接数
+	MaxLifetimeClosed int64 `json:"maxLifetimeClosed"` // 因超过最大存活时间关闭的连接数
+}
+
+// DbHealth 数据库分组健康状态
+type DbHealth struct {
+	Group     string       `json:"group"`     // 分组
+	Type      string       `json:"type"`      // 数据库类型
+	Status    string       `json:"status"`    // 状态 up、down
+	Latency   int64        `json:"latency"`   // ping 耗时（毫秒）
+	Error     string       `json:"error"`     // 错误信息
+	Failures  int          `json:"failures"`  // 连续失败次数
+	CheckTime *gtime.Time  `json:"checkTime"` // 检查时间
+	Pool      *DbPoolStats `json:"pool"`      // g.DB() 连接池
+	GormPool  *DbPoolStats `json:"gormPool"`  // gorm 连接池,未使用时为空
+}
+
+// 按数据库节点配置设置 gorm 连接池
+func setGormPool(db *gorm.DB, node *gdb.ConfigNode) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	if node.MaxIdleConnCount > 0 {
+		sqlDB.SetMaxIdleConns(node.MaxIdleConnCount)
+	} else {
+		sqlDB.SetMaxIdleConns(defaultPoolMaxIdle)
+	}
+	if node.MaxOpenConnCount > 0 {
+		sqlDB.SetMaxOpenConns(node.MaxOpenConnCount)
+	}
+	if node.MaxConnLifeTime > 0 {
+		sqlDB.SetConnMaxLifetime(node.MaxConnLifeTime)
+	} else {
+		sqlDB.SetConnMaxLifetime(defaultPoolMaxLifeTime)
+	}
+	return nil
+}
+
+func newDbPoolStats(stats sql.DBStats) *DbPoolStats {
+	return &DbPoolStats{
+		MaxOpen:           stats.MaxOpenConnections,
+		Open:              stats.OpenConnections,
+		InUse:             stats.InUse,
+		Idle:              stats.Idle,
+		WaitCount:         stats.WaitCount,
+		WaitDuration:      stats.WaitDuration.Milliseconds(),
+		MaxIdleClosed:     stats.MaxIdleClosed,
+		MaxLifetimeClosed: stats.MaxLifetimeClosed,
+	}
+}
+
+// 分组的 g.DB() 和 gorm 连接池状态
+func dbPoolStats(group string) (pool, gormPool *DbPoolStats) {
+	if master, err := g.DB(group).Master(); err == nil {
+		pool = newDbPoolStats(master.Stats())
+	}
+	gormMu.Lock()
+	db, ok := GormDBS[group]
+	gormMu.Unlock()
+	if ok {
+		if sqlDB, err := db.DB(); err == nil {
+			gormPool = newDbPoolStats(sqlDB.Stats())
+		}
+	}
+	return
+}
+
+// 关闭分组的 g.DB() 和 gorm 连接,下次使用时重新连接
+func closeConnections(ctx context.Context, group string) error {
+	gormMu.Lock()
+	defer gormMu.Unlock()
+	if db, ok := GormDBS[group]; ok {
+		if sqlDB, err := db.DB(); err == nil {
+			if err = sqlDB.Close(); err != nil {
+				return err
+			}
+		}
+		delete(GormDBS, group)
+	}
+	return g.DB(group).Close(ctx)
+}
+
+// PingDb 检查数据库分组连接并记录结果,不关闭连接
+func PingDb(ctx context.Context, group string) *DbHealth {
+	// 恢复数据库期间不 ping,避免重新连接到替换前的文件
+	defer readDbGate(ctx)()
+	var (
+		start   = time.Now()
+		timeout = time.Duration(coreconfig.Config.Core.DbHealth.Timeout) * time.Second
+		health  = &DbHealth{Group: group, Type: g.DB(group).GetConfig().Type, Status: DbStatusUp}
+	)
+	if timeout <= 0 {
+		timeout = 3 * time.Second
+	}
+	pingCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	master, err := g.DB(group).Master()
+	if err == nil {
+		err = master.PingContext(pingCtx)
+	}
+	health.Latency = time.Since(start).Milliseconds()
+	health.CheckTime = gtime.Now()
+
+	dbHealthMu.Lock()
+	last := dbHealthMap[group]
+	if err != nil {
+		health.Status = DbStatusDown
+		health.Error = err.Error()
+		health.Failures = 1
+		if last != nil {
+			health.Failures = last.Failures + 1
+		}
+	}
+	dbHealthMap[group] = health
+	dbHealthMu.Unlock()
+
+	if err != nil {
+		g.Log().Errorf(ctx, "数据库分组 %s 连接异常,第 %d 次: %v", group, health.Failures, err)
+	} else if last != nil && last.Status == DbStatusDown {
+		g.Log().Infof(ctx, "数据库分组 %s 连接已恢复", group)
+	}
+	health.Pool, health.GormPool = dbPoolStats(group)
+	return health
+}
+
+// GetDbHealth 检查数据库分组连接,groups 为空时检查全部分组
+func GetDbHealth(ctx context.Context, groups ...string) (list []*DbHealth, err error) {
+	if len(groups) == 0 {
+		groups = DbGroups
+	}
+	all := garray.NewStrArrayFrom(DbGroups)
+	for _, group := range groups {
+		if !all.Contains(group) {
+			return nil, gerror.Newf("数据库分组 %s 不存在", group)
+		}
+		list = append(list, PingDb(ctx, group))
+	}
+	return
+}
+
+// GetDbStats 获取数据库分组的连接池状态和最近一次检查结果,groups 为空时获取全部分组
+func GetDbStats(groups ...string) (list []*DbHealth, err error) {
+	if len(groups) == 0 {
+		groups = DbGroups
+	}
+	all := garray.NewStrArrayFrom(DbGroups)
+	for _, group := range groups {
+		if !all.Contains(group) {
+			return nil, gerror.Newf("数据库分组 %s 不存在", group)
+		}
+		health := &DbHealth{Group: group, Type: g.DB(group).GetConfig().Type}
+		dbHealthMu.Lock()
+		if last, ok := dbHealthMap[group]; ok {
+			*health = *last
+		}
+		dbHealthMu.Unlock()
+		health.Pool, health.GormPool = dbPoolStats(group)
+		list = append(list, health)
+	}
+	return
+}
+
+// 启动定时检查数据库连接
+func startDbHealth() {
+	cfg := coreconfig.Config.Core.DbHealth
+	if !cfg.Enable || cfg.Interval <= 0 {
+		return
+	}
+	// 定时检查的连续失败次数,不计入接口触发的检查
+	failures := make(map[string]int)
+	gtimer.AddSingleton(ctx, time.Duration(cfg.Interval)*time.Second, func(ctx context.Context) {
+		for _, group := range DbGroups {
+			if PingDb(ctx, group).Status == DbStatusUp {
+				failures[group] = 0
+				continue
+			}
+			failures[group]++
+			if cfg.ReconnectAfter > 0 && failures[group]%cfg.ReconnectAfter == 0 {
+				reconnectDb(ctx, group, failures[group])
+			}
+		}
+	})
+}
+
+// 关闭分组的连接,下次使用时重新连接
+func reconnectDb(ctx context.Context, group string, failures int) {
+	defer readDbGate(ctx)()
+	g.Log().Warningf(ctx, "数据库分组 %s 连续 %d 次检查失败,关闭连接以便重新连接", group, failures)
+	if err := closeConnections(ctx, group); err != nil {
+		g.Log().Errorf(ctx, "关闭数据库分组 %s 连接失败: %v", group, err)
+	}
+}
+
+// DbController 数据库状态接口
+type DbController struct {
+	*ControllerSimple
+}
+
+type DbHealthReq struct {
+	g.Meta `path:"/health" method:"GET"`
+	Group  string `json:"group"` // 数据库分组,为空时为全部分组
+}
+
+type DbStatsReq struct {
+	g.Meta `path:"/stats" method:"GET"`
+	Group  string `json:"group"` // 数据库分组,为空时为全部分组
+}
+
+// Health 检查数据库连接
+func (c *DbController) Health(ctx context.Context, req *DbHealthReq) (res *BaseRes, err error) {
+	var groups []string
+	if req.Group != "" {
+		groups = append(groups, req.Group)
+	}
+	list, err := GetDbHealth(ctx, groups...)
+	if err != nil {
+		return Fail(err.Error()), err
+	}
+	return Ok(list), nil
+}
+
+// Stats 连接池状态
+func (c *DbController) Stats(ctx context.Context, req *DbStatsReq) (res *BaseRes, err error) {
+	var groups []string
+	if req.Group != "" {
+		groups = append(groups, req.Group)
+	}
+	list, err := GetDbStats(groups...)
+	if err != nil {
+		return Fail(err.Error()), err
+	}
+	return Ok(list), nil
+}
+
+func init() {
+	if coreconfig.Config.Core.DbHealth.Enable {
+		AddControllerSimple(&DbController{
+			&ControllerSimple{Prefix: "/admin/core/db"},
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package defineStruct
+
+import (
+	_ "embed"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strings"
+	"sync"
+)
+
+// 配置结构体的源码,用于读取字段注释
+//
+//go:embed config.go
+var configSource string
+
+var (
+	commentsOnce sync.Once
+	comments     map[string]string
+)
+
+// Comments 配置结构体字段的注释,key 为 结构体名.字段名,如 CoreConfig.Eps
+func Comments() map[string]string {
+	commentsOnce.Do(func() {
+		comments = make(map[string]string)
+		file, err := parser.ParseFile(token.NewFileSet(), "config.go", configSource, parser.ParseComments)
+		if err != nil {
+			return
+		}
+		ast.Inspect(file, func(node ast.Node) bool {
+			spec, ok := node.(*ast.TypeSpec)
+			if !ok {
+				return true
+			}
+			st, ok := spec.Type.(*ast.StructType)
+			if !ok {
+				return false
+			}
+			for _, field := range st.Fields.List {
+				if field.Comment == nil {
+					continue
+				}
+				text := strings.TrimSpace(field.Comment.Text())
+				for _, name := range field.Names {
+					comments[spec.Name.Name+"."+name.Name] = text
+				}
+			}
+			return false
+		})
+	})
+	return comments
+}
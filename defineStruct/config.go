@@ -71,16 +71,63 @@ type RedisCore struct {
 
 // 核心配置
 type CoreConfig struct {
-	AppName     string       `yaml:"appName"`     // 应用名称
-	IsDesktop   bool         `yaml:"isDesktop"`   // 是否桌面端
-	IsProd      bool         `yaml:"isProd"`      // 是否生产模式
-	AutoMigrate bool         `yaml:"autoMigrate"` // 是否自动建表
-	Eps         bool         `yaml:"eps"`         // 是否生成前端路由
-	Notice      NoticeConfig `yaml:"notice"`      // 通知队列配置
-	SQLLogger   LoggerConfig `yaml:"sqlLogger"`   // SQL日志配置
-	GFLogger    LoggerConfig `yaml:"gfLogger"`    // GF日志配置
-	RunLogger   RunLogger    `yaml:"runLogger"`   // 运行日志配置
-	File        FileConfig   `yaml:"file"`        // 文件上传配置
+	AppName     string            `yaml:"appName"`     // 应用名称
+	IsDesktop   bool              `yaml:"isDesktop"`   // 是否桌面端
+	IsProd      bool              `yaml:"isProd"`      // 是否生产模式
+	AutoMigrate bool              `yaml:"autoMigrate"` // 是否自动建表
+	Eps         bool              `yaml:"eps"`         // 是否生成前端路由
+	Notice      NoticeConfig      `yaml:"notice"`      // 通知队列配置
+	SQLLogger   SQLLogger         `yaml:"sqlLogger"`   // SQL日志配置
+	SQLObserver SQLObserver       `yaml:"sqlObserver"` // SQL观测配置
+	GFLogger    LoggerConfig      `yaml:"gfLogger"`    // GF日志配置
+	RunLogger   RunLogger         `yaml:"runLogger"`   // 运行日志配置
+	File        FileConfig        `yaml:"file"`        // 文件上传配置
+	Dict        DictConfig        `yaml:"dict"`        // 数据字典配置
+	Migration   MigrationConfig   `yaml:"migration"`   // 数据库迁移配置
+	SchemaCheck SchemaCheckConfig `yaml:"schemaCheck"` // 启动时表结构检查配置
+	Seed        SeedConfig        `yaml:"seed"`        // 种子数据配置
+	Backup      BackupConfig      `yaml:"backup"`      // sqlite 备份配置
+	DbHealth    DbHealthConfig    `yaml:"dbHealth"`    // 数据库健康检查配置
+}
+
+// 数据库健康检查配置
+type DbHealthConfig struct {
+	Enable         bool `yaml:"enable"`         // 是否启用健康检查接口和定时检查
+	Interval       int  `yaml:"interval"`       // 定时检查间隔（秒）,0 为不定时检查
+	Timeout        int  `yaml:"timeout"`        // ping 超时时间（秒）
+	ReconnectAfter int  `yaml:"reconnectAfter"` // 定时检查连续失败多少次后关闭连接以便重新连接,0 为不重新连接
+}
+
+// sqlite 备份配置
+type BackupConfig struct {
+	Enable       bool   `yaml:"enable"`       // 是否启用定时备份和备份接口
+	Cron         string `yaml:"cron"`         // 定时备份表达式,为空时不定时备份
+	Keep         int    `yaml:"keep"`         // 保留的备份个数,0 为不清理
+	Path         string `yaml:"path"`         // 备份目录,桌面端生产环境为数据目录下的 data/backup
+	CheckOnStart bool   `yaml:"checkOnStart"` // 启动时是否检查数据库完整性
+	AutoRestore  bool   `yaml:"autoRestore"`  // 数据库损坏时是否自动从最近一个完好的备份恢复
+}
+
+// 种子数据配置
+type SeedConfig struct {
+	AutoRun bool `yaml:"autoRun"` // 启动时是否自动执行版本变化的种子数据
+}
+
+// 启动时表结构检查配置
+type SchemaCheckConfig struct {
+	Enable bool `yaml:"enable"` // 是否在启动时对比模型与表结构,生产环境存在破坏性差异时拒绝启动
+}
+
+// 数据库迁移配置
+type MigrationConfig struct {
+	AutoRun     bool `yaml:"autoRun"`     // 启动时是否自动执行未执行的迁移
+	LockTimeout int  `yaml:"lockTimeout"` // 迁移锁超时时间（秒）,节点异常退出后超时自动释放
+}
+
+// 数据字典配置
+type DictConfig struct {
+	Enable bool `yaml:"enable"` // 是否启用数据字典模块
+	Expire int  `yaml:"expire"` // 字典缓存时间（秒）,0 为不过期
 }
 
 // 通知队列配置
@@ -112,13 +159,23 @@ type LoggerConfig struct {
 
 // SQL日志配置
 type SQLLogger struct {
-	Path     string `yaml:"path"`     // 日志路径
-	File     string `yaml:"file"`     // 日志文件名
-	Level    string `yaml:"level"`    // 日志级别
-	Stdout   bool   `yaml:"stdout"`   // 是否输出到控制台
-	Flags    int    `yaml:"flags"`    // 日志标志位
-	StStatus int    `yaml:"stStatus"` // 日志状态
-	StSkip   int    `yaml:"stSkip"`   // 日志跳过
+	Path          string `yaml:"path"`          // 日志路径
+	File          string `yaml:"file"`          // 日志文件名
+	Level         string `yaml:"level"`         // 日志级别
+	Stdout        bool   `yaml:"stdout"`        // 是否输出到控制台
+	Flags         int    `yaml:"flags"`         // 日志标志位
+	StStatus      int    `yaml:"stStatus"`      // 日志状态
+	StSkip        int    `yaml:"stSkip"`        // 日志跳过
+	SlowThreshold int    `yaml:"slowThreshold"` // gorm 慢查询阈值（毫秒）,0 为不记录慢查询
+}
+
+// SQL观测配置
+type SQLObserver struct {
+	Enable        bool `yaml:"enable"`        // 是否启用慢查询和接口SQL统计
+	SlowThreshold int  `yaml:"slowThreshold"` // 慢查询阈值（毫秒）
+	NPlusOne      int  `yaml:"nPlusOne"`      // 同一请求中相同语句重复次数达到该值时告警
+	TopSize       int  `yaml:"topSize"`       // 统计排行返回条数
+	MaxStats      int  `yaml:"maxStats"`      // 语句统计、接口统计最多保留的条数,0 为不限制
 }
 
 // GF日志配置
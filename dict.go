@@ -0,0 +1,330 @@
+package dzhcore
+
+import (
+	"context"
+	"time"
+
+	"github.com/gogf/gf/v2/container/garray"
+	"github.com/gogf/gf/v2/database/gdb"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/text/gstr"
+	"github.com/gogf/gf/v2/util/gconv"
+	"github.com/gzdzh-cn/dzhcore/coreconfig"
+)
+
+// 数据字典
+// 开启 core.dict.enable 后注册字典类型表、字典项表及其 CRUD 路由
+// 初始数据可放在 internal/resource/initjson/core_dict_type.json、core_dict_item.json 中,
+// 通过 FillInitData(ctx, "base", &DictType{}) 写入,字典项通过 typeKey 关联字典类型
+
+const (
+	TableNameDictType = "core_dict_type"
+	TableNameDictItem = "core_dict_item"
+	dictCachePrefix   = "core:dict:"
+)
+
+// DictType 字典类型,映射表 <core_dict_type>
+type DictType struct {
+	*Model
+	Name     string  `gorm:"column:name;comment:名称;type:varchar(255);not null" json:"name"`           // 名称
+	Key      string  `gorm:"column:key;comment:标识;type:varchar(255);not null;uniqueIndex" json:"key"` // 标识
+	OrderNum int32   `gorm:"column:orderNum;comment:排序;type:int;not null;default:99" json:"orderNum"` // 排序
+	Remark   *string `gorm:"column:remark;comment:备注;type:varchar(255)" json:"remark"`                // 备注
+}
+
+// TableName DictType 的表名
+func (*DictType) TableName() string {
+	return TableNameDictType
+}
+
+// GroupName DictType 的表分组
+func (*DictType) GroupName() string {
+	return "default"
+}
+
+// NewDictType 创建一个新的 DictType 实例
+func NewDictType() *DictType {
+	return &DictType{
+		Model: NewModel(),
+	}
+}
+
+// DictItem 字典项,映射表 <core_dict_item>
+type DictItem struct {
+	*Model
+	TypeKey  string  `gorm:"column:typeKey;comment:字典类型标识;type:varchar(255);not null;index" json:"typeKey"` // 字典类型标识
+	Label    string  `gorm:"column:label;comment:名称;type:varchar(255);not null" json:"label"`               // 名称
+	Value    string  `gorm:"column:value;comment:值;type:varchar(255);not null" json:"value"`                // 值
+	OrderNum int32   `gorm:"column:orderNum;comment:排序;type:int;not null;default:99" json:"orderNum"`       // 排序
+	Status   int     `gorm:"column:status;comment:状态;type:int;default:1" json:"status"`                     // 状态 0:禁用 1:启用
+	Remark   *string `gorm:"column:remark;comment:备注;type:varchar(255)" json:"remark"`                      // 备注
+}
+
+// TableName DictItem 的表名
+func (*DictItem) TableName() string {
+	return TableNameDictItem
+}
+
+// GroupName DictItem 的表分组
+func (*DictItem) GroupName() string {
+	return "default"
+}
+
+// NewDictItem 创建一个新的 DictItem 实例
+func NewDictItem() *DictItem {
+	return &DictItem{
+		Model: NewModel(),
+	}
+}
+
+// DictOption 字典选项
+type DictOption struct {
+	Label string `json:"label"`
+	Value string `json:"value"`
+}
+
+// GetDictItems 获取字典类型下启用的字典项,结果缓存,修改字典后自动清除
+func GetDictItems(ctx context.Context, typeKey string) (items []*DictOption, err error) {
+	value, err := CacheManager.GetOrSetFunc(ctx, dictCachePrefix+typeKey, func(ctx context.Context) (interface{}, error) {
+		result, err := DBM(&DictItem{}).Ctx(ctx).Fields("label", "value").
+			Where("typeKey", typeKey).Where("status", 1).
+			Order("orderNum ASC").Order("createTime ASC").All()
+		if err != nil {
+			return nil, err
+		}
+		return result.List(), nil
+	}, time.Duration(coreconfig.Config.Core.Dict.Expire)*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	err = value.Scan(&items)
+	return
+}
+
+// GetDictMap 获取字典值和名称的映射 key:值 value:名称
+func GetDictMap(ctx context.Context, typeKey string) (data g.MapStrStr, err error) {
+	items, err := GetDictItems(ctx, typeKey)
+	if err != nil {
+		return nil, err
+	}
+	data = make(g.MapStrStr, len(items))
+	for _, item := range items {
+		data[item.Value] = item.Label
+	}
+	return
+}
+
+// GetDictLabel 获取字典值对应的名称,找不到时返回空字符串
+func GetDictLabel(ctx context.Context, typeKey string, value interface{}) string {
+	data, err := GetDictMap(ctx, typeKey)
+	if err != nil {
+		g.Log().Error(ctx, err.Error())
+		return ""
+	}
+	return data[gconv.String(value)]
+}
+
+// ClearDictCache 清除字典缓存,typeKeys 为空时清除全部字典类型的缓存
+func ClearDictCache(ctx context.Context, typeKeys ...string) (err error) {
+	if len(typeKeys) == 0 {
+		types, err := DBM(&DictType{}).Ctx(ctx).Unscoped().Array("key")
+		if err != nil {
+			return err
+		}
+		items, err := DBM(&DictItem{}).Ctx(ctx).Unscoped().Distinct().Array("typeKey")
+		if err != nil {
+			return err
+		}
+		typeKeys = append(gconv.Strings(types), gconv.Strings(items)...)
+	}
+	removes := garray.NewStrArray()
+	for _, typeKey := range typeKeys {
+		if typeKey != "" && !removes.Contains(dictCachePrefix+typeKey) {
+			removes.Append(dictCachePrefix + typeKey)
+		}
+	}
+	if removes.Len() == 0 {
+		return
+	}
+	return CacheManager.Removes(ctx, gconv.Interfaces(removes.Slice()))
+}
+
+// 按 DictFields 追加字典名称字段
+func (s *Service) applyDictFields(ctx context.Context, result gdb.Result) gdb.Result {
+	for field, typeKey := range s.DictFields {
+		data, err := GetDictMap(ctx, typeKey)
+		if err != nil {
+			g.Log().Errorf(ctx, "读取字典 %v 失败: %v", typeKey, err)
+			continue
+		}
+		for _, record := range result {
+			if value, ok := record[field]; ok {
+				record[field+"Label"] = g.NewVar(data[value.String()])
+			}
+		}
+	}
+	return result
+}
+
+// 字典服务,修改后清除字典缓存
+type sDictService struct {
+	*Service
+}
+
+const dictChangeCtxKey = "dzhcore.dictChange"
+
+// 一次请求中修改的字典,ModifyBefore 中记录修改前的类型标识,ModifyAfter 中同步字典项并清除缓存
+type dictChange struct {
+	keys    []string          // 需要清除缓存的字典类型标识
+	renames map[string]string // 修改的字典类型标识 key:原标识 value:新标识
+}
+
+// 当前请求的字典修改记录
+func getDictChange(ctx context.Context) *dictChange {
+	r := g.RequestFromCtx(ctx)
+	if r == nil {
+		return &dictChange{renames: make(map[string]string)}
+	}
+	if change, ok := r.GetCtxVar(dictChangeCtxKey).Val().(*dictChange); ok {
+		return change
+	}
+	change := &dictChange{renames: make(map[string]string)}
+	r.SetCtxVar(dictChangeCtxKey, change)
+	return change
+}
+
+func (s *sDictService) isType() bool {
+	return s.Model.TableName() == TableNameDictType
+}
+
+// 字典类型的标识字段
+func (s *sDictService) keyField() string {
+	if s.isType() {
+		return "key"
+	}
+	return "typeKey"
+}
+
+// ModifyBefore 记录修改前的字典类型标识,删除字典类型时同时删除字典项
+func (s *sDictService) ModifyBefore(ctx context.Context, method string, param g.MapStrAny) (err error) {
+	var ids []interface{}
+	switch method {
+	case "Delete":
+		ids = gconv.SliceAny(param["ids"])
+	case "Update":
+		ids = []interface{}{param["id"]}
+	default:
+		return
+	}
+	keys, err := DDAO(s.Dao, ctx).WhereIn("id", ids).Array(s.keyField())
+	if err != nil || len(keys) == 0 {
+		return
+	}
+	change := getDictChange(ctx)
+	change.keys = append(change.keys, gconv.Strings(keys)...)
+	if !s.isType() {
+		return
+	}
+	switch method {
+	case "Delete":
+		_, err = DBM(&DictItem{}).Ctx(ctx).WhereIn("typeKey", gconv.Strings(keys)).Delete()
+	case "Update":
+		// 修改标识后字典项跟随修改,否则字典项无法再通过类型找到
+		if key := gconv.String(param["key"]); param["key"] != nil && key != keys[0].String() {
+			change.renames[keys[0].String()] = key
+		}
+	}
+	return
+}
+
+// ModifyAfter 修改字典类型标识时同步修改字典项,清除修改前后字典类型的缓存
+func (s *sDictService) ModifyAfter(ctx context.Context, method string, param g.MapStrAny) (err error) {
+	change := getDictChange(ctx)
+	if key := gconv.String(param[s.keyField()]); key != "" {
+		change.keys = append(change.keys, key)
+	}
+	for oldKey, newKey := range change.renames {
+		if _, err = DBM(&DictItem{}).Ctx(ctx).Where("typeKey", oldKey).Data(g.Map{"typeKey": newKey}).Update(); err != nil {
+			return err
+		}
+		change.keys = append(change.keys, newKey)
+		delete(change.renames, oldKey)
+	}
+	keys := change.keys
+	change.keys = nil
+	if len(keys) == 0 {
+		return
+	}
+	return ClearDictCache(ctx, keys...)
+}
+
+// DictItemController 字典项路由,额外提供按类型批量获取字典数据的接口
+type DictItemController struct {
+	*Controller
+}
+
+type DictDataReq struct {
+	g.Meta `path:"/data" method:"GET"`
+	Types  string `json:"types" v:"required#请填写字典类型标识"` // 字典类型标识,多个用逗号隔开
+}
+
+// Data 按类型批量获取字典数据
+func (c *DictItemController) Data(ctx context.Context, req *DictDataReq) (res *BaseRes, err error) {
+	data := g.Map{}
+	for _, typeKey := range gstr.SplitAndTrim(req.Types, ",") {
+		items, err := GetDictItems(ctx, typeKey)
+		if err != nil {
+			return Fail(err.Error()), err
+		}
+		data[typeKey] = items
+	}
+	return Ok(data), nil
+}
+
+func newDictTypeService() *sDictService {
+	model := NewDictType()
+	s := NewModelService(model)
+	s.Dao = NewModelDao(model)
+	s.UniqueKey = g.MapStrStr{"key": "字典类型标识已存在"}
+	s.NotNullKey = g.MapStrStr{"name": "名称不能为空", "key": "标识不能为空"}
+	s.PageQueryOp = &QueryOp{
+		KeyWordField: []string{"name", "key"},
+		AddOrderby:   g.MapStrStr{"orderNum": "ASC"},
+	}
+	s.ListQueryOp = s.PageQueryOp
+	return &sDictService{s}
+}
+
+func newDictItemService() *sDictService {
+	model := NewDictItem()
+	s := NewModelService(model)
+	s.Dao = NewModelDao(model)
+	s.NotNullKey = g.MapStrStr{"typeKey": "字典类型标识不能为空", "label": "名称不能为空", "value": "值不能为空"}
+	s.PageQueryOp = &QueryOp{
+		FieldEQ:      []string{"typeKey", "status"},
+		KeyWordField: []string{"label", "value"},
+		AddOrderby:   g.MapStrStr{"orderNum": "ASC"},
+	}
+	s.ListQueryOp = s.PageQueryOp
+	return &sDictService{s}
+}
+
+func init() {
+	if !coreconfig.Config.Core.Dict.Enable {
+		return
+	}
+	AddModel(&DictType{})
+	AddModel(&DictItem{})
+	AddController(&Controller{
+		Prefix:  "/admin/dict/type",
+		Api:     []string{"Add", "Delete", "Update", "Info", "List", "Page"},
+		Service: newDictTypeService(),
+	})
+	AddController(&DictItemController{
+		&Controller{
+			Prefix:  "/admin/dict/item",
+			Api:     []string{"Add", "Delete", "Update", "Info", "List", "Page"},
+			Service: newDictItemService(),
+		},
+	})
+}
@@ -0,0 +1,147 @@
+package dzhcore_test
+
+import (
+	"testing"
+
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gzdzh-cn/dzhcore"
+	"github.com/gzdzh-cn/dzhcore/dzhcoretest"
+)
+
+func init() {
+	s := dzhcore.NewModelService(&testContact{})
+	s.Dao = dzhcore.NewModelDao(&testContact{})
+	s.DictFields = g.MapStrStr{"city": "test_city"}
+	testOptions.Models = append(testOptions.Models, &dzhcore.DictType{}, &dzhcore.DictItem{})
+	testOptions.Controllers = append(testOptions.Controllers,
+		&dzhcore.Controller{
+			Prefix:  "/admin/dict/type",
+			Api:     []string{"Add", "Delete", "Update", "List"},
+			Service: dzhcore.NewDictTypeService(),
+		},
+		&dzhcore.DictItemController{Controller: &dzhcore.Controller{
+			Prefix:  "/admin/dict/item",
+			Api:     []string{"Add", "Delete", "Update", "List"},
+			Service: dzhcore.NewDictItemService(),
+		}},
+		&dzhcore.Controller{Prefix: "/admin/test/contactDict", Api: []string{"List"}, Service: s},
+	)
+}
+
+func setupDict(t *testing.T) *dzhcoretest.App {
+	app := dzhcoretest.Setup(t)
+	app.Fixture(t, dzhcore.TableNameDictType, g.List{{"id": "t1", "name": "城市", "key": "test_city"}})
+	app.Fixture(t, dzhcore.TableNameDictItem, g.List{
+		{"id": "i1", "typeKey": "test_city", "label": "北京", "value": "bj", "status": 1},
+		{"id": "i2", "typeKey": "test_city", "label": "上海", "value": "sh", "status": 1},
+		{"id": "i3", "typeKey": "test_city", "label": "广州", "value": "gz", "status": 0},
+	})
+	return app
+}
+
+func TestGetDictLabel(t *testing.T) {
+	app := setupDict(t)
+	for value, want := range map[string]string{"bj": "北京", "sh": "上海", "gz": "", "xx": ""} {
+		if got := dzhcore.GetDictLabel(app.Ctx, "test_city", value); got != want {
+			t.Errorf("%s 的名称 %q, 期望 %q", value, got, want)
+		}
+	}
+	if got := dzhcore.GetDictLabel(app.Ctx, "test_none", "bj"); got != "" {
+		t.Errorf("不存在的字典类型返回 %q", got)
+	}
+}
+
+// 修改字典项后清除对应字典类型的缓存
+func TestDictCache(t *testing.T) {
+	app := setupDict(t)
+	if got := dzhcore.GetDictLabel(app.Ctx, "test_city", "bj"); got != "北京" {
+		t.Fatalf("bj 的名称 %q, 期望 北京", got)
+	}
+	res := app.Client.Update(t, "/admin/dict/item", g.Map{"id": "i1", "label": "北京市"})
+	if !res.Ok() {
+		t.Fatalf("修改字典项失败: %s", res.Body)
+	}
+	if got := dzhcore.GetDictLabel(app.Ctx, "test_city", "bj"); got != "北京市" {
+		t.Fatalf("修改后 bj 的名称 %q, 期望 北京市", got)
+	}
+
+	// 字典项移到其他类型时两个类型的缓存都清除
+	if got := dzhcore.GetDictLabel(app.Ctx, "test_area", "sh"); got != "" {
+		t.Fatalf("test_area 中 sh 的名称 %q, 期望为空", got)
+	}
+	if res = app.Client.Update(t, "/admin/dict/item", g.Map{"id": "i2", "typeKey": "test_area"}); !res.Ok() {
+		t.Fatalf("修改字典项失败: %s", res.Body)
+	}
+	if got := dzhcore.GetDictLabel(app.Ctx, "test_city", "sh"); got != "" {
+		t.Errorf("移动后 test_city 中 sh 的名称 %q, 期望为空", got)
+	}
+	if got := dzhcore.GetDictLabel(app.Ctx, "test_area", "sh"); got != "上海" {
+		t.Errorf("移动后 test_area 中 sh 的名称 %q, 期望 上海", got)
+	}
+
+	if res = app.Client.Post(t, "/admin/dict/item/delete", g.Map{"ids": g.Slice{"i1"}}); !res.Ok() {
+		t.Fatalf("删除字典项失败: %s", res.Body)
+	}
+	if got := dzhcore.GetDictLabel(app.Ctx, "test_city", "bj"); got != "" {
+		t.Fatalf("删除后 bj 的名称 %q, 期望为空", got)
+	}
+}
+
+// 修改字典类型标识时字典项跟随修改
+func TestDictTypeRename(t *testing.T) {
+	app := setupDict(t)
+	if got := dzhcore.GetDictLabel(app.Ctx, "test_city", "bj"); got != "北京" {
+		t.Fatalf("bj 的名称 %q, 期望 北京", got)
+	}
+	res := app.Client.Update(t, "/admin/dict/type", g.Map{"id": "t1", "name": "城市", "key": "test_city2"})
+	if !res.Ok() {
+		t.Fatalf("修改字典类型失败: %s", res.Body)
+	}
+	if count, _ := g.DB().Model(dzhcore.TableNameDictItem).Where("typeKey", "test_city2").Count(); count != 3 {
+		t.Fatalf("修改标识后字典项 %d 条, 期望 3", count)
+	}
+	if got := dzhcore.GetDictLabel(app.Ctx, "test_city", "bj"); got != "" {
+		t.Errorf("原标识 bj 的名称 %q, 期望为空", got)
+	}
+	if got := dzhcore.GetDictLabel(app.Ctx, "test_city2", "bj"); got != "北京" {
+		t.Errorf("新标识 bj 的名称 %q, 期望 北京", got)
+	}
+
+	// 删除字典类型时删除字典项
+	if res = app.Client.Post(t, "/admin/dict/type/delete", g.Map{"ids": g.Slice{"t1"}}); !res.Ok() {
+		t.Fatalf("删除字典类型失败: %s", res.Body)
+	}
+	if count, _ := g.DB().Model(dzhcore.TableNameDictItem).Count(); count != 0 {
+		t.Fatalf("删除字典类型后仍有 %d 条字典项", count)
+	}
+	if got := dzhcore.GetDictLabel(app.Ctx, "test_city2", "bj"); got != "" {
+		t.Errorf("删除后 bj 的名称 %q, 期望为空", got)
+	}
+}
+
+// DictFields 追加 <字段名>Label
+func TestDictFields(t *testing.T) {
+	app := setupDict(t)
+	app.Fixture(t, "test_contact", g.List{
+		{"id": "1", "name": "a", "city": "bj"},
+		{"id": "2", "name": "b", "city": "gz"},
+		{"id": "3", "name": "c", "city": "xx"},
+	})
+	res := app.Client.Post(t, "/admin/test/contactDict/list", nil)
+	if !res.Ok() {
+		t.Fatalf("列表失败: %s", res.Body)
+	}
+	labels := map[string]string{}
+	for _, record := range res.Json().GetJsons(".") {
+		if !record.Contains("cityLabel") {
+			t.Fatalf("数据 %s 没有 cityLabel", record.MustToJsonString())
+		}
+		labels[record.Get("id").String()] = record.Get("cityLabel").String()
+	}
+	want := map[string]string{"1": "北京", "2": "", "3": ""}
+	for id, label := range want {
+		if labels[id] != label {
+			t.Errorf("%s 的 cityLabel %q, 期望 %q", id, labels[id], label)
+		}
+	}
+}
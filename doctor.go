@@ -0,0 +1,227 @@
+package dzhcore
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/gogf/gf/v2/database/gredis"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gcfg"
+	"github.com/gogf/gf/v2/os/gfile"
+	"github.com/gzdzh-cn/dzhcore/coreconfig"
+	"github.com/gzdzh-cn/dzhcore/corefile"
+	"github.com/gzdzh-cn/dzhcore/utility/util"
+)
+
+// 启动前检查,检查配置、数据库、redis、文件上传和目录权限,供 doctor 命令使用
+// 启动时这些问题通常在 NewInit 中 panic,检查时每一项单独执行,panic 记为失败
+
+const (
+	DoctorPass = "pass" // 通过
+	DoctorWarn = "warn" // 警告,可以启动但可能不符合预期
+	DoctorFail = "fail" // 失败,启动时会出错
+)
+
+// 默认的 jwt 密钥,与 coreconfig 一致
+const defaultJwtSecret = "88888888"
+
+// DoctorResult 检查结果
+type DoctorResult struct {
+	Name    string `json:"name"`    // 检查项
+	Status  string `json:"status"`  // pass、warn、fail
+	Message string `json:"message"` // 结果说明
+	Hint    string `json:"hint"`    // 修复建议
+}
+
+// RunDoctor 执行全部检查,会初始化配置、日志和数据库连接
+func RunDoctor(ctx context.Context) (list []*DoctorResult) {
+	add := func(name string, check func() []*DoctorResult) {
+		defer func() {
+			if r := recover(); r != nil {
+				list = append(list, &DoctorResult{Name: name, Status: DoctorFail, Message: fmt.Sprint(r)})
+			}
+		}()
+		for _, result := range check() {
+			if result.Name == "" {
+				result.Name = name
+			}
+			list = append(list, result)
+		}
+	}
+	add("配置文件", doctorConfigFile)
+	add("配置项", func() []*DoctorResult { return doctorConfigItems(ctx) })
+	add("初始化", func() []*DoctorResult {
+		NewInitDataBase()
+		return []*DoctorResult{{Status: DoctorPass, Message: "配置、日志和数据库连接初始化完成"}}
+	})
+	add("数据库", func() []*DoctorResult { return doctorDatabase(ctx) })
+	add("redis", func() []*DoctorResult { return doctorRedis(ctx) })
+	add("文件上传", func() []*DoctorResult { return doctorFile(ctx) })
+	add("目录权限", doctorDirs)
+	add("jwt", doctorJwt)
+	return
+}
+
+func doctorConfigFile() []*DoctorResult {
+	var results []*DoctorResult
+	adapter, ok := g.Cfg().GetAdapter().(*gcfg.AdapterFile)
+	if ok {
+		if path, _ := adapter.GetFilePath(); path != "" {
+			results = append(results, &DoctorResult{Status: DoctorPass, Message: path})
+		} else {
+			results = append(results, &DoctorResult{
+				Status:  DoctorFail,
+				Message: "未找到配置文件",
+				Hint:    "在 manifest/config/config.yaml 或 config.yaml 中添加配置,或通过 GF_GCFG_FILE 指定配置文件",
+			})
+		}
+	}
+	if gfile.Exists(".env") {
+		results = append(results, &DoctorResult{Name: ".env", Status: DoctorPass, Message: "已读取 .env"})
+	} else {
+		results = append(results, &DoctorResult{Name: ".env", Status: DoctorPass, Message: "未找到 .env,只使用配置文件和环境变量"})
+	}
+	return results
+}
+
+func doctorConfigItems(ctx context.Context) []*DoctorResult {
+	issues, err := coreconfig.CheckConfig(ctx)
+	if err != nil {
+		return []*DoctorResult{{Status: DoctorFail, Message: err.Error(), Hint: "检查配置文件的格式"}}
+	}
+	if len(issues) == 0 {
+		return []*DoctorResult{{Status: DoctorPass, Message: "配置项与 defineStruct.Config 一致"}}
+	}
+	results := make([]*DoctorResult, 0, len(issues))
+	for _, issue := range issues {
+		result := &DoctorResult{Status: DoctorWarn, Message: issue.Key + " " + issue.Message, Hint: "修改或删除该配置项"}
+		if issue.Level == coreconfig.IssueFail {
+			result.Status = DoctorFail
+			result.Hint = "按 defineStruct.Config 中的类型修改该配置项"
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+func doctorDatabase(ctx context.Context) (results []*DoctorResult) {
+	tool := util.NewToolUtil()
+	for _, group := range DbGroups {
+		var (
+			name   = "数据库 " + group
+			config = g.DB(group).GetConfig()
+			health = PingDb(ctx, group)
+		)
+		if health.Status != DbStatusUp {
+			results = append(results, &DoctorResult{
+				Name:    name,
+				Status:  DoctorFail,
+				Message: fmt.Sprintf("%s 连接失败: %s", health.Type, health.Error),
+				Hint:    fmt.Sprintf("检查 database.%s 的地址、用户名、密码,并确认项目已导入 %s 的驱动", group, health.Type),
+			})
+			continue
+		}
+		results = append(results, &DoctorResult{Name: name, Status: DoctorPass, Message: fmt.Sprintf("%s 连接正常,耗时 %dms", health.Type, health.Latency)})
+		if config.Type == "sqlite" && config.Name != "" {
+			if err := tool.CheckWritable(filepath.Dir(config.Name)); err != nil {
+				results = append(results, &DoctorResult{Name: name, Status: DoctorFail, Message: err.Error(), Hint: "修改 sqlite 数据库目录的权限"})
+			}
+		}
+	}
+	return
+}
+
+func doctorRedis(ctx context.Context) []*DoctorResult {
+	cfg := coreconfig.Config.Redis
+	if !cfg.Enable {
+		return []*DoctorResult{{Status: DoctorPass, Message: "未开启"}}
+	}
+	ping := func(name string, db int) *DoctorResult {
+		redis, err := gredis.New(&gredis.Config{
+			Address:     cfg.CfRedis.Address,
+			Db:          db,
+			Pass:        cfg.CfRedis.Pass,
+			DialTimeout: 3 * time.Second,
+		})
+		if err == nil {
+			defer redis.Close(ctx)
+			_, err = redis.Do(ctx, "PING")
+		}
+		if err != nil {
+			return &DoctorResult{
+				Name:    name,
+				Status:  DoctorFail,
+				Message: fmt.Sprintf("%s db %d 连接失败: %v", cfg.CfRedis.Address, db, err),
+				Hint:    "检查 redis.cfRedis 的 address、pass,并确认项目已导入 github.com/gogf/gf/contrib/nosql/redis/v2",
+			}
+		}
+		return &DoctorResult{Name: name, Status: DoctorPass, Message: fmt.Sprintf("%s db %d 连接正常", cfg.CfRedis.Address, db)}
+	}
+	results := []*DoctorResult{ping("redis", cfg.CfRedis.DB)}
+	// 数据库查询缓存使用相同的地址和 dbRedis.db
+	if cfg.DBRedis.Enable {
+		results = append(results, ping("redis 查询缓存", cfg.DBRedis.DB))
+	}
+	return results
+}
+
+func doctorFile(ctx context.Context) []*DoctorResult {
+	mode := coreconfig.Config.Core.File.Mode
+	checker, ok := corefile.Checkers[mode]
+	if !ok {
+		if _, ok = corefile.FileMap[mode]; ok {
+			return []*DoctorResult{{Status: DoctorPass, Message: fmt.Sprintf("%s 驱动没有提供检查", mode)}}
+		}
+		return []*DoctorResult{{
+			Status:  DoctorFail,
+			Message: fmt.Sprintf("未知的上传类型 %s", mode),
+			Hint:    fmt.Sprintf("检查 core.file.mode 的拼写,或导入 github.com/gzdzh-cn/dzhcore/contrib/files/%s", mode),
+		}}
+	}
+	message, err := checker(ctx)
+	if err != nil {
+		return []*DoctorResult{{Status: DoctorFail, Message: fmt.Sprintf("%s: %v", mode, err), Hint: "检查 core.file 下的配置"}}
+	}
+	return []*DoctorResult{{Status: DoctorPass, Message: fmt.Sprintf("%s: %s", mode, message)}}
+}
+
+func doctorDirs() (results []*DoctorResult) {
+	var (
+		tool = util.NewToolUtil()
+		core = coreconfig.Config.Core
+		dirs = [][2]string{
+			{"core.gfLogger.path", core.GFLogger.Path},
+			{"core.sqlLogger.path", core.SQLLogger.Path},
+		}
+	)
+	if core.RunLogger.Enable {
+		dirs = append(dirs, [2]string{"core.runLogger.path", core.RunLogger.Path})
+	}
+	if core.Backup.Enable {
+		dirs = append(dirs, [2]string{"core.backup.path", core.Backup.Path})
+	}
+	for _, dir := range dirs {
+		if dir[1] == "" {
+			continue
+		}
+		if err := tool.CheckWritable(dir[1]); err != nil {
+			results = append(results, &DoctorResult{Status: DoctorFail, Message: fmt.Sprintf("%s: %v", dir[0], err), Hint: "修改目录权限或配置其他目录"})
+			continue
+		}
+		results = append(results, &DoctorResult{Status: DoctorPass, Message: fmt.Sprintf("%s: %s 可写", dir[0], dir[1])})
+	}
+	return
+}
+
+func doctorJwt() []*DoctorResult {
+	if coreconfig.Config.Modules.Base.JWT.Secret == defaultJwtSecret {
+		return []*DoctorResult{{
+			Status:  DoctorWarn,
+			Message: "使用默认的 jwt 密钥",
+			Hint:    "在 .env 中设置 MODULES_BASE_JWT_SECRET 或修改 modules.base.jwt.secret",
+		}}
+	}
+	return []*DoctorResult{{Status: DoctorPass, Message: "已设置 jwt 密钥"}}
+}
@@ -0,0 +1,82 @@
+package dzhcore_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gzdzh-cn/dzhcore"
+	"github.com/gzdzh-cn/dzhcore/coreconfig"
+	"github.com/gzdzh-cn/dzhcore/dzhcoretest"
+)
+
+// 检查结果 名称 状态,多项用逗号分隔
+func doctorText(list []*dzhcore.DoctorResult) string {
+	items := make([]string, 0, len(list))
+	for _, result := range list {
+		items = append(items, result.Name+" "+result.Status)
+	}
+	return strings.Join(items, ",")
+}
+
+func TestDoctorDatabase(t *testing.T) {
+	app := dzhcoretest.Setup(t)
+	list := dzhcore.DoctorDatabase(app.Ctx)
+	if got := doctorText(list); got != "数据库 default pass,数据库 log pass" {
+		t.Fatalf("检查结果 %s", got)
+	}
+}
+
+// 未知的上传类型在启动时会 panic,检查时为失败并提示导入驱动
+func TestDoctorFile(t *testing.T) {
+	app := dzhcoretest.Setup(t)
+	mode := coreconfig.Config.Core.File.Mode
+	t.Cleanup(func() { coreconfig.Config.Core.File.Mode = mode })
+	coreconfig.Config.Core.File.Mode = "unknown"
+	list := dzhcore.DoctorFile(app.Ctx)
+	if len(list) != 1 || list[0].Status != dzhcore.DoctorFail || !strings.Contains(list[0].Hint, "contrib/files/unknown") {
+		t.Fatalf("检查结果 %+v", list[0])
+	}
+}
+
+// 日志目录的上级是文件时不可写
+func TestDoctorDirs(t *testing.T) {
+	dzhcoretest.Setup(t)
+	file := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(file, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := coreconfig.Config.Core
+	t.Cleanup(func() { coreconfig.Config.Core = cfg })
+	coreconfig.Config.Core.GFLogger.Path = filepath.Join(file, "logs")
+	coreconfig.Config.Core.SQLLogger.Path = t.TempDir()
+	coreconfig.Config.Core.RunLogger.Enable = false
+	coreconfig.Config.Core.Backup.Enable = false
+
+	list := dzhcore.DoctorDirs()
+	if len(list) != 2 || list[0].Status != dzhcore.DoctorFail || list[1].Status != dzhcore.DoctorPass {
+		t.Fatalf("检查结果 %s", doctorText(list))
+	}
+	if !strings.HasPrefix(list[0].Message, "core.gfLogger.path") || !strings.HasPrefix(list[1].Message, "core.sqlLogger.path") {
+		t.Fatalf("检查项 %s、%s", list[0].Message, list[1].Message)
+	}
+}
+
+func TestDoctorJwt(t *testing.T) {
+	dzhcoretest.Setup(t)
+	secret := coreconfig.Config.Modules.Base.JWT.Secret
+	t.Cleanup(func() { coreconfig.Config.Modules.Base.JWT.Secret = secret })
+	for _, tc := range []struct {
+		secret string
+		status string
+	}{
+		{"88888888", dzhcore.DoctorWarn},
+		{"changed", dzhcore.DoctorPass},
+	} {
+		coreconfig.Config.Modules.Base.JWT.Secret = tc.secret
+		if list := dzhcore.DoctorJwt(); list[0].Status != tc.status {
+			t.Errorf("密钥 %s 的检查结果 %s, 期望 %s", tc.secret, list[0].Status, tc.status)
+		}
+	}
+}
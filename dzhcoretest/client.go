@@ -0,0 +1,144 @@
+package dzhcoretest
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gogf/gf/v2/encoding/gjson"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/util/gconv"
+	"github.com/gzdzh-cn/dzhcore"
+)
+
+// 请求成功时 BaseRes 的 code
+const CodeOk = 1000
+
+// Client 请求客户端,不经过网络,直接由测试应用的 Server 处理请求
+type Client struct {
+	handler http.Handler
+	Header  http.Header // 每个请求都带上的请求头,如 Authorization
+}
+
+// Response 响应结果,响应内容为 json 时解析到 BaseRes
+type Response struct {
+	Status int    // http 状态码
+	Body   []byte // 响应内容
+	dzhcore.BaseRes
+}
+
+// NewClient 创建请求客户端
+func NewClient(handler http.Handler) *Client {
+	return &Client{
+		handler: handler,
+		Header:  make(http.Header),
+	}
+}
+
+// Ok 是否请求成功
+func (r *Response) Ok() bool {
+	return r.Status == http.StatusOK && r.Code == CodeOk
+}
+
+// Scan 把 data 转换到 pointer
+func (r *Response) Scan(pointer interface{}) error {
+	return gconv.Scan(r.Data, pointer)
+}
+
+// Json data 的 json 对象
+func (r *Response) Json() *gjson.Json {
+	return gjson.New(r.Data)
+}
+
+// Request 发送请求,data 作为 json 请求体,失败时结束测试
+func (c *Client) Request(t testing.TB, method, path string, data interface{}) *Response {
+	t.Helper()
+	var body []byte
+	if data != nil {
+		var err error
+		if body, err = gjson.Marshal(data); err != nil {
+			t.Fatalf("请求 %s %s 失败: %v", method, path, err)
+		}
+	}
+	req := httptest.NewRequest(method, path, bytes.NewReader(body))
+	if data != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for k, v := range c.Header {
+		req.Header[k] = v
+	}
+	w := httptest.NewRecorder()
+	c.handler.ServeHTTP(w, req)
+
+	res := &Response{
+		Status: w.Code,
+		Body:   w.Body.Bytes(),
+	}
+	if strings.HasPrefix(w.Header().Get("Content-Type"), "application/json") {
+		if err := gjson.Unmarshal(res.Body, &res.BaseRes); err != nil {
+			t.Fatalf("解析 %s %s 响应失败: %v, %s", method, path, err, res.Body)
+		}
+	}
+	return res
+}
+
+// Get 发送 GET 请求,params 作为查询参数
+func (c *Client) Get(t testing.TB, path string, params ...g.Map) *Response {
+	t.Helper()
+	if len(params) > 0 && len(params[0]) > 0 {
+		query := make(url.Values)
+		for k, v := range params[0] {
+			query.Set(k, gconv.String(v))
+		}
+		path += "?" + query.Encode()
+	}
+	return c.Request(t, http.MethodGet, path, nil)
+}
+
+// Post 发送 POST 请求
+func (c *Client) Post(t testing.TB, path string, data interface{}) *Response {
+	t.Helper()
+	if m, ok := data.(g.Map); data == nil || (ok && m == nil) {
+		data = g.Map{}
+	}
+	return c.Request(t, http.MethodPost, path, data)
+}
+
+// Add 新增 prefix 为控制器的路由前缀
+func (c *Client) Add(t testing.TB, prefix string, data g.Map) *Response {
+	t.Helper()
+	return c.Post(t, prefix+"/add", data)
+}
+
+// Delete 删除
+func (c *Client) Delete(t testing.TB, prefix string, ids ...interface{}) *Response {
+	t.Helper()
+	return c.Post(t, prefix+"/delete", g.Map{"ids": ids})
+}
+
+// Update 修改
+func (c *Client) Update(t testing.TB, prefix string, data g.Map) *Response {
+	t.Helper()
+	return c.Post(t, prefix+"/update", data)
+}
+
+// Info 详情
+func (c *Client) Info(t testing.TB, prefix string, id interface{}) *Response {
+	t.Helper()
+	return c.Get(t, prefix+"/info", g.Map{"id": id})
+}
+
+// List 列表
+func (c *Client) List(t testing.TB, prefix string, params g.Map) *Response {
+	t.Helper()
+	return c.Post(t, prefix+"/list", params)
+}
+
+// Page 分页
+func (c *Client) Page(t testing.TB, prefix string, params g.Map) *Response {
+	t.Helper()
+	return c.Post(t, prefix+"/page", params)
+}
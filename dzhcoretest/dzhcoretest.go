@@ -0,0 +1,193 @@
+// Package dzhcoretest 测试工具,使用内存 sqlite 启动 dzhcore,注册模型、控制器和种子数据后提供请求客户端、测试数据和数据重置.
+//
+// 同一进程只初始化一次,之后每次 Setup 把 sqlite 分组的数据恢复到初始化完成时的状态,测试之间不能并行.
+//
+//	func TestUser(t *testing.T) {
+//		app := dzhcoretest.Setup(t, &dzhcoretest.Options{
+//			Models:      []dzhcore.IModel{&model.User{}},
+//			Controllers: []dzhcore.IController{user.NewUserController()},
+//		})
+//		app.Fixture(t, "user", g.List{{"name": "a"}})
+//		res := app.Client.Page(t, "/admin/user", nil)
+//	}
+package dzhcoretest
+
+import (
+	"context"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/gogf/gf/v2/encoding/gjson"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/net/ghttp"
+	"github.com/gogf/gf/v2/os/gcfg"
+	"github.com/gogf/gf/v2/os/gctx"
+	"github.com/gogf/gf/v2/os/gfile"
+	"github.com/gzdzh-cn/dzhcore"
+	"github.com/gzdzh-cn/dzhcore/contrib/drivers/sqlitemem"
+	"github.com/gzdzh-cn/dzhcore/coreconfig"
+)
+
+var (
+	bootOnce sync.Once
+	bootApp  *App
+	bootErr  error
+)
+
+// Options 测试应用配置,只在第一次初始化时生效
+type Options struct {
+	Config            string                      // yaml 或 json 配置,与默认测试配置合并
+	Models            []dzhcore.IModel            // 模型,初始化时建表
+	Controllers       []dzhcore.IController       // 控制器
+	ControllerSimples []dzhcore.IControllerSimple // 不带crud的控制器
+	Seeds             []*dzhcore.Seed             // 种子数据,初始化时执行
+}
+
+// App 测试应用
+type App struct {
+	Ctx       context.Context
+	Server    *ghttp.Server
+	Client    *Client
+	snapshots []*snapshot
+}
+
+// Setup 初始化测试应用并把数据恢复到初始化完成时的状态,失败时结束测试
+func Setup(t testing.TB, opts ...*Options) *App {
+	t.Helper()
+	var opt *Options
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	app, err := Boot(opt)
+	if err != nil {
+		t.Fatalf("初始化测试应用失败: %v", err)
+	}
+	app.Reset(t)
+	return app
+}
+
+// Boot 初始化测试应用,同一进程只初始化一次,可在 TestMain 中调用
+func Boot(opts *Options) (*App, error) {
+	bootOnce.Do(func() {
+		if opts == nil {
+			opts = &Options{}
+		}
+		bootApp, bootErr = boot(opts)
+	})
+	return bootApp, bootErr
+}
+
+func boot(opts *Options) (app *App, err error) {
+	ctx := gctx.New()
+	content, err := config(opts.Config)
+	if err != nil {
+		return nil, err
+	}
+	adapter, err := gcfg.NewAdapterContent(content)
+	if err != nil {
+		return nil, err
+	}
+	g.Cfg().SetAdapter(adapter)
+	coreconfig.Reload()
+
+	for _, model := range opts.Models {
+		dzhcore.AddModel(model)
+	}
+	for _, controller := range opts.Controllers {
+		dzhcore.AddController(controller)
+	}
+	for _, controller := range opts.ControllerSimples {
+		dzhcore.AddControllerSimple(controller)
+	}
+	dzhcore.AddSeed(opts.Seeds...)
+
+	// 建表、迁移、种子数据失败时 NewInit 会 panic
+	defer func() {
+		if e := recover(); e != nil {
+			app, err = nil, gerror.Newf("%v", e)
+		}
+	}()
+	dzhcore.NewInit()
+
+	s := g.Server()
+	s.SetAddr("127.0.0.1:0")
+	s.SetDumpRouterMap(false)
+	if err = s.Start(); err != nil {
+		return nil, err
+	}
+	app = &App{
+		Ctx:    ctx,
+		Server: s,
+		Client: NewClient(s),
+	}
+	if err = app.takeSnapshot(ctx); err != nil {
+		return nil, err
+	}
+	return app, nil
+}
+
+// 默认测试配置,数据库使用内存 sqlite,日志、上传文件和备份写入临时目录
+// 测试应用在进程内只初始化一次,临时目录不能使用第一个测试的 t.TempDir(),该目录在第一个测试结束时删除
+func defaultConfig() (g.Map, error) {
+	dir, err := os.MkdirTemp("", "dzhcoretest")
+	if err != nil {
+		return nil, err
+	}
+	return g.Map{
+		"server": g.Map{
+			"address": "127.0.0.1:0",
+		},
+		"database": g.Map{
+			"default": g.Map{
+				"type":      sqlitemem.DriverName,
+				"name":      "dzhcoretest",
+				"createdAt": "createTime",
+				"updatedAt": "updateTime",
+			},
+		},
+		"core": g.Map{
+			"autoMigrate": true,
+			"gfLogger":    g.Map{"path": gfile.Join(dir, "logs"), "level": "error"},
+			"sqlLogger":   g.Map{"path": gfile.Join(dir, "sql")},
+			"runLogger":   g.Map{"path": gfile.Join(dir, "run"), "enable": false},
+			"file":        g.Map{"uploadPath": gfile.Join(dir, "uploads")},
+			"backup":      g.Map{"enable": false, "checkOnStart": false, "path": gfile.Join(dir, "backup")},
+			"dbHealth":    g.Map{"enable": false},
+		},
+	}, nil
+}
+
+// 合并默认配置和自定义配置
+func config(content string) (string, error) {
+	cfg, err := defaultConfig()
+	if err != nil {
+		return "", err
+	}
+	if content != "" {
+		custom, err := gjson.LoadContent([]byte(content))
+		if err != nil {
+			return "", err
+		}
+		mergeMap(cfg, custom.Map())
+	}
+	return gjson.New(cfg).ToJsonString()
+}
+
+// 递归合并,src 覆盖 dst
+func mergeMap(dst, src g.Map) {
+	for k, v := range src {
+		srcMap, ok := v.(map[string]interface{})
+		if !ok {
+			dst[k] = v
+			continue
+		}
+		dstMap, ok := dst[k].(g.Map)
+		if !ok {
+			dst[k] = srcMap
+			continue
+		}
+		mergeMap(dstMap, srcMap)
+	}
+}
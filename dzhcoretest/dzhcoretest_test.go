@@ -0,0 +1,110 @@
+package dzhcoretest_test
+
+import (
+	"testing"
+
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gzdzh-cn/dzhcore"
+	"github.com/gzdzh-cn/dzhcore/dzhcoretest"
+)
+
+type testTag struct {
+	*dzhcore.Model
+	Name string `gorm:"column:name;type:varchar(255);uniqueIndex" json:"name"`
+}
+
+func (*testTag) TableName() string {
+	return "test_tag"
+}
+
+func (*testTag) GroupName() string {
+	return "default"
+}
+
+func setup(t *testing.T) *dzhcoretest.App {
+	s := dzhcore.NewModelService(&testTag{})
+	s.Dao = dzhcore.NewModelDao(&testTag{})
+	return dzhcoretest.Setup(t, &dzhcoretest.Options{
+		Models: []dzhcore.IModel{&testTag{}},
+		Controllers: []dzhcore.IController{&dzhcore.Controller{
+			Prefix:  "/admin/test/tag",
+			Api:     []string{"Add", "Page"},
+			Service: s,
+		}},
+		Seeds: []*dzhcore.Seed{{
+			Module: "test",
+			Table:  "test_tag",
+			Keys:   []string{"name"},
+			Data:   g.List{{"name": "seeded"}},
+		}},
+	})
+}
+
+func TestAddAndPage(t *testing.T) {
+	app := setup(t)
+	res := app.Client.Add(t, "/admin/test/tag", g.Map{"name": "a"})
+	if !res.Ok() {
+		t.Fatalf("新增失败: %s", res.Body)
+	}
+	if res.Json().Get("id").String() == "" {
+		t.Fatalf("新增没有返回 id: %s", res.Body)
+	}
+
+	page := app.Client.Page(t, "/admin/test/tag", nil)
+	if !page.Ok() {
+		t.Fatalf("分页失败: %s", page.Body)
+	}
+	if total := page.Json().Get("pagination.total").Int(); total != 2 {
+		t.Fatalf("分页总数 %d, 期望 2", total)
+	}
+}
+
+// 每次 Setup 恢复到初始化完成时的数据,只保留种子数据
+func TestSetupResetsData(t *testing.T) {
+	app := setup(t)
+	app.Fixture(t, "test_tag", g.List{{"name": "b"}, {"name": "c"}})
+	if count, _ := g.DB().Model("test_tag").Count(); count != 3 {
+		t.Fatalf("写入测试数据后 %d 条, 期望 3", count)
+	}
+
+	setup(t)
+	names, err := g.DB().Model("test_tag").Array("name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 1 || names[0].String() != "seeded" {
+		t.Fatalf("重置后数据 %v, 期望只有 seeded", names)
+	}
+}
+
+// 内存库从备份恢复表结构和数据
+func TestRestoreMemory(t *testing.T) {
+	app := setup(t)
+	app.Fixture(t, "test_tag", g.List{{"name": "before"}})
+	file, err := dzhcore.BackupDatabase(app.Ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	app.Fixture(t, "test_tag", g.List{{"name": "after"}})
+	if _, err = g.DB().Exec(app.Ctx, "CREATE TABLE test_extra (id INTEGER)"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = dzhcore.RestoreDatabase(app.Ctx, file.Name); err != nil {
+		t.Fatal(err)
+	}
+	names, err := g.DB().Model("test_tag").Order("name").Array("name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 2 || names[0].String() != "before" || names[1].String() != "seeded" {
+		t.Fatalf("恢复后数据 %v, 期望 [before seeded]", names)
+	}
+	if _, err = g.DB().GetValue(app.Ctx, "SELECT count(*) FROM test_extra"); err == nil {
+		t.Fatal("恢复后仍有备份之后创建的表 test_extra")
+	}
+	// 唯一索引随表结构一起恢复
+	if _, err = g.DB().Model("test_tag").Data(g.Map{"id": "x", "name": "before"}).Insert(); err == nil {
+		t.Fatal("恢复后唯一索引丢失")
+	}
+}
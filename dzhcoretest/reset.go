@@ -0,0 +1,177 @@
+package dzhcoretest
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path"
+	"testing"
+
+	"github.com/gogf/gf/v2/database/gdb"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gfile"
+	"github.com/gzdzh-cn/dzhcore"
+	"github.com/gzdzh-cn/dzhcore/contrib/drivers/sqlitemem"
+)
+
+// 初始化完成时的表数据
+type snapshot struct {
+	group string
+	table string
+	rows  gdb.Result
+}
+
+// 内存 sqlite 和 sqlite 文件分组,其他数据库不重置
+func sqliteGroups() (groups []string) {
+	for _, group := range dzhcore.DbGroups {
+		switch g.DB(group).GetConfig().Type {
+		case sqlitemem.DriverName, "sqlite":
+			groups = append(groups, group)
+		}
+	}
+	return
+}
+
+// 分组的全部数据表,包括自增序号表,不包括全文索引的影子表
+func tables(ctx context.Context, db gdb.DB) ([]string, error) {
+	result, err := db.GetAll(ctx, `SELECT name FROM pragma_table_list WHERE schema = 'main' AND type = 'table' AND (name NOT LIKE 'sqlite_%' OR name = 'sqlite_sequence')`)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(result))
+	for _, record := range result {
+		names = append(names, record["name"].String())
+	}
+	return names, nil
+}
+
+func (a *App) takeSnapshot(ctx context.Context) error {
+	a.snapshots = nil
+	for _, group := range sqliteGroups() {
+		db := g.DB(group)
+		names, err := tables(ctx, db)
+		if err != nil {
+			return err
+		}
+		for _, table := range names {
+			rows, err := db.GetAll(ctx, fmt.Sprintf("SELECT * FROM `%s`", table))
+			if err != nil {
+				return err
+			}
+			a.snapshots = append(a.snapshots, &snapshot{group: group, table: table, rows: rows})
+		}
+	}
+	return nil
+}
+
+// Reset 清空 sqlite 分组并恢复初始化完成时的数据,同时清空缓存
+func (a *App) Reset(t testing.TB) {
+	t.Helper()
+	if err := a.reset(a.Ctx); err != nil {
+		t.Fatalf("重置测试数据失败: %v", err)
+	}
+}
+
+func (a *App) reset(ctx context.Context) error {
+	for _, group := range sqliteGroups() {
+		db := g.DB(group)
+		names, err := tables(ctx, db)
+		if err != nil {
+			return err
+		}
+		err = db.Transaction(ctx, func(ctx context.Context, tx gdb.TX) error {
+			for _, table := range names {
+				if _, err := tx.Exec(fmt.Sprintf("DELETE FROM `%s`", table)); err != nil {
+					return err
+				}
+			}
+			for _, s := range a.snapshots {
+				if s.group != group || s.rows.IsEmpty() {
+					continue
+				}
+				// 不自动写入创建时间、更新时间
+				if _, err := tx.Model(s.table).Unscoped().Data(s.rows.List()).Insert(); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if err = db.GetCache().Clear(ctx); err != nil {
+			return err
+		}
+	}
+	if err := dzhcore.CacheManager.Clear(ctx); err != nil {
+		return err
+	}
+	return dzhcore.DbCacheManager.Clear(ctx)
+}
+
+// Fixture 写入测试数据,表有 id 字段且数据没有 id 时生成雪花 id,group 为空时为 default
+func (a *App) Fixture(t testing.TB, table string, rows g.List, group ...string) {
+	t.Helper()
+	if err := a.fixture(a.Ctx, table, rows, group...); err != nil {
+		t.Fatalf("写入测试数据 %s 失败: %v", table, err)
+	}
+}
+
+func (a *App) fixture(ctx context.Context, table string, rows g.List, group ...string) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	db := g.DB(group...)
+	fields, err := db.TableFields(ctx, table)
+	if err != nil {
+		return err
+	}
+	_, hasId := fields["id"]
+	data := make(g.List, 0, len(rows))
+	for _, row := range rows {
+		item := make(g.Map, len(row)+1)
+		for k, v := range row {
+			item[k] = v
+		}
+		if _, ok := item["id"]; !ok && hasId {
+			item["id"] = dzhcore.CreateSnowflakeId()
+		}
+		data = append(data, item)
+	}
+	_, err = db.Model(table).Ctx(ctx).Data(data).Insert()
+	return err
+}
+
+// FixtureFile 读取 json、yaml、csv 文件写入测试数据,文件名为表名
+func (a *App) FixtureFile(t testing.TB, file string, group ...string) {
+	t.Helper()
+	rows, err := dzhcore.ParseSeedFile(file, gfile.GetBytes(file))
+	if err != nil {
+		t.Fatalf("读取测试数据 %s 失败: %v", file, err)
+	}
+	a.Fixture(t, gfile.Name(file), rows, group...)
+}
+
+// FixtureFS 读取目录下全部 json、yaml、csv 文件写入测试数据,文件名为表名
+func (a *App) FixtureFS(t testing.TB, fsys fs.FS, dir string, group ...string) {
+	t.Helper()
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		t.Fatalf("读取测试数据目录 %s 失败: %v", dir, err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		file := path.Join(dir, entry.Name())
+		content, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			t.Fatalf("读取测试数据 %s 失败: %v", file, err)
+		}
+		rows, err := dzhcore.ParseSeedFile(file, content)
+		if err != nil {
+			t.Fatalf("读取测试数据 %s 失败: %v", file, err)
+		}
+		a.Fixture(t, gfile.Name(file), rows, group...)
+	}
+}
@@ -0,0 +1,657 @@
+package cmd
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gogf/gf/v2/encoding/gjson"
+	"github.com/gogf/gf/v2/os/gcmd"
+	"github.com/gogf/gf/v2/os/gfile"
+	"github.com/gogf/gf/v2/os/gtime"
+)
+
+var (
+	// Addon 插件打包和安装命令
+	Addon = &gcmd.Command{
+		Name:  "addon",
+		Usage: "addon pack|install|list|remove",
+		Brief: "插件打包、安装、查看和删除",
+	}
+
+	addonPack = &gcmd.Command{
+		Name:  "pack",
+		Usage: "addon pack NAME [-o 文件]",
+		Brief: "打包 addons 下的插件为 zip，包含 manifest.json（名称、版本、依赖、迁移、种子数据、前端文件）",
+		Func:  addonPackFunc,
+		Arguments: []gcmd.Argument{
+			{Name: "name", IsArg: true, Brief: "插件目录名，例如: shop"},
+			{Name: "output", Short: "o", Brief: "输出的文件，默认 插件名-版本.zip"},
+		},
+	}
+
+	addonInstall = &gcmd.Command{
+		Name:  "install",
+		Usage: "addon install <压缩包|git 地址> [-b 分支] [-f] [--skip-migrate]",
+		Brief: "安装插件，解压到 addons 目录，导入到项目并执行插件的迁移",
+		Func:  addonInstallFunc,
+		Arguments: []gcmd.Argument{
+			{Name: "source", IsArg: true, Brief: "dzhgo addon pack 生成的 zip 文件，或 git 仓库地址"},
+			{Name: "branch", Short: "b", Brief: "git 仓库的分支或标签"},
+			{Name: "force", Short: "f", Brief: "插件已存在时覆盖", Orphan: true},
+			{Name: "skip-migrate", Brief: "不执行插件的迁移", Orphan: true},
+		},
+	}
+
+	addonList = &gcmd.Command{
+		Name:  "list",
+		Usage: "addon list",
+		Brief: "列出项目中的插件、版本、来源和是否已导入",
+		Func:  addonListFunc,
+	}
+
+	addonRemove = &gcmd.Command{
+		Name:  "remove",
+		Usage: "addon remove NAME [-f]",
+		Brief: "删除插件目录并取消导入，不删除数据表",
+		Func:  addonRemoveFunc,
+		Arguments: []gcmd.Argument{
+			{Name: "name", IsArg: true, Brief: "插件目录名，例如: shop"},
+			{Name: "force", Short: "f", Brief: "有其他插件依赖时也删除", Orphan: true},
+		},
+	}
+
+	addonNamePattern    = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+	addonVersionPattern = regexp.MustCompile(`Version\s*=\s*"([^"]*)"`)
+	goModRequirePattern = regexp.MustCompile(`^(?:require\s+)?(\S+)\s+(v\S+)`)
+	emptyImportPattern  = regexp.MustCompile(`\n+import \(\s*\)\n`)
+)
+
+// 插件清单文件,在压缩包根目录和安装后的插件目录中
+const addonManifestFile = "manifest.json"
+
+// addonManifest 插件清单
+type addonManifest struct {
+	Name         string     `json:"name"`                // 插件目录名
+	Version      string     `json:"version"`             // 插件版本,读取插件的 config.go 中的 Version
+	Module       string     `json:"module"`              // 打包时项目的 module,安装时替换插件的导入路径
+	Dependencies *addonDeps `json:"dependencies"`        // 依赖
+	Migrations   []string   `json:"migrations"`          // migration 目录下的迁移文件
+	Seeds        []string   `json:"seeds"`               // resource/initjson 目录下的种子数据
+	Frontend     []string   `json:"frontend"`            // frontend 目录下的前端文件
+	PackTime     string     `json:"packTime"`            // 打包时间
+	Source       string     `json:"source,omitempty"`    // 安装来源,安装时写入
+	Installed    string     `json:"installed,omitempty"` // 安装时间
+}
+
+// addonDeps 插件依赖
+type addonDeps struct {
+	Addons  []string       `json:"addons"`  // 依赖的其他插件
+	Modules []*addonModule `json:"modules"` // 依赖的 go module,版本为打包时项目 go.mod 中的版本
+}
+
+type addonModule struct {
+	Path    string `json:"path"`
+	Version string `json:"version"`
+}
+
+// 打包插件
+func addonPackFunc(ctx context.Context, parser *gcmd.Parser) (err error) {
+	name := parser.GetArg(3).String()
+	if name == "" {
+		return fmt.Errorf("请提供插件名称，例如: dzhgo addon pack shop")
+	}
+	dir := filepath.Join("addons", name)
+	if !gfile.IsDir(dir) {
+		return fmt.Errorf("插件不存在: %s", dir)
+	}
+	modName, err := getModName()
+	if err != nil {
+		return err
+	}
+	manifest, err := readAddonManifest(dir, name, modName)
+	if err != nil {
+		return err
+	}
+	output := parser.GetOpt("output", fmt.Sprintf("%s-%s.zip", name, manifest.Version)).String()
+
+	files, err := addonFiles(dir)
+	if err != nil {
+		return err
+	}
+	if err = writeAddonZip(output, dir, name, manifest, files); err != nil {
+		return err
+	}
+	fmt.Printf("已打包插件 %s %s: %s\n", name, manifest.Version, output)
+	fmt.Printf("  文件 %d 个，迁移 %d 个，种子数据 %d 个，前端文件 %d 个\n", len(files), len(manifest.Migrations), len(manifest.Seeds), len(manifest.Frontend))
+	if len(manifest.Dependencies.Addons) > 0 {
+		fmt.Printf("  依赖插件: %s\n", strings.Join(manifest.Dependencies.Addons, ", "))
+	}
+	return nil
+}
+
+// 读取插件目录生成清单
+func readAddonManifest(dir, name, modName string) (*addonManifest, error) {
+	manifest := &addonManifest{
+		Name:         name,
+		Version:      "v0.0.0",
+		Module:       modName,
+		Dependencies: &addonDeps{Addons: []string{}, Modules: []*addonModule{}},
+		Migrations:   []string{},
+		Seeds:        []string{},
+		Frontend:     []string{},
+		PackTime:     gtime.Now().String(),
+	}
+	if match := addonVersionPattern.FindStringSubmatch(gfile.GetContents(filepath.Join(dir, "config.go"))); match != nil {
+		manifest.Version = match[1]
+	} else {
+		fmt.Printf("未在 %s 中找到 Version，使用 %s\n", filepath.Join(dir, "config.go"), manifest.Version)
+	}
+
+	files, err := addonFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		addonPrefix = modName + "/addons/"
+		addons      = make(map[string]bool)
+		imports     = make(map[string]bool)
+	)
+	for _, file := range files {
+		switch {
+		case strings.HasPrefix(file, "migration/"):
+			if file != "migration/migration.go" {
+				manifest.Migrations = append(manifest.Migrations, file)
+			}
+		case strings.HasPrefix(file, "resource/initjson/") && strings.HasSuffix(file, ".json"):
+			manifest.Seeds = append(manifest.Seeds, file)
+		case strings.HasPrefix(file, "frontend/"):
+			manifest.Frontend = append(manifest.Frontend, file)
+		}
+		if !strings.HasSuffix(file, ".go") {
+			continue
+		}
+		f, err := parser.ParseFile(token.NewFileSet(), filepath.Join(dir, file), nil, parser.ImportsOnly)
+		if err != nil {
+			return nil, fmt.Errorf("解析文件失败: %s, 错误: %v", file, err)
+		}
+		for _, spec := range f.Imports {
+			importPath, _ := strconv.Unquote(spec.Path.Value)
+			switch {
+			case strings.HasPrefix(importPath, addonPrefix):
+				other, _, _ := strings.Cut(strings.TrimPrefix(importPath, addonPrefix), "/")
+				if other != name {
+					addons[other] = true
+				}
+			case importPath == modName || strings.HasPrefix(importPath, modName+"/"):
+				fmt.Printf("警告: %s 导入了项目中插件以外的包 %s，安装到其他项目时可能无法编译\n", file, importPath)
+			case strings.Contains(strings.Split(importPath, "/")[0], "."):
+				imports[importPath] = true
+			}
+		}
+	}
+	for addon := range addons {
+		manifest.Dependencies.Addons = append(manifest.Dependencies.Addons, addon)
+	}
+	sort.Strings(manifest.Dependencies.Addons)
+
+	// 导入的包对应 go.mod 中最长匹配的 module
+	requires := goModRequires(gfile.GetContents("go.mod"))
+	modules := make(map[string]bool)
+	for importPath := range imports {
+		var module string
+		for mod := range requires {
+			if (importPath == mod || strings.HasPrefix(importPath, mod+"/")) && len(mod) > len(module) {
+				module = mod
+			}
+		}
+		if module != "" && !modules[module] {
+			modules[module] = true
+			manifest.Dependencies.Modules = append(manifest.Dependencies.Modules, &addonModule{Path: module, Version: requires[module]})
+		}
+	}
+	sort.Slice(manifest.Dependencies.Modules, func(i, j int) bool {
+		return manifest.Dependencies.Modules[i].Path < manifest.Dependencies.Modules[j].Path
+	})
+	return manifest, nil
+}
+
+// 插件目录下需要打包的文件,相对路径使用 /,不包含隐藏文件和清单
+func addonFiles(dir string) (files []string, err error) {
+	err = filepath.Walk(dir, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, file)
+		if err != nil || rel == "." {
+			return err
+		}
+		if strings.HasPrefix(info.Name(), ".") {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+		if !info.IsDir() && rel != addonManifestFile {
+			files = append(files, rel)
+		}
+		return nil
+	})
+	sort.Strings(files)
+	return
+}
+
+// 写入压缩包,根目录为 manifest.json 和插件目录
+func writeAddonZip(output, dir, name string, manifest *addonManifest, files []string) (err error) {
+	if err = gfile.Mkdir(filepath.Dir(output)); err != nil {
+		return err
+	}
+	file, err := os.Create(output)
+	if err != nil {
+		return fmt.Errorf("创建文件失败: %s, 错误: %v", output, err)
+	}
+	defer file.Close()
+	writer := zip.NewWriter(file)
+	defer writer.Close()
+
+	content, err := json.MarshalIndent(manifest, "", "\t")
+	if err != nil {
+		return err
+	}
+	var (
+		now   = time.Now()
+		write = func(name string, content []byte) error {
+			w, err := writer.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: now})
+			if err != nil {
+				return err
+			}
+			_, err = w.Write(content)
+			return err
+		}
+	)
+	if err = write(addonManifestFile, content); err != nil {
+		return err
+	}
+	for _, rel := range files {
+		if err = write(name+"/"+rel, gfile.GetBytes(filepath.Join(dir, filepath.FromSlash(rel)))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// go.mod 中 require 的 module 和版本
+func goModRequires(content string) map[string]string {
+	var (
+		requires = make(map[string]string)
+		inBlock  bool
+	)
+	for _, line := range strings.Split(content, "\n") {
+		line, _, _ = strings.Cut(line, "//")
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "require (":
+			inBlock = true
+			continue
+		case line == ")":
+			inBlock = false
+			continue
+		case !inBlock && !strings.HasPrefix(line, "require "):
+			continue
+		}
+		if match := goModRequirePattern.FindStringSubmatch(line); match != nil {
+			requires[match[1]] = match[2]
+		}
+	}
+	return requires
+}
+
+// 安装插件
+func addonInstallFunc(ctx context.Context, parser *gcmd.Parser) (err error) {
+	source := parser.GetArg(3).String()
+	if source == "" {
+		return fmt.Errorf("请提供插件压缩包或 git 地址，例如: dzhgo addon install shop-v1.0.0.zip")
+	}
+	modName, err := getModName()
+	if err != nil {
+		return err
+	}
+	tempDir, err := os.MkdirTemp("", "dzhgo-addon-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tempDir)
+
+	switch {
+	case gfile.IsFile(source):
+		err = unzipAddon(source, tempDir)
+	case isGitSource(source):
+		err = cloneAddon(ctx, source, parser.GetOpt("branch").String(), tempDir)
+	default:
+		err = fmt.Errorf("未找到插件压缩包: %s", source)
+	}
+	if err != nil {
+		return err
+	}
+
+	manifestFile := filepath.Join(tempDir, addonManifestFile)
+	if !gfile.Exists(manifestFile) {
+		return fmt.Errorf("插件中没有 %s，请使用 dzhgo addon pack 打包", addonManifestFile)
+	}
+	manifest := &addonManifest{}
+	if err = gjson.DecodeTo(gfile.GetBytes(manifestFile), manifest); err != nil {
+		return fmt.Errorf("读取 %s 失败: %v", addonManifestFile, err)
+	}
+	if !addonNamePattern.MatchString(manifest.Name) {
+		return fmt.Errorf("插件名称不正确: %s", manifest.Name)
+	}
+	// 压缩包中插件在同名目录下,git 仓库也可以直接为插件目录
+	srcDir := filepath.Join(tempDir, manifest.Name)
+	if !gfile.IsDir(srcDir) {
+		srcDir = tempDir
+	}
+
+	target := filepath.Join("addons", manifest.Name)
+	if gfile.Exists(target) && parser.GetOpt("force") == nil {
+		return fmt.Errorf("插件已存在: %s，使用 -f 覆盖", target)
+	}
+	if manifest.Dependencies != nil {
+		for _, dep := range manifest.Dependencies.Addons {
+			if !gfile.IsDir(filepath.Join("addons", dep)) {
+				return fmt.Errorf("插件 %s 依赖插件 %s，请先安装", manifest.Name, dep)
+			}
+		}
+	}
+
+	// 先写入 addons 下的临时目录,全部写入后再替换已安装的插件,失败时保留原插件
+	staging := filepath.Join("addons", "."+manifest.Name+".installing")
+	if err = gfile.Remove(staging); err != nil {
+		return err
+	}
+	defer gfile.Remove(staging)
+	// 复制文件,替换打包时项目的导入路径
+	files, err := addonFiles(srcDir)
+	if err != nil {
+		return err
+	}
+	replacer := strings.NewReplacer(`"`+manifest.Module+`/addons/`, `"`+modName+`/addons/`)
+	for _, rel := range files {
+		content := gfile.GetContents(filepath.Join(srcDir, filepath.FromSlash(rel)))
+		if strings.HasSuffix(rel, ".go") && manifest.Module != "" && manifest.Module != modName {
+			content = replacer.Replace(content)
+		}
+		if err = gfile.PutContents(filepath.Join(staging, filepath.FromSlash(rel)), content); err != nil {
+			return fmt.Errorf("写入文件失败: %s, 错误: %v", rel, err)
+		}
+	}
+	manifest.Source = source
+	manifest.Installed = gtime.Now().String()
+	content, err := json.MarshalIndent(manifest, "", "\t")
+	if err != nil {
+		return err
+	}
+	if err = gfile.PutBytes(filepath.Join(staging, addonManifestFile), content); err != nil {
+		return err
+	}
+	if err = replaceAddonDir(staging, target); err != nil {
+		return err
+	}
+	fmt.Printf("已安装插件 %s %s 到 %s\n", manifest.Name, manifest.Version, target)
+
+	// 项目中没有的 go module 按打包时的版本添加
+	if manifest.Dependencies != nil {
+		requires := goModRequires(gfile.GetContents("go.mod"))
+		for _, module := range manifest.Dependencies.Modules {
+			if version, ok := requires[module.Path]; ok {
+				if version != module.Version {
+					fmt.Printf("项目中 %s 的版本为 %s，插件打包时为 %s\n", module.Path, version, module.Version)
+				}
+				continue
+			}
+			if err = runGo(ctx, "get", module.Path+"@"+module.Version); err != nil {
+				return fmt.Errorf("添加依赖失败: %s@%s, 错误: %v", module.Path, module.Version, err)
+			}
+		}
+	}
+
+	if err = wireAddon(modName + "/addons/" + manifest.Name); err != nil {
+		return err
+	}
+	if len(manifest.Migrations) > 0 && parser.GetOpt("skip-migrate") == nil {
+		fmt.Println("执行插件的迁移")
+		if err = runProject(ctx, "migrate", "up"); err != nil {
+			return fmt.Errorf("执行迁移失败: %v，修复后执行 dzhgo migrate up", err)
+		}
+	}
+	if len(manifest.Frontend) > 0 {
+		fmt.Printf("插件包含前端文件 %d 个，位于 %s，请复制到前端项目\n", len(manifest.Frontend), filepath.Join(target, "frontend"))
+	}
+	return nil
+}
+
+func isGitSource(source string) bool {
+	return strings.Contains(source, "://") || strings.HasPrefix(source, "git@") || strings.HasSuffix(source, ".git")
+}
+
+// 解压插件压缩包,不允许解压到目录以外
+func unzipAddon(file, dir string) error {
+	reader, err := zip.OpenReader(file)
+	if err != nil {
+		return fmt.Errorf("读取压缩包失败: %s, 错误: %v", file, err)
+	}
+	defer reader.Close()
+	for _, f := range reader.File {
+		name := path.Clean(f.Name)
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		// 反斜杠在 windows 下为路径分隔符,可能跳出目录
+		if strings.Contains(f.Name, `\`) || !filepath.IsLocal(filepath.FromSlash(name)) {
+			return fmt.Errorf("压缩包中的路径不正确: %s", f.Name)
+		}
+		r, err := f.Open()
+		if err != nil {
+			return err
+		}
+		content, err := io.ReadAll(r)
+		r.Close()
+		if err != nil {
+			return err
+		}
+		if err = gfile.PutBytes(filepath.Join(dir, filepath.FromSlash(name)), content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 克隆 git 仓库
+func cloneAddon(ctx context.Context, source, branch, dir string) error {
+	args := []string{"clone", "--depth", "1"}
+	if branch != "" {
+		args = append(args, "--branch", branch)
+	}
+	command := exec.CommandContext(ctx, "git", append(args, source, dir)...)
+	command.Stdout = os.Stdout
+	command.Stderr = os.Stderr
+	if err := command.Run(); err != nil {
+		return fmt.Errorf("克隆仓库失败: %s, 错误: %v", source, err)
+	}
+	return gfile.Remove(filepath.Join(dir, ".git"))
+}
+
+// 在当前项目中执行 go 命令
+func runGo(ctx context.Context, args ...string) error {
+	command := exec.CommandContext(ctx, "go", args...)
+	command.Stdout = os.Stdout
+	command.Stderr = os.Stderr
+	return command.Run()
+}
+
+// 列出插件
+func addonListFunc(ctx context.Context, parser *gcmd.Parser) (err error) {
+	modName, err := getModName()
+	if err != nil {
+		return err
+	}
+	names, err := addonNames()
+	if err != nil {
+		return err
+	}
+	if len(names) == 0 {
+		fmt.Println("项目中没有插件")
+		return nil
+	}
+	addonsContent := gfile.GetContents("addons/addons.go")
+	fmt.Printf("%-16s %-10s %-6s %-6s %-8s %s\n", "插件", "版本", "导入", "迁移", "种子数据", "来源")
+	for _, name := range names {
+		dir := filepath.Join("addons", name)
+		manifest, err := readAddonManifest(dir, name, modName)
+		if err != nil {
+			return err
+		}
+		wired := "否"
+		if strings.Contains(addonsContent, strconv.Quote(modName+"/addons/"+name)) {
+			wired = "是"
+		}
+		source := "本地"
+		installed := &addonManifest{}
+		if file := filepath.Join(dir, addonManifestFile); gfile.Exists(file) && gjson.DecodeTo(gfile.GetBytes(file), installed) == nil && installed.Source != "" {
+			source = installed.Source
+		}
+		fmt.Printf("%-16s %-10s %-6s %-6d %-8d %s\n", name, manifest.Version, wired, len(manifest.Migrations), len(manifest.Seeds), source)
+	}
+	return nil
+}
+
+// 用 staging 目录替换 target,替换失败时恢复原来的 target
+func replaceAddonDir(staging, target string) error {
+	if !gfile.Exists(target) {
+		return os.Rename(staging, target)
+	}
+	backup := filepath.Join(filepath.Dir(target), "."+filepath.Base(target)+".old")
+	if err := gfile.Remove(backup); err != nil {
+		return err
+	}
+	if err := os.Rename(target, backup); err != nil {
+		return err
+	}
+	if err := os.Rename(staging, target); err != nil {
+		if e := os.Rename(backup, target); e != nil {
+			return fmt.Errorf("替换插件失败: %v，原插件保留在 %s", err, backup)
+		}
+		return err
+	}
+	return gfile.Remove(backup)
+}
+
+// addons 下的插件目录
+func addonNames() (names []string, err error) {
+	if !gfile.IsDir("addons") {
+		return nil, nil
+	}
+	entries, err := os.ReadDir("addons")
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() && !strings.HasPrefix(entry.Name(), ".") {
+			names = append(names, entry.Name())
+		}
+	}
+	return
+}
+
+// 删除插件
+func addonRemoveFunc(ctx context.Context, parser *gcmd.Parser) (err error) {
+	name := parser.GetArg(3).String()
+	if name == "" {
+		return fmt.Errorf("请提供插件名称，例如: dzhgo addon remove shop")
+	}
+	dir := filepath.Join("addons", name)
+	if !gfile.IsDir(dir) {
+		return fmt.Errorf("插件不存在: %s", dir)
+	}
+	modName, err := getModName()
+	if err != nil {
+		return err
+	}
+	// 检查其他插件的依赖
+	names, err := addonNames()
+	if err != nil {
+		return err
+	}
+	var dependents []string
+	for _, other := range names {
+		if other == name {
+			continue
+		}
+		manifest, err := readAddonManifest(filepath.Join("addons", other), other, modName)
+		if err != nil {
+			return err
+		}
+		for _, dep := range manifest.Dependencies.Addons {
+			if dep == name {
+				dependents = append(dependents, other)
+			}
+		}
+	}
+	if len(dependents) > 0 && parser.GetOpt("force") == nil {
+		return fmt.Errorf("插件 %s 被 %s 依赖，使用 -f 强制删除", name, strings.Join(dependents, ", "))
+	}
+
+	if err = unwireAddon(modName + "/addons/" + name); err != nil {
+		return err
+	}
+	if err = gfile.Remove(dir); err != nil {
+		return fmt.Errorf("删除目录失败: %s, 错误: %v", dir, err)
+	}
+	fmt.Printf("已删除插件 %s\n", dir)
+	fmt.Println("插件的数据表和迁移记录未删除，如需回滚请在删除前执行 dzhgo migrate down")
+	return nil
+}
+
+// 从项目的 addons/addons.go 中取消导入插件
+func unwireAddon(importPrefix string) error {
+	const addonsFile = "addons/addons.go"
+	if !gfile.Exists(addonsFile) {
+		return nil
+	}
+	var (
+		quoted = strconv.Quote(importPrefix)
+		lines  = strings.Split(gfile.GetContents(addonsFile), "\n")
+		kept   = make([]string, 0, len(lines))
+	)
+	for _, line := range lines {
+		if strings.Contains(line, quoted) {
+			continue
+		}
+		kept = append(kept, line)
+	}
+	if len(kept) == len(lines) {
+		return nil
+	}
+	// 没有导入的插件时删除空的 import
+	content := emptyImportPattern.ReplaceAllString(strings.Join(kept, "\n"), "\n")
+	return putGenFile("插件导入", addonsFile, content, true)
+}
+
+func init() {
+	if err := Addon.AddCommand(addonPack, addonInstall, addonList, addonRemove); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,262 @@
+package cmd
+
+import (
+	"archive/zip"
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gogf/gf/v2/encoding/gjson"
+)
+
+const addonGoMod = `module example.com/app
+
+go 1.24
+
+require (
+	github.com/gogf/gf/v2 v2.9.0
+	github.com/gzdzh-cn/dzhcore v1.0.0 // indirect
+)
+
+require github.com/google/uuid v1.6.0
+`
+
+// 写入项目文件 key:相对路径
+func writeFiles(t *testing.T, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// 在临时项目中创建 base 和依赖 base 的 shop 插件
+func chdirAddonProject(t *testing.T, module string) string {
+	t.Helper()
+	dir := chdirProject(t)
+	writeFiles(t, map[string]string{
+		"go.mod":                                        strings.Replace(addonGoMod, "example.com/app", module, 1),
+		"addons/addons.go":                              "package addons\n\nimport (\n\t_ \"" + module + "/addons/base\"\n)\n",
+		"addons/base/config.go":                         "package base\n\nconst Version = \"v0.1.0\"\n",
+		"addons/shop/config.go":                         "package shop\n\nimport (\n\t_ \"" + module + "/addons/base/model\"\n\t_ \"github.com/gogf/gf/v2/frame/g\"\n\t_ \"github.com/gzdzh-cn/dzhcore\"\n)\n\nconst Version = \"v1.2.0\"\n",
+		"addons/shop/migration/migration.go":            "package migration\n",
+		"addons/shop/migration/m20240101_init.go":       "package migration\n",
+		"addons/shop/resource/initjson/shop_goods.json": "[]\n",
+		"addons/shop/frontend/views/goods.vue":          "<template></template>\n",
+		"addons/shop/.git/HEAD":                         "ref: refs/heads/main\n",
+		"addons/shop/manifest.json":                     "{}\n",
+	})
+	return dir
+}
+
+func runAddon(t *testing.T, args ...string) error {
+	t.Helper()
+	_, err := Root.RunWithSpecificArgs(context.Background(), append([]string{"dzhgo", "addon"}, args...))
+	return err
+}
+
+// 插件清单的版本、文件分类和依赖
+func TestReadAddonManifest(t *testing.T) {
+	chdirAddonProject(t, "example.com/app")
+	manifest, err := readAddonManifest(filepath.Join("addons", "shop"), "shop", "example.com/app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if manifest.Version != "v1.2.0" || manifest.Module != "example.com/app" {
+		t.Fatalf("版本 %s, module %s", manifest.Version, manifest.Module)
+	}
+	checks := map[string][2][]string{
+		"migrations": {manifest.Migrations, {"migration/m20240101_init.go"}},
+		"seeds":      {manifest.Seeds, {"resource/initjson/shop_goods.json"}},
+		"frontend":   {manifest.Frontend, {"frontend/views/goods.vue"}},
+		"addons":     {manifest.Dependencies.Addons, {"base"}},
+	}
+	for name, check := range checks {
+		if !reflect.DeepEqual(check[0], check[1]) {
+			t.Errorf("%s %v, 期望 %v", name, check[0], check[1])
+		}
+	}
+	var modules []string
+	for _, module := range manifest.Dependencies.Modules {
+		modules = append(modules, module.Path+"@"+module.Version)
+	}
+	if want := []string{"github.com/gogf/gf/v2@v2.9.0", "github.com/gzdzh-cn/dzhcore@v1.0.0"}; !reflect.DeepEqual(modules, want) {
+		t.Errorf("modules %v, 期望 %v", modules, want)
+	}
+
+	files, err := addonFiles(filepath.Join("addons", "shop"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range files {
+		if strings.HasPrefix(file, ".git/") || file == addonManifestFile {
+			t.Errorf("打包了 %s", file)
+		}
+	}
+}
+
+func TestGoModRequires(t *testing.T) {
+	want := map[string]string{
+		"github.com/gogf/gf/v2":       "v2.9.0",
+		"github.com/gzdzh-cn/dzhcore": "v1.0.0",
+		"github.com/google/uuid":      "v1.6.0",
+	}
+	if got := goModRequires(addonGoMod); !reflect.DeepEqual(got, want) {
+		t.Fatalf("requires %v, 期望 %v", got, want)
+	}
+}
+
+// 写入自定义路径的压缩包
+func writeZip(t *testing.T, file string, names ...string) {
+	t.Helper()
+	f, err := os.Create(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	writer := zip.NewWriter(f)
+	for _, name := range names {
+		w, err := writer.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = w.Write([]byte("package x\n")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// 不允许解压到目录以外
+func TestUnzipAddonPath(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"../evil.go", "shop/../../evil.go", "/evil.go", `..\evil.go`, `shop\..\..\evil.go`} {
+		file := filepath.Join(dir, "bad.zip")
+		writeZip(t, file, "manifest.json", name)
+		target := filepath.Join(dir, "bad")
+		if err := unzipAddon(file, target); err == nil || !strings.Contains(err.Error(), "路径不正确") {
+			t.Errorf("%s 错误 %v", name, err)
+		}
+		if _, err := os.Stat(filepath.Join(dir, "evil.go")); err == nil {
+			t.Fatalf("%s 解压到了目录以外", name)
+		}
+	}
+
+	file := filepath.Join(dir, "ok.zip")
+	writeZip(t, file, "manifest.json", "shop/./config.go", "shop/model/../model/goods.go")
+	target := filepath.Join(dir, "ok")
+	if err := unzipAddon(file, target); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"manifest.json", "shop/config.go", "shop/model/goods.go"} {
+		if _, err := os.Stat(filepath.Join(target, name)); err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+// 打包后安装到其他项目,替换导入路径并检查依赖的插件
+func TestAddonPackInstall(t *testing.T) {
+	chdirAddonProject(t, "example.com/app")
+	output := filepath.Join(t.TempDir(), "shop.zip")
+	if err := runAddon(t, "pack", "shop", "-o", output); err != nil {
+		t.Fatal(err)
+	}
+	packed := t.TempDir()
+	if err := unzipAddon(output, packed); err != nil {
+		t.Fatal(err)
+	}
+	if got := readTree(t, packed); got["shop/.git/HEAD"] != "" || got["shop/config.go"] == "" {
+		t.Fatalf("压缩包中的文件 %v", got)
+	}
+
+	chdirAddonProject(t, "example.com/other")
+	if err := os.RemoveAll("addons/shop"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.RemoveAll("addons/base"); err != nil {
+		t.Fatal(err)
+	}
+	if err := runAddon(t, "install", output, "--skip-migrate"); err == nil || !strings.Contains(err.Error(), "依赖插件 base") {
+		t.Fatalf("缺少依赖时错误 %v", err)
+	}
+	if _, err := os.Stat("addons/shop"); !os.IsNotExist(err) {
+		t.Fatalf("缺少依赖时安装了插件: %v", err)
+	}
+
+	writeFiles(t, map[string]string{"addons/base/config.go": "package base\n"})
+	if err := runAddon(t, "install", output, "--skip-migrate"); err != nil {
+		t.Fatal(err)
+	}
+	config, _ := os.ReadFile("addons/shop/config.go")
+	if !strings.Contains(string(config), `"example.com/other/addons/base/model"`) {
+		t.Errorf("导入路径未替换:\n%s", config)
+	}
+	manifest := &addonManifest{}
+	if err := gjson.DecodeTo(readTree(t, "addons/shop")[addonManifestFile], manifest); err != nil {
+		t.Fatal(err)
+	}
+	if manifest.Source != output || manifest.Installed == "" || manifest.Module != "example.com/app" {
+		t.Errorf("安装记录 %+v", manifest)
+	}
+	if addons, _ := os.ReadFile("addons/addons.go"); !strings.Contains(string(addons), `"example.com/other/addons/shop"`) {
+		t.Errorf("未导入插件:\n%s", addons)
+	}
+
+	if err := runAddon(t, "install", output, "--skip-migrate"); err == nil || !strings.Contains(err.Error(), "插件已存在") {
+		t.Fatalf("插件已存在时错误 %v", err)
+	}
+	if err := runAddon(t, "install", output, "--skip-migrate", "-f"); err != nil {
+		t.Fatal(err)
+	}
+	if names, _ := addonNames(); !reflect.DeepEqual(names, []string{"base", "shop"}) {
+		t.Errorf("覆盖安装后的插件 %v", names)
+	}
+}
+
+// 被其他插件依赖时不删除,删除后取消导入
+func TestAddonRemove(t *testing.T) {
+	chdirAddonProject(t, "example.com/app")
+	writeFiles(t, map[string]string{
+		"addons/addons.go": "package addons\n\nimport (\n\t_ \"example.com/app/addons/base\"\n\t_ \"example.com/app/addons/shop\"\n)\n",
+	})
+	if err := runAddon(t, "remove", "base"); err == nil || !strings.Contains(err.Error(), "被 shop 依赖") {
+		t.Fatalf("被依赖时错误 %v", err)
+	}
+	if _, err := os.Stat("addons/base"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := runAddon(t, "remove", "shop"); err != nil {
+		t.Fatal(err)
+	}
+	if err := runAddon(t, "remove", "base"); err != nil {
+		t.Fatal(err)
+	}
+	if names, _ := addonNames(); len(names) != 0 {
+		t.Errorf("删除后的插件 %v", names)
+	}
+	if addons, _ := os.ReadFile("addons/addons.go"); string(addons) != "package addons\n" {
+		t.Errorf("取消导入后:\n%s", addons)
+	}
+}
+
+// -f 时删除被依赖的插件
+func TestAddonRemoveForce(t *testing.T) {
+	chdirAddonProject(t, "example.com/app")
+	if err := runAddon(t, "remove", "base", "-f"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat("addons/base"); !os.IsNotExist(err) {
+		t.Fatalf("未删除插件: %v", err)
+	}
+}
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/gogf/gf/v2/os/gcmd"
+)
+
+var (
+	// ConfigCmd 配置命令
+	ConfigCmd = &gcmd.Command{
+		Name:  "config",
+		Usage: "config show|schema",
+		Brief: "查看生效的配置和来源，生成配置文件的 JSON Schema",
+	}
+
+	configShow = &gcmd.Command{
+		Name:  "show",
+		Usage: "config show [-k 前缀] [-o 文件]",
+		Brief: "列出生效的配置和来源（配置文件、环境变量、.env、默认值），密钥类的配置项隐藏",
+		Arguments: []gcmd.Argument{
+			{Name: "key", Short: "k", Brief: "只列出该前缀的配置项，如 core.file"},
+			{Name: "output", Short: "o", Brief: "同时以 json 写入文件"},
+		},
+		Func: func(ctx context.Context, parser *gcmd.Parser) (err error) {
+			return proxyProject(ctx)
+		},
+	}
+
+	configSchema = &gcmd.Command{
+		Name:  "schema",
+		Usage: "config schema [-o 文件]",
+		Brief: "按 defineStruct.Config 生成配置文件的 JSON Schema，供编辑器校验和提示",
+		Arguments: []gcmd.Argument{
+			{Name: "output", Short: "o", Brief: "写入的文件，默认 manifest/config/config.schema.json"},
+		},
+		Func: func(ctx context.Context, parser *gcmd.Parser) (err error) {
+			return proxyProject(ctx)
+		},
+	}
+)
+
+func init() {
+	if err := ConfigCmd.AddCommand(configShow, configSchema); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/gogf/gf/v2/os/gcmd"
+	"github.com/gogf/gf/v2/os/gfile"
+	"github.com/gzdzh-cn/dzhcore/dzhgo/config"
+)
+
+var (
+	// Doctor 检查配置和运行环境命令
+	Doctor = &gcmd.Command{
+		Name:  "doctor",
+		Usage: "doctor [-s] [-o 文件]",
+		Brief: "检查 Go 环境、项目依赖，并在项目中检查配置、数据库、redis、文件上传和目录权限",
+		Func:  doctorFunc,
+		Arguments: []gcmd.Argument{
+			{Name: "strict", Short: "s", Brief: "存在警告时也返回错误，用于 CI", Orphan: true},
+			{Name: "output", Short: "o", Brief: "同时以 json 写入文件，供 CI 读取"},
+		},
+	}
+)
+
+var dzhcoreRequirePattern = regexp.MustCompile(`(?m)^\s*(?:require\s+)?github\.com/gzdzh-cn/dzhcore\s+(\S+)`)
+
+// 检查本地环境后在项目中执行 doctor
+func doctorFunc(ctx context.Context, parser *gcmd.Parser) (err error) {
+	var failed bool
+	report := func(ok bool, name, message, hint string) {
+		status := "[通过]"
+		if !ok {
+			status, failed = "[失败]", true
+		}
+		fmt.Printf("%s %s: %s\n", status, name, message)
+		if !ok && hint != "" {
+			fmt.Printf("       建议: %s\n", hint)
+		}
+	}
+
+	// Go 环境
+	if output, err := exec.CommandContext(ctx, "go", "env", "GOVERSION").Output(); err != nil {
+		report(false, "Go 环境", "未找到 go 命令", fmt.Sprintf("安装 Go %s 或以上版本并添加到 PATH", config.GoVersion))
+	} else {
+		report(true, "Go 环境", strings.TrimSpace(string(output)), "")
+	}
+
+	// 项目依赖
+	if !gfile.Exists("go.mod") {
+		report(false, "项目", "当前目录没有 go.mod", "在项目根目录执行，或使用 dzhgo init 创建项目")
+		os.Exit(1)
+	}
+	if match := dzhcoreRequirePattern.FindStringSubmatch(gfile.GetContents("go.mod")); match == nil {
+		report(false, "dzhcore", "go.mod 中没有依赖 github.com/gzdzh-cn/dzhcore", "执行 go get github.com/gzdzh-cn/dzhcore@"+config.Version)
+	} else {
+		report(true, "dzhcore", match[1], "")
+	}
+	if !projectMountsCommands() {
+		report(false, "命令", "项目中没有调用 corecmd.AddCommands", "在项目主命令的 init 中调用 corecmd.AddCommands(&Main)")
+	} else {
+		report(true, "命令", "已挂载 dzhcore 命令", "")
+	}
+	if failed {
+		os.Exit(1)
+	}
+
+	// 在项目中检查配置和运行环境,存在失败项时退出码为 1
+	if err = proxyProject(ctx); err != nil {
+		os.Exit(1)
+	}
+	return nil
+}
+
+// 项目的 go 文件中是否调用了 corecmd.AddCommands
+func projectMountsCommands() bool {
+	files, _ := gfile.ScanDirFile(".", "*.go", true)
+	for _, file := range files {
+		if strings.Contains(filepath.ToSlash(file), "/vendor/") {
+			continue
+		}
+		if strings.Contains(gfile.GetContents(file), "corecmd.AddCommands") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,404 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/gogf/gf/v2/encoding/gjson"
+	"github.com/gogf/gf/v2/net/gclient"
+	"github.com/gogf/gf/v2/os/gcmd"
+	"github.com/gogf/gf/v2/os/gfile"
+	"github.com/gogf/gf/v2/os/gtime"
+	"github.com/gogf/gf/v2/text/gstr"
+)
+
+var (
+	// Eps 生成前端接口类型命令
+	Eps = &gcmd.Command{
+		Name:  "eps",
+		Usage: "eps [-o 目录] [-u 接口地址 | -i 文件]",
+		Brief: "根据控制器开启的接口和模型的列生成 TypeScript 类型和请求方法",
+		Func:  epsFunc,
+		Arguments: []gcmd.Argument{
+			{Name: "output", Short: "o", Brief: "生成目录，默认 eps"},
+			{Name: "url", Short: "u", Brief: "从运行中的服务读取，如 http://127.0.0.1:8000/admin/core/eps"},
+			{Name: "token", Short: "t", Brief: "请求接口时的 Authorization 请求头"},
+			{Name: "input", Short: "i", Brief: "从 go run . eps -o 导出的 json 文件读取"},
+			{Name: "dry-run", Brief: "只打印将要生成的文件和差异，不写入", Orphan: true},
+		},
+	}
+)
+
+// 控制器的接口描述,由项目中的 eps 命令或 /admin/core/eps 接口读取,与 dzhcore.Eps 一致
+type epsInfo struct {
+	Prefix  string       `json:"prefix"`
+	Name    string       `json:"name"`
+	Group   string       `json:"group"`
+	Table   string       `json:"table"`
+	Api     []*epsApi    `json:"api"`
+	Columns []*epsColumn `json:"columns"`
+}
+
+type epsApi struct {
+	Name    string      `json:"name"`
+	Method  string      `json:"method"`
+	Path    string      `json:"path"`
+	Summary string      `json:"summary"`
+	Params  []*epsParam `json:"params"`
+}
+
+type epsParam struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Required bool   `json:"required"`
+	Comment  string `json:"comment"`
+}
+
+type epsColumn struct {
+	Comment      string `json:"comment"`
+	Length       string `json:"length"`
+	Nullable     bool   `json:"nullable"`
+	PropertyName string `json:"propertyName"`
+	Type         string `json:"type"`
+}
+
+// 增删改查接口的请求、返回类型和说明,$M 替换为模型的类型
+var epsCrudApi = map[string][3]string{
+	"Add":         {"Partial<$M>", "{ id: string }", "新增"},
+	"Delete":      {"{ ids: string[] }", "any", "删除"},
+	"Update":      {"Partial<$M> & { id: string }", "any", "修改"},
+	"UpdateBatch": {"{ ids: string[]; data: Partial<$M> }", "{ affected: number }", "批量修改"},
+	"Upsert":      {"{ list: Partial<$M>[] }", "{ affected: number; ids: string[] }", "新增或修改"},
+	"Info":        {"{ id: string }", "$M", "详情"},
+	"List":        {"ListReq & Partial<$M>", "$M[]", "列表"},
+	"Page":        {"PageReq & Partial<$M>", "PageResult<$M>", "分页"},
+	"Options":     {"OptionsReq", "PageResult<OptionItem>", "下拉选项"},
+}
+
+const epsHeader = "// 由 dzhgo eps 生成，请勿修改\n"
+
+// 公共类型和请求方法的定义
+const epsBase = epsHeader + `
+/** 接口返回 */
+export interface BaseRes<T = any> {
+  code: number
+  message: string
+  data: T
+}
+
+/** 分页信息 */
+export interface Pagination {
+  page: number
+  size: number
+  total: number
+}
+
+/** 分页结果 */
+export interface PageResult<T> {
+  list: T[]
+  pagination: Pagination
+}
+
+/** 下拉选项 */
+export interface OptionItem {
+  value: any
+  label: string
+  [key: string]: any
+}
+
+/** 列表查询 */
+export interface ListReq {
+  keyWord?: string
+  order?: string
+  sort?: 'asc' | 'desc'
+}
+
+/** 分页查询 */
+export interface PageReq extends ListReq {
+  page?: number
+  size?: number
+  isExport?: boolean
+  maxExportLimit?: number
+}
+
+/** 下拉选项查询 */
+export interface OptionsReq {
+  keyWord?: string
+  page?: number
+  size?: number
+  ids?: string
+  distinct?: string
+}
+
+/** 请求方法，由项目实现，如使用 axios 发送请求并返回 BaseRes 的 data */
+export type Request = <T = any>(options: { url: string; method: string; data?: any; params?: any }) => Promise<T>
+`
+
+// 生成前端接口类型执行函数
+func epsFunc(ctx context.Context, parser *gcmd.Parser) (err error) {
+	genOptions.DryRun = parser.GetOpt("dry-run") != nil
+	var (
+		output = parser.GetOpt("output", "eps").String()
+		list   []*epsInfo
+	)
+	if list, err = loadEps(ctx, parser.GetOpt("url").String(), parser.GetOpt("token").String(), parser.GetOpt("input").String()); err != nil {
+		return err
+	}
+	if len(list) == 0 {
+		fmt.Println("没有注册任何控制器")
+		return nil
+	}
+	return generateEps(output, list)
+}
+
+// 生成 base.ts、每个路由前缀的类型和请求方法,以及汇总的 index.ts
+func generateEps(output string, list []*epsInfo) (err error) {
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Prefix < list[j].Prefix
+	})
+	if err = mkdirGen(output); err != nil {
+		return err
+	}
+	if err = putGenFile("类型", filepath.Join(output, "base.ts"), epsBase, true); err != nil {
+		return err
+	}
+
+	var (
+		imports []string
+		clients []string
+	)
+	for _, info := range list {
+		if len(info.Api) == 0 {
+			continue
+		}
+		file := strings.Trim(info.Prefix, "/")
+		if err = mkdirGen(filepath.Join(output, filepath.Dir(file))); err != nil {
+			return err
+		}
+		if err = putGenFile("接口", filepath.Join(output, file+".ts"), epsModule(info), true); err != nil {
+			return err
+		}
+		name := epsClientName(info.Prefix)
+		imports = append(imports, fmt.Sprintf("import { create%sApi } from './%s'\n", gstr.UcFirst(name), file))
+		clients = append(clients, fmt.Sprintf("    %s: create%sApi(request),\n", name, gstr.UcFirst(name)))
+	}
+
+	var buf strings.Builder
+	buf.WriteString(epsHeader + "\nimport type { Request } from './base'\n")
+	for _, item := range imports {
+		buf.WriteString(item)
+	}
+	buf.WriteString("\nexport * from './base'\n\n/** 创建全部接口 */\nexport function createApi(request: Request) {\n  return {\n")
+	for _, item := range clients {
+		buf.WriteString(item)
+	}
+	buf.WriteString("  }\n}\n")
+	return putGenFile("接口", filepath.Join(output, "index.ts"), buf.String(), true)
+}
+
+// 读取接口描述,没有指定接口地址和文件时在项目中执行 eps 命令
+func loadEps(ctx context.Context, url, token, input string) (list []*epsInfo, err error) {
+	var content []byte
+	switch {
+	case url != "":
+		client := gclient.New()
+		if token != "" {
+			client.SetHeader("Authorization", token)
+		}
+		res, err := client.Get(ctx, url)
+		if err != nil {
+			return nil, fmt.Errorf("请求 %s 失败: %v", url, err)
+		}
+		defer res.Close()
+		if res.StatusCode != 200 {
+			return nil, fmt.Errorf("请求 %s 失败，状态码: %d", url, res.StatusCode)
+		}
+		j, err := gjson.DecodeToJson(res.ReadAll())
+		if err != nil {
+			return nil, fmt.Errorf("解析接口描述失败: %v", err)
+		}
+		if code := j.Get("code").Int(); code != 1000 {
+			return nil, fmt.Errorf("读取接口描述失败: %s", j.Get("message").String())
+		}
+		content = []byte(j.Get("data").String())
+	case input != "":
+		if !gfile.Exists(input) {
+			return nil, fmt.Errorf("文件不存在: %s", input)
+		}
+		content = gfile.GetBytes(input)
+	default:
+		output := gfile.Temp(fmt.Sprintf("dzhgo-eps-%d.json", gtime.TimestampNano()))
+		defer gfile.Remove(output)
+		if err = runProject(ctx, "eps", "-o", output); err != nil {
+			return nil, fmt.Errorf("读取接口描述失败，项目主命令需要通过 corecmd.AddCommands 挂载 dzhcore 命令: %v", err)
+		}
+		content = gfile.GetBytes(output)
+	}
+	if err = gjson.DecodeTo(content, &list); err != nil {
+		return nil, fmt.Errorf("解析接口描述失败: %v", err)
+	}
+	return list, nil
+}
+
+// 一个路由前缀的类型和请求方法
+func epsModule(info *epsInfo) string {
+	var (
+		buf      strings.Builder
+		model    = gstr.CaseCamel(info.Name)
+		name     = epsClientName(info.Prefix)
+		depth    = strings.Count(strings.Trim(info.Prefix, "/"), "/")
+		base     = strings.Repeat("../", depth) + "base"
+		types    = []string{"Request"}
+		hasModel = len(info.Columns) > 0
+	)
+	if depth == 0 {
+		base = "./base"
+	}
+	for _, api := range info.Api {
+		if crud, ok := epsCrudApi[api.Name]; ok && hasModel {
+			for _, t := range []string{"ListReq", "PageReq", "OptionsReq", "PageResult", "OptionItem"} {
+				if strings.Contains(crud[0]+crud[1], t) && !containsFold(types, t) {
+					types = append(types, t)
+				}
+			}
+		}
+	}
+	sort.Strings(types[1:])
+
+	buf.WriteString(epsHeader + "\n")
+	fmt.Fprintf(&buf, "import type { %s } from '%s'\n", strings.Join(types, ", "), base)
+
+	// 模型的列
+	if hasModel {
+		fmt.Fprintf(&buf, "\n/** %s 表 %s */\nexport interface %s {\n", model, info.Table, model)
+		for _, column := range info.Columns {
+			tsType := epsColumnType(column.Type)
+			if column.Nullable {
+				tsType += " | null"
+			}
+			if column.Comment != "" && column.Comment != column.PropertyName {
+				fmt.Fprintf(&buf, "  /** %s */\n", column.Comment)
+			}
+			fmt.Fprintf(&buf, "  %s: %s\n", epsPropertyName(column.PropertyName), tsType)
+		}
+		buf.WriteString("}\n")
+	}
+
+	// 其他接口的请求参数
+	for _, api := range info.Api {
+		if _, ok := epsCrudApi[api.Name]; ok || len(api.Params) == 0 {
+			continue
+		}
+		fmt.Fprintf(&buf, "\nexport interface %s%sReq {\n", model, api.Name)
+		for _, param := range api.Params {
+			if param.Comment != "" {
+				fmt.Fprintf(&buf, "  /** %s */\n", param.Comment)
+			}
+			optional := "?"
+			if param.Required {
+				optional = ""
+			}
+			fmt.Fprintf(&buf, "  %s%s: %s\n", epsPropertyName(param.Name), optional, epsGoType(param.Type))
+		}
+		buf.WriteString("}\n")
+	}
+
+	fmt.Fprintf(&buf, "\n/** %s 的接口 */\nexport function create%sApi(request: Request) {\n  return {\n", info.Prefix, gstr.UcFirst(name))
+	for _, api := range info.Api {
+		var (
+			reqType = "any"
+			resType = "any"
+			summary = api.Summary
+		)
+		if crud, ok := epsCrudApi[api.Name]; ok && hasModel {
+			reqType = strings.ReplaceAll(crud[0], "$M", model)
+			resType = strings.ReplaceAll(crud[1], "$M", model)
+			if summary == "" {
+				summary = crud[2]
+			}
+		} else if len(api.Params) > 0 {
+			reqType = model + api.Name + "Req"
+		}
+		var (
+			field = "data"
+			arg   string
+		)
+		if api.Method == "GET" {
+			field = "params"
+		}
+		// 没有请求参数时不传
+		if reqType != "any" {
+			arg = fmt.Sprintf("%s: %s", field, reqType)
+			field = ", " + field
+		} else {
+			field = ""
+		}
+		if summary != "" {
+			fmt.Fprintf(&buf, "    /** %s */\n", summary)
+		}
+		fmt.Fprintf(&buf, "    %s: (%s) => request<%s>({ url: '%s', method: '%s'%s }),\n",
+			gstr.LcFirst(api.Name), arg, resType, api.Path, epsMethod(api.Method), field)
+	}
+	buf.WriteString("  }\n}\n")
+	return buf.String()
+}
+
+// 路由前缀转换为请求方法的名称,如 /admin/base/sys/user 为 adminBaseSysUser
+func epsClientName(prefix string) string {
+	return gstr.CaseCamelLower(strings.NewReplacer("/", "_", "-", "_").Replace(strings.Trim(prefix, "/")))
+}
+
+// 请求方式,ALL 或为空时使用 POST
+func epsMethod(method string) string {
+	switch method {
+	case "", "ALL":
+		return "POST"
+	}
+	return method
+}
+
+// 属性名不是合法的标识符时加引号
+func epsPropertyName(name string) string {
+	if fieldNamePattern.MatchString(name) {
+		return name
+	}
+	return fmt.Sprintf("'%s'", name)
+}
+
+// 列类型转换为 TypeScript 类型
+func epsColumnType(columnType string) string {
+	t := strings.ToLower(strings.Fields(columnType + " ")[0])
+	switch t {
+	case "int", "integer", "tinyint", "smallint", "mediumint", "bigint", "int2", "int4", "int8",
+		"decimal", "numeric", "float", "float4", "float8", "double", "real", "serial", "bigserial":
+		return "number"
+	case "bool", "boolean":
+		return "boolean"
+	case "json", "jsonb":
+		return "any"
+	}
+	return "string"
+}
+
+// Go 类型转换为 TypeScript 类型
+func epsGoType(goType string) string {
+	goType = strings.TrimPrefix(goType, "*")
+	switch {
+	case strings.HasPrefix(goType, "[]"):
+		return epsGoType(goType[2:]) + "[]"
+	case strings.HasPrefix(goType, "map["):
+		return "Record<string, any>"
+	}
+	switch goType {
+	case "string", "time.Time", "gtime.Time":
+		return "string"
+	case "bool":
+		return "boolean"
+	case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64", "float32", "float64":
+		return "number"
+	}
+	return "any"
+}
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// 按 testdata/eps.json 生成的类型和请求方法与 testdata/eps 一致
+func TestGenerateEpsGolden(t *testing.T) {
+	golden, err := filepath.Abs("testdata/eps")
+	if err != nil {
+		t.Fatal(err)
+	}
+	list, err := loadEps(context.Background(), "", "", "testdata/eps.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	if err = generateEps(dir, list); err != nil {
+		t.Fatal(err)
+	}
+	checkGolden(t, golden, readTree(t, dir))
+}
+
+// 从运行中的服务读取时带上 Authorization,返回码不是 1000 时报错
+func TestLoadEpsUrl(t *testing.T) {
+	content, err := os.ReadFile("testdata/eps.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "token" {
+			w.Write([]byte(`{"code":1001,"message":"登录失效"}`))
+			return
+		}
+		w.Write([]byte(`{"code":1000,"message":"success","data":` + string(content) + `}`))
+	}))
+	defer server.Close()
+
+	list, err := loadEps(context.Background(), server.URL, "token", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 3 || list[0].Prefix != "/admin/shop/order" || len(list[0].Columns) != 5 {
+		t.Fatalf("读取 %d 个控制器, 期望 3", len(list))
+	}
+	if _, err = loadEps(context.Background(), server.URL, "", ""); err == nil {
+		t.Fatal("返回码不是 1000 时应失败")
+	}
+}
+
+func TestEpsTypes(t *testing.T) {
+	for column, want := range map[string]string{
+		"int": "number", "bigint unsigned": "number", "DECIMAL": "number", "boolean": "boolean",
+		"jsonb": "any", "varchar": "string", "datetime": "string",
+	} {
+		if got := epsColumnType(column); got != want {
+			t.Errorf("列类型 %s 为 %s, 期望 %s", column, got, want)
+		}
+	}
+	for goType, want := range map[string]string{
+		"*string": "string", "[]int": "number[]", "map[string]interface {}": "Record<string, any>",
+		"*gtime.Time": "string", "bool": "boolean", "struct {}": "any",
+	} {
+		if got := epsGoType(goType); got != want {
+			t.Errorf("Go 类型 %s 为 %s, 期望 %s", goType, got, want)
+		}
+	}
+	if got := epsClientName("/admin/base/sys-user/"); got != "adminBaseSysUser" {
+		t.Errorf("epsClientName %s, 期望 adminBaseSysUser", got)
+	}
+}
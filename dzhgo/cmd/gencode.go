@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/gogf/gf/v2/container/garray"
 	"github.com/gogf/gf/v2/os/gcmd"
 	"github.com/gogf/gf/v2/os/gfile"
 	"github.com/gogf/gf/v2/text/gstr"
@@ -108,6 +109,42 @@ var (
 				Short: "L",
 				Brief: "单独生成逻辑，例如: user (可与 addons 配合使用)",
 			},
+			{
+				Name:  "fields",
+				Short: "f",
+				Brief: "字段定义，名称:类型[:选项...]，多个用逗号分隔，例如: \"name:string:required:unique,price:decimal(10,2),status:int:dict=order_status\" (与 model、logic 配合使用)",
+			},
+			{
+				Name:  "from-table",
+				Short: "t",
+				Brief: "根据数据库中已有的表生成模型、控制器和逻辑，多个用逗号分隔，例如: base_sys_user (可与 addons 配合使用)",
+			},
+			{
+				Name:   "all-tables",
+				Short:  "A",
+				Brief:  "根据数据库中全部业务表生成，不包括 dzhcore 内置表",
+				Orphan: true,
+			},
+			{
+				Name:   "dry-run",
+				Brief:  "只打印将要生成的文件和与已有文件的差异，不写入",
+				Orphan: true,
+			},
+			{
+				Name:   "force",
+				Brief:  "覆盖已存在的文件",
+				Orphan: true,
+			},
+			{
+				Name:   "export-templates",
+				Brief:  "导出默认模板到 .dzhgo/templates，修改后生成代码时优先使用",
+				Orphan: true,
+			},
+			{
+				Name:  "group",
+				Short: "g",
+				Brief: "读取表结构的数据库分组，默认 default (与 from-table、all-tables 配合使用)",
+			},
 		},
 	}
 )
@@ -120,10 +157,38 @@ func genCodeFunc(ctx context.Context, parser *gcmd.Parser) (err error) {
 	model := parser.GetOpt("model").String()           //模型名称
 	controller := parser.GetOpt("controller").String() //控制器名称
 	logic := parser.GetOpt("logic").String()           //逻辑名称
+	fromTable := parser.GetOpt("from-table").String()  //表名
+	allTables := parser.GetOpt("all-tables") != nil    //全部表
+	fields := parser.GetOpt("fields").String()         //字段定义
+
+	genOptions.DryRun = parser.GetOpt("dry-run") != nil
+	genOptions.Force = parser.GetOpt("force") != nil
+	if parser.GetOpt("export-templates") != nil {
+		return exportTemplates()
+	}
+
+	// 根据已有的表生成
+	if fromTable != "" || allTables {
+		if model != "" || controller != "" || logic != "" || fields != "" {
+			return fmt.Errorf("from-table、all-tables 不能与 model、controller、logic、fields 同时使用")
+		}
+		return generateFromTables(ctx, gstr.CaseCamelLower(addons), module, fromTable, allTables, parser.GetOpt("group").String())
+	}
 
 	// 1. 所有参数不能全为空
 	if addons == "" && module == "" && model == "" && controller == "" && logic == "" {
-		return fmt.Errorf("请至少提供一个参数，使用 -a/--addons, -m/--module, -M/--model, -c/--controller, -l/--logic")
+		return fmt.Errorf("请至少提供一个参数，使用 -a/--addons, -m/--module, -M/--model, -c/--controller, -l/--logic, -t/--from-table, -A/--all-tables")
+	}
+
+	// 字段定义
+	var specs []*fieldSpec
+	if fields != "" {
+		if model == "" && logic == "" {
+			return fmt.Errorf("fields 参数需要与 model 或 logic 参数配合使用")
+		}
+		if specs, err = parseFieldSpecs(fields); err != nil {
+			return err
+		}
 	}
 
 	// 2. 有addons时，module可以为空
@@ -135,7 +200,7 @@ func genCodeFunc(ctx context.Context, parser *gcmd.Parser) (err error) {
 		addonsCamel := gstr.CaseCamelLower(addons)
 
 		// 如果module为空，设置为空字符串，在generateAddonCode中会同时生成admin和app
-		return generateAddonCode(addonsCamel, module, modelCamel, controllerCamel, logicCamel)
+		return generateAddonCode(addonsCamel, module, modelCamel, controllerCamel, logicCamel, specs)
 	}
 
 	// 3. 没有addons时，不能使用name和module
@@ -153,23 +218,26 @@ func genCodeFunc(ctx context.Context, parser *gcmd.Parser) (err error) {
 	controllerCamel := gstr.CaseCamelLower(controller)
 	logicCamel := gstr.CaseCamelLower(logic)
 	addonsCamel := gstr.CaseCamelLower(addons)
-	return generateInternalSingleFile(addonsCamel, modelCamel, controllerCamel, logicCamel)
+	return generateInternalSingleFile(addonsCamel, modelCamel, controllerCamel, logicCamel, specs)
 }
 
-// 只在 internal 目录下生成单独文件
-func generateInternalSingleFile(addonsCamel, modelCamel, controllerCamel, logicCamel string) error {
-	// 获取 go.mod 里的 module 名称
-	modName := ""
+// 获取 go.mod 里的 module 名称
+func getModName() (string, error) {
 	if modData := gfile.GetContents("go.mod"); modData != "" {
 		for _, line := range gstr.Split(modData, "\n") {
 			if gstr.HasPrefix(line, "module ") {
-				modName = gstr.Trim(gstr.TrimLeftStr(line, "module "))
-				break
+				return gstr.Trim(gstr.TrimLeftStr(line, "module ")), nil
 			}
 		}
 	}
-	if modName == "" {
-		return fmt.Errorf("无法获取go.mod中的module名称")
+	return "", fmt.Errorf("无法获取go.mod中的module名称")
+}
+
+// 只在 internal 目录下生成单独文件
+func generateInternalSingleFile(addonsCamel, modelCamel, controllerCamel, logicCamel string, specs []*fieldSpec) error {
+	modName, err := getModName()
+	if err != nil {
+		return err
 	}
 	// undersAddons := gstr.CaseSnakeFirstUpper(addonsName)
 	basePath := "internal"
@@ -177,10 +245,9 @@ func generateInternalSingleFile(addonsCamel, modelCamel, controllerCamel, logicC
 
 	// 生成模型（如果指定了 model）
 	if modelCamel != "" {
-		if err := generateModelAtPath(addonsCamel, modelCamel, basePath); err != nil {
+		if err := generateModelWithFields(addonsCamel, modelCamel, basePath, specs); err != nil {
 			return err
 		}
-		fmt.Printf("模型 %s 已生成到 %s/model\n", modelCamel, basePath)
 	}
 
 	// 生成控制器（如果指定了 controller）
@@ -188,34 +255,23 @@ func generateInternalSingleFile(addonsCamel, modelCamel, controllerCamel, logicC
 		if err := generateControllerAtPath(addonsCamel, controllerCamel, "admin", basePath, importPrefix); err != nil {
 			return err
 		}
-		fmt.Printf("控制器 %s 已生成到 %s/controller/admin\n", controllerCamel, basePath)
 	}
 
 	// 生成逻辑（如果指定了 logic）
 	if logicCamel != "" {
-		if err := generateLogicSysAtPath(logicCamel, basePath, importPrefix, ""); err != nil {
+		if err := generateLogicSysAtPath(logicCamel, basePath, importPrefix, "", logicFieldSpec(addonsCamel, logicCamel, specs)); err != nil {
 			return err
 		}
-		fmt.Printf("逻辑 %s 已生成到 %s/logic/sys\n", logicCamel, basePath)
 	}
 
 	return nil
 }
 
 // 生成 addons 目录下的代码
-func generateAddonCode(addonsCamel, module, modelCamel, controllerCamel, logicCamel string) error {
-	// 获取 go.mod 里的 module 名称
-	modName := ""
-	if modData := gfile.GetContents("go.mod"); modData != "" {
-		for _, line := range gstr.Split(modData, "\n") {
-			if gstr.HasPrefix(line, "module ") {
-				modName = gstr.Trim(gstr.TrimLeftStr(line, "module "))
-				break
-			}
-		}
-	}
-	if modName == "" {
-		return fmt.Errorf("无法获取go.mod中的module名称")
+func generateAddonCode(addonsCamel, module, modelCamel, controllerCamel, logicCamel string, specs []*fieldSpec) error {
+	modName, err := getModName()
+	if err != nil {
+		return err
 	}
 	//下划线命名
 	undersAddons := gstr.CaseSnakeFirstUpper(addonsCamel)
@@ -234,33 +290,28 @@ func generateAddonCode(addonsCamel, module, modelCamel, controllerCamel, logicCa
 			if err := generateControllerAtPath(addonsCamel, controllerCamel, "admin", basePath, importPrefix); err != nil {
 				return err
 			}
-			fmt.Printf("控制器 %s 已生成到 %s/controller/admin\n", controllerCamel, basePath)
 			if err := generateControllerAtPath(addonsCamel, controllerCamel, "app", basePath, importPrefix); err != nil {
 				return err
 			}
-			fmt.Printf("控制器 %s 已生成到 %s/controller/app\n", controllerCamel, basePath)
 		} else {
 			if err := generateControllerAtPath(addonsCamel, controllerCamel, module, basePath, importPrefix); err != nil {
 				return err
 			}
-			fmt.Printf("控制器 %s 已生成到 %s/controller/%s\n", controllerCamel, basePath, module)
 		}
 	}
 
 	// 生成模型
 	if modelCamel != "" {
-		if err := generateModelAtPath(addonsCamel, modelCamel, basePath); err != nil {
+		if err := generateModelWithFields(addonsCamel, modelCamel, basePath, specs); err != nil {
 			return err
 		}
-		fmt.Printf("模型 %s 已生成到 %s/model\n", modelCamel, basePath)
 	}
 
 	// 生成逻辑
 	if logicCamel != "" {
-		if err := generateLogicSysAtPath(logicCamel, basePath, importPrefix, addonsCamel); err != nil {
+		if err := generateLogicSysAtPath(logicCamel, basePath, importPrefix, addonsCamel, logicFieldSpec(addonsCamel, logicCamel, specs)); err != nil {
 			return err
 		}
-		fmt.Printf("逻辑 %s 已生成到 %s/logic/sys\n", logicCamel, basePath)
 	}
 
 	return nil
@@ -273,116 +324,62 @@ func generateAddonModule(addonsCamel, module, basePath, importPrefix string) err
 	undersName := gstr.CaseSnakeFirstUpper(addonsCamel)
 
 	// 根据 module 决定生成哪些目录
-	var subDirs []string
+	var subs []string
 	switch module {
 	case "admin":
-		subDirs = []string{
-			"controller/admin",
-			"model",
-			"logic",
-			"service",
-			"middleware",
-			"funcs",
-			"config",
-			"consts",
-			"packed",
-			"resource/initjson",
-			"api/v1",
-			"controller",
-		}
+		subs = []string{"admin"}
 	case "app":
-		subDirs = []string{
-			"controller/app",
-			"model",
-			"logic",
-			"service",
-			"middleware",
-			"funcs",
-			"config",
-			"consts",
-			"packed",
-			"resource/initjson",
-			"api/v1",
-			"controller",
-		}
+		subs = []string{"app"}
 	default:
-		subDirs = []string{
-			"controller/admin",
-			"controller/app",
-			"model",
-			"logic",
-			"service",
-			"middleware",
-			"funcs",
-			"config",
-			"consts",
-			"packed",
-			"resource/initjson",
-			"api/v1",
-			"controller",
-		}
+		subs = []string{"admin", "app"}
+	}
+	subDirs := []string{
+		"model",
+		"logic",
+		"service",
+		"middleware",
+		"funcs",
+		"config",
+		"consts",
+		"packed",
+		"resource/initjson",
+		"api/v1",
+		"controller",
+	}
+	for _, sub := range subs {
+		subDirs = append(subDirs, "controller/"+sub)
 	}
-
 	for _, dir := range subDirs {
-		fullPath := filepath.Join(basePath, dir)
-
-		if err := gfile.Mkdir(fullPath); err != nil {
-			return fmt.Errorf("创建目录失败: %s, 错误: %v", fullPath, err)
+		if err := mkdirGen(filepath.Join(basePath, dir)); err != nil {
+			return err
 		}
-
 	}
 
-	// 合并 controller 下 admin、app 子目录及 go 文件的生成
-	for _, parent := range []string{"controller"} {
-		var subs []string
-		switch module {
-		case "admin":
-			subs = []string{"admin"}
-		case "app":
-			subs = []string{"app"}
-		default:
-			subs = []string{"admin", "app"}
-		}
-
-		parentBase := filepath.Join(basePath, parent)
-		for _, sub := range subs {
-			subDir := filepath.Join(parentBase, sub)
-
-			if err := gfile.Mkdir(subDir); err != nil {
-				return fmt.Errorf("创建目录失败: %s, 错误: %v", subDir, err)
-			}
-
-			filePath := filepath.Join(subDir, sub+".go")
-			if gfile.Exists(filePath) {
-				fmt.Printf("目录文件已存在: %s\n", filePath)
-				continue
-			}
-
-			content := fmt.Sprintf("package %s\n\n// %s 插件的 %s/%s 代码\n", sub, undersName, parent, sub)
-			if err := gfile.PutContents(filePath, content); err != nil {
-				return fmt.Errorf("写入 %s 失败: %v", filePath, err)
-			}
-
+	// controller 下 admin、app 子目录的 go 文件
+	for _, sub := range subs {
+		filePath := filepath.Join(basePath, "controller", sub, sub+".go")
+		if err := generatePackageFile(filePath, sub, fmt.Sprintf("%s 插件的 controller/%s 代码", undersName, sub)); err != nil {
+			return err
 		}
 	}
 
-	// 合并 api 下 v1 子目录及 go 文件的生成
-	if err := generateAddonApiV1(basePath, undersName); err != nil {
+	// 生成 api/v1 目录下的 插件名.go
+	if err := generatePackageFile(filepath.Join(basePath, "api", "v1", undersName+".go"), "v1", fmt.Sprintf("%s 插件的 api/v1/%s.go 代码", undersName, undersName)); err != nil {
 		return err
 	}
 
 	// 生成 controller 目录下的 controller.go
-	if err := generateAddonController(basePath, importPrefix); err != nil {
+	if err := generateAddonController(basePath, importPrefix, subs); err != nil {
 		return err
 	}
 
 	// 生成 model 目录下的 model.go
-	if err := generateAddonModel(basePath, undersName); err != nil {
+	if err := generatePackageFile(filepath.Join(basePath, "model", "model.go"), "model", fmt.Sprintf("%s 插件的 model/model.go 代码", undersName)); err != nil {
 		return err
 	}
 
 	// 生成插件根目录下的 config.go
-	if err := generateAddonConfig(basePath, undersName); err != nil {
+	if err := generateFile("配置", filepath.Join(basePath, "config.go"), "addon_config.go.tmpl", &addonData{Package: undersName}); err != nil {
 		return err
 	}
 
@@ -391,460 +388,204 @@ func generateAddonModule(addonsCamel, module, basePath, importPrefix string) err
 		return err
 	}
 
-	fmt.Printf("插件模块 %s 目录结构已生成于 %s\n", undersName, basePath)
-	return nil
-}
-
-// 生成目录下的api/v1/api.go
-func generateAddonApiV1(basePath, undersName string) error {
-	apiV1Dir := filepath.Join(basePath, "api", "v1")
-	if err := gfile.Mkdir(apiV1Dir); err != nil {
-		return fmt.Errorf("创建目录失败: %s, 错误: %v", apiV1Dir, err)
-	}
-
-	filePath := filepath.Join(apiV1Dir, undersName+".go")
-	if gfile.Exists(filePath) {
-		fmt.Printf("api/v1/%s.go 文件已存在: %s\n", undersName, filePath)
-		return nil
-	}
-
-	content := fmt.Sprintf("package v1\n\n// %s 插件的 api/v1/%s.go 代码\n", undersName, undersName)
-	if err := gfile.PutContents(filePath, content); err != nil {
-		return fmt.Errorf("写入 %s 失败: %v", filePath, err)
+	if !genOptions.DryRun {
+		fmt.Printf("插件模块 %s 目录结构已生成于 %s\n", undersName, basePath)
 	}
 	return nil
 }
 
-// 生成目录下的controller.go
-func generateAddonController(basePath, importPrefix string) error {
-	controllerDir := filepath.Join(basePath, "controller")
-	if err := gfile.Mkdir(controllerDir); err != nil {
-		return fmt.Errorf("创建目录失败: %s, 错误: %v", controllerDir, err)
-	}
-
-	// 生成 controller 目录下的 go 文件
-	filePath := filepath.Join(controllerDir, "controller.go")
-	if gfile.Exists(filePath) {
-		fmt.Printf("controller/controller.go 文件已存在: %s\n", filePath)
-		return nil
-	}
-
-	templaleData := `package controller
-
-import (
-	_ "%s/controller/admin"
-	_ "%s/controller/app"
-)
-`
-
-	content := fmt.Sprintf(
-		templaleData,
-		importPrefix,
-		importPrefix,
-	)
-	if err := gfile.PutContents(filePath, content); err != nil {
-		return fmt.Errorf("写入 %s 失败: %v", filePath, err)
-	}
-	return nil
+// 插件模板参数
+type addonData struct {
+	Package      string   // 包名,即插件目录名
+	Name         string   // 插件结构体名称前缀
+	ImportPrefix string   // 插件的导入路径
+	Modules      []string // controller 下的模块,如 admin、app
 }
 
-// 生成目录下的model.go
-func generateAddonModel(basePath, undersName string) error {
-	modelDir := filepath.Join(basePath, "model")
-	if err := gfile.Mkdir(modelDir); err != nil {
-		return fmt.Errorf("创建目录失败: %s, 错误: %v", modelDir, err)
-	}
-
-	// 生成 model 目录下的 go 文件
-	filePath := filepath.Join(modelDir, "model.go")
-	if gfile.Exists(filePath) {
-		fmt.Printf("model/model.go 文件已存在: %s\n", filePath)
-		return nil
-	}
-
-	content := fmt.Sprintf("package model\n\n// %s 插件的 model/model.go 代码\n", undersName)
-	if err := gfile.PutContents(filePath, content); err != nil {
-		return fmt.Errorf("写入 %s 失败: %v", filePath, err)
-	}
-	return nil
+// 只有包声明和注释的文件
+type packageData struct {
+	Package string
+	Comment string
 }
 
-// 生成插件根目录下的 config.go
-func generateAddonConfig(basePath, undersName string) error {
-	configPath := filepath.Join(basePath, "config.go")
-	if gfile.Exists(configPath) {
-		fmt.Printf("配置文件已存在: %s\n", configPath)
-		return nil
-	}
-
-	templaleData := `package %s
-
-	import "github.com/gzdzh-cn/dzhcore"
-	var (
-		Version = "v1.0.0"
-	)
+func generatePackageFile(file, pkg, comment string) error {
+	return generateFile("目录", file, "package.go.tmpl", &packageData{Package: pkg, Comment: comment})
+}
 
-	func init() {
-		dzhcore.SetVersions("%s", Version)
+// 生成 controller 目录下的 controller.go,导入已有的 admin、app 控制器目录
+func generateAddonController(basePath, importPrefix string, subs []string) error {
+	modules := make([]string, 0, 2)
+	for _, sub := range []string{"admin", "app"} {
+		if garray.NewStrArrayFrom(subs).Contains(sub) || gfile.IsDir(filepath.Join(basePath, "controller", sub)) {
+			modules = append(modules, sub)
+		}
 	}
-`
-	content := fmt.Sprintf(templaleData, undersName, undersName)
-	if err := gfile.PutContents(configPath, content); err != nil {
-		return fmt.Errorf("写入 %s 失败: %v", configPath, err)
+	content, err := renderTemplate("addon_controller.go.tmpl", &addonData{ImportPrefix: importPrefix, Modules: modules})
+	if err != nil {
+		return err
 	}
-
-	return nil
+	return putGenFile("控制器入口", filepath.Join(basePath, "controller", "controller.go"), content, true)
 }
 
 // 生成插件根目录下的插件名.go
 func generateAddonMain(basePath, undersName, importPrefix string) error {
 	mainPath := filepath.Join(basePath, undersName+".go")
-	if gfile.Exists(mainPath) {
-		fmt.Printf("插件主入口文件已存在: %s\n", mainPath)
-		return nil
-	}
-	addonsCamel := gstr.CaseCamelLower(undersName)
-	lcFirstAddonsCamel := gstr.LcFirst(addonsCamel)
-	templaleData := `package %s
-
-	import (
-		"github.com/gogf/gf/v2/frame/g"
-		"github.com/gogf/gf/v2/os/gctx"
-		"github.com/gzdzh-cn/dzhcore"
-		_ "%s/controller"
-		_ "%s/model"
-	)
-	
-	func init() {
-		dzhcore.AddAddon(&%sAddon{Version: Version, Name: "%s"})
-	}
-	
-	type %sAddon struct {
-		Version string
-		Name    string
-	}
-	
-	func (a *%sAddon) GetName() string {
-		return a.Name
-	}
-	
-	func (a *%sAddon) GetVersion() string {
-		return a.Version
-	}
-	
-	func (a *%sAddon) NewInit() {
-		var (
-			ctx = gctx.GetInitCtx()
-		)
-		g.Log().Debug(ctx, "------------ addon %s init start ...")
-		g.Log().Debugf(ctx, "%s version:%%v", Version)
-	}
-	
-	`
-
-	content := fmt.Sprintf(
-		templaleData,
-		undersName,
-		importPrefix,
-		importPrefix,
-		lcFirstAddonsCamel,
-		undersName,
-		lcFirstAddonsCamel,
-		lcFirstAddonsCamel,
-		lcFirstAddonsCamel,
-		lcFirstAddonsCamel,
-		undersName,
-		undersName,
-	)
-	if err := gfile.PutContents(mainPath, content); err != nil {
-		return fmt.Errorf("写入 %s 失败: %v", mainPath, err)
+	exists := gfile.Exists(mainPath)
+	err := generateFile("插件主入口", mainPath, "addon.go.tmpl", &addonData{
+		Package:      undersName,
+		Name:         gstr.LcFirst(gstr.CaseCamelLower(undersName)),
+		ImportPrefix: importPrefix,
+	})
+	if err != nil || exists {
+		return err
 	}
-	return nil
-}
-
-// 新增：支持自定义 basePath 和 importPrefix 的模型生成函数
-func generateModelAtPath(addonsCamel, modelCamel, basePath string) error {
-
-	modelTemplate := `package model
-
-import (
-	"github.com/gzdzh-cn/dzhcore"
-)
-
-const TableName%s = "addons_%s"
-
-// %s 模型，映射表 <addons_%s>
-type %s struct {
-	*dzhcore.Model
-	Title     string  %cgorm:"column:title;comment:标题;type:varchar(255);not null" json:"title"%c // 标题
-	Status   int     %cgorm:"column:status;comment:状态;type:int(11);default:1" json:"status"%c // 状态
-	OrderNum int32   %cgorm:"column:order_num;comment:排序;type:int;not null;default:99" json:"orderNum"%c  // 排序
-	Remark   *string %cgorm:"column:remark;comment:备注;type:varchar(255)" json:"remark"%c      // 备注
-}
-
-// TableName %s 的表名
-func (*%s) TableName() string {
-	return TableName%s
+	return wireAddon(importPrefix)
 }
 
-// GroupName %s 的表分组
-func (*%s) GroupName() string {
-	return "default"
-}
-
-// New%s 创建一个新的 %s 实例
-func New%s() *%s {
-	return &%s{
-		Model: dzhcore.NewModel(),
+// 在项目的 addons/addons.go 中导入插件,没有该文件时提示手动导入
+func wireAddon(importPrefix string) error {
+	const addonsFile = "addons/addons.go"
+	line := fmt.Sprintf("_ %q", importPrefix)
+	if !gfile.Exists(addonsFile) {
+		fmt.Printf("请在项目中导入插件: import %s\n", line)
+		return nil
 	}
+	content := gfile.GetContents(addonsFile)
+	if strings.Contains(content, fmt.Sprintf("%q", importPrefix)) {
+		return nil
+	}
+	if strings.Contains(content, "import (") {
+		content = strings.Replace(content, "import (", "import (\n\t"+line, 1)
+	} else {
+		content = strings.TrimRight(content, "\n") + "\n\nimport (\n\t" + line + "\n)\n"
+	}
+	return putGenFile("插件导入", addonsFile, content, true)
 }
 
-// init 注册模型
-func init() {
-	dzhcore.AddModel(&%s{})
-}
-`
-
-	// 驼峰转下划线
-	undersAddonsName := gstr.CaseSnakeFirstUpper(addonsCamel)
-
-	modelDir := filepath.Join(basePath, "model")
-
-	// 下划线命名
+// 模型对应的表名
+func modelTableName(addonsCamel, modelCamel string) string {
 	undersModelName := gstr.CaseSnakeFirstUpper(modelCamel)
 	if addonsCamel != "" {
-		undersModelName = undersAddonsName + "_" + gstr.CaseSnakeFirstUpper(modelCamel)
+		undersModelName = gstr.CaseSnakeFirstUpper(addonsCamel) + "_" + undersModelName
 	}
-	modelPath := filepath.Join(modelDir, undersModelName+".go")
-	if !gfile.Exists(modelDir) {
-		if err := gfile.Mkdir(modelDir); err != nil {
-			return fmt.Errorf("创建目录失败: %s, 错误: %v", modelDir, err)
-		}
+	return "addons_" + undersModelName
+}
+
+// 生成模型,有字段定义时按字段生成并生成种子数据文件
+func generateModelWithFields(addonsCamel, modelCamel, basePath string, specs []*fieldSpec) error {
+	if len(specs) == 0 {
+		return generateModelAtPath(addonsCamel, modelCamel, basePath)
 	}
-	if gfile.Exists(modelPath) {
-		fmt.Printf("模型文件已存在: %s\n", modelPath)
-		return nil
+	table := modelTableName(addonsCamel, modelCamel)
+	if err := generateModelFromTable(addonsCamel, modelCamel, basePath, fieldSpecsTable(table, specs)); err != nil {
+		return err
 	}
-
-	// 驼峰
-	caseCamelTableName := gstr.CaseCamel(undersModelName)
-	modelContent := fmt.Sprintf(modelTemplate,
-		caseCamelTableName, undersModelName, // 第1-2个: TableName%s, "addons_%s"
-		caseCamelTableName, undersModelName, // 第3-4个: // %s 模型, <addons_%s>
-		caseCamelTableName,                     // 第5个: type %s struct
-		'`', '`', '`', '`', '`', '`', '`', '`', // 第6-13个: 8 个 struct tag 反引号
-		caseCamelTableName, caseCamelTableName, caseCamelTableName, // 第14-16个: // TableName, func (*), return TableName
-		caseCamelTableName, caseCamelTableName, // 第17-18个: // GroupName, func (*)
-		caseCamelTableName, caseCamelTableName, caseCamelTableName, caseCamelTableName, caseCamelTableName, // 第19-23个: // New, 实例, func New, *%s, &%s{
-		caseCamelTableName, // 第24个: dzhcore.AddModel(&%s{})
-	)
-	if err := gfile.PutContents(modelPath, modelContent); err != nil {
-		return fmt.Errorf("写入模型文件失败: %v", err)
-	}
-	fmt.Printf("生成模型: %s\n", modelPath)
-	return nil
+	return generateSeedStub(basePath, table, addonsCamel, gstr.CaseCamel(strings.TrimPrefix(table, "addons_")))
 }
 
-// 新增：支持自定义 basePath 和 importPrefix 的 logic/sys 生成函数，内容为 dict.go 模板
-func generateLogicSysAtPath(logicCamel, basePath, importPrefix, addonsCamel string) error {
-
-	const logicSysTemplate = `package sys
-	
-import (
-	"%s/dao"
-	"%s/model"
-	"%s/service"
-	"context"
-
-	"github.com/gogf/gf/v2/database/gdb"
-	"github.com/gogf/gf/v2/frame/g"
-	"github.com/gzdzh-cn/dzhcore"
-)
-
-func init() {
-	service.Register%sService(&s%sService{})
+// 逻辑的字段配置,没有字段定义时为空
+func logicFieldSpec(addonsCamel, logicCamel string, specs []*fieldSpec) *logicSpec {
+	if len(specs) == 0 {
+		return nil
+	}
+	return fieldSpecsLogic(modelTableName(addonsCamel, logicCamel), specs)
 }
 
-type s%sService struct {
-	*dzhcore.Service
+// 默认模型的字段,与按模板生成之前的模型一致
+var defaultModelFields = []*modelField{
+	{Name: "Title", Type: "string", Gorm: "column:title;comment:标题;type:varchar(255);not null", Json: "title", Comment: "标题",
+		Column: &tableColumn{Name: "title", Type: "varchar", ColumnType: "varchar(255)", Length: 255, Comment: "标题"}},
+	{Name: "Status", Type: "int", Gorm: "column:status;comment:状态;type:int(11);default:1", Json: "status", Comment: "状态",
+		Column: &tableColumn{Name: "status", Type: "int", ColumnType: "int(11)", Nullable: true, HasDefault: true, Default: "1", Comment: "状态"}},
+	{Name: "OrderNum", Type: "int32", Gorm: "column:order_num;comment:排序;type:int;not null;default:99", Json: "orderNum", Comment: "排序",
+		Column: &tableColumn{Name: "order_num", Type: "int", ColumnType: "int", HasDefault: true, Default: "99", Comment: "排序"}},
+	{Name: "Remark", Type: "*string", Gorm: "column:remark;comment:备注;type:varchar(255)", Json: "remark", Comment: "备注",
+		Column: &tableColumn{Name: "remark", Type: "varchar", ColumnType: "varchar(255)", Length: 255, Nullable: true, Comment: "备注"}},
 }
 
-func News%sService() *s%sService {
-	return &s%sService{
-		&dzhcore.Service{
-			Dao:   &dao.%s,
-			Model: model.New%s(),
-			ListQueryOp: &dzhcore.QueryOp{
-				FieldEQ:      []string{""},                     // 字段等于
-				KeyWordField: []string{""},                     // 模糊搜索匹配的数据库字段
-				AddOrderby:   g.MapStrStr{"createTime": "DESC"}, // 添加排序
-				Where: func(ctx context.Context) []g.Array { // 自定义条件
-					return []g.Array{}
-				},
-				OrWhere: func(ctx context.Context) []g.Array { // or 自定义条件
-					return []g.Array{}
-				},
-				Select: "",                  // 查询字段,多个字段用逗号隔开 如: id,name  或  a.id,a.name,b.name AS bname
-				As:     "",                  //主表别名
-				Join:   []*dzhcore.JoinOp{}, // 关联查询
-				Extend: func(ctx g.Ctx, m *gdb.Model) *gdb.Model { // 追加其他条件
-					return m
-				},
-				ModifyResult: func(ctx g.Ctx, data any) any { // 修改结果
-					return data
-				},
-			},
-			PageQueryOp: &dzhcore.QueryOp{
-				FieldEQ:      []string{""},                     // 字段等于
-				KeyWordField: []string{""},                     // 模糊搜索匹配的数据库字段
-				AddOrderby:   g.MapStrStr{"createTime": "DESC"}, // 添加排序
-				Where: func(ctx context.Context) []g.Array { // 自定义条件
-					return []g.Array{}
-				},
-				OrWhere: func(ctx context.Context) []g.Array { // or 自定义条件
-					return []g.Array{}
-				},
-				Select: "",                  // 查询字段,多个字段用逗号隔开 如: id,name  或  a.id,a.name,b.name AS bname
-				As:     "",                  //主表别名
-				Join:   []*dzhcore.JoinOp{}, // 关联查询
-				Extend: func(ctx g.Ctx, m *gdb.Model) *gdb.Model { // 追加其他条件
-					return m
-				},
-				ModifyResult: func(ctx g.Ctx, data any) any { // 修改结果
-					return data
-				},
-			},
-			InsertParam: func(ctx context.Context) g.MapStrAny { // Add时插入参数
-				return g.MapStrAny{}
-			},
-			Before: func(ctx context.Context) (err error) { // CRUD前的操作
-				return nil
-			},
-			InfoIgnoreProperty: "",            // Info时忽略的字段,多个字段用逗号隔开
-			UniqueKey:          g.MapStrStr{}, // 唯一键 key:字段名 value:错误信息
-			NotNullKey:         g.MapStrStr{}, // 非空键 key:字段名 value:错误信息
-		},
+// 新增：支持自定义 basePath 和 importPrefix 的模型生成函数
+func generateModelAtPath(addonsCamel, modelCamel, basePath string) error {
+	data, file, err := newModelData(addonsCamel, modelCamel, basePath, &tableInfo{
+		Group: "default",
+		Name:  modelTableName(addonsCamel, modelCamel),
+	})
+	if err != nil {
+		return err
 	}
+	data.Fields = defaultModelFields
+	return generateFile("模型", file, "model.go.tmpl", data)
 }
 
-func (s *s%sService) Test(ctx context.Context) (err error) {
-	return nil
+// 逻辑模板参数
+type logicData struct {
+	*logicSpec
+	ImportPrefix string // 模块的导入路径
+	Name         string // 服务名称,如 ShopOrder
+	DaoName      string // dao 名称,如 AddonsShopOrder
 }
-`
-
-	// 驼峰转下划线
-	undersAddonsName := gstr.CaseSnakeFirstUpper(addonsCamel)
 
-	// 首字母大写驼峰命名
-	ucFirstLogicCamel := gstr.UcFirst(logicCamel)
+// 新增：支持自定义 basePath 和 importPrefix 的 logic/sys 生成函数，内容为 dict.go 模板
+// spec 为查询字段、非空键、唯一键、校验规则和字典字段,为空时留空
+func generateLogicSysAtPath(logicCamel, basePath, importPrefix, addonsCamel string, spec *logicSpec) error {
 	logicSysDir := filepath.Join(basePath, "logic", "sys")
-	if err := gfile.Mkdir(logicSysDir); err != nil && !gfile.Exists(logicSysDir) {
-		return fmt.Errorf("创建 %s 目录失败: %v", logicSysDir, err)
+	if err := mkdirGen(logicSysDir); err != nil {
+		return err
 	}
 
 	//下划线命名
 	undersName := gstr.CaseSnakeFirstUpper(logicCamel)
 	if addonsCamel != "" {
-		undersName = undersAddonsName + "_" + gstr.CaseSnakeFirstUpper(logicCamel)
+		undersName = gstr.CaseSnakeFirstUpper(addonsCamel) + "_" + undersName
 	}
-	logicFile := filepath.Join(logicSysDir, undersName+".go")
-	if gfile.Exists(logicFile) {
-		fmt.Printf("逻辑文件已存在1: %s\n", logicFile)
-		return nil
+	if spec == nil {
+		spec = &logicSpec{}
 	}
 
-	daoName := ucFirstLogicCamel
+	// 首字母大写驼峰命名
+	data := &logicData{logicSpec: spec, ImportPrefix: importPrefix, Name: gstr.UcFirst(logicCamel)}
+	data.DaoName = data.Name
 	if addonsCamel != "" {
-		ucFirstLogicCamel = gstr.UcFirst(addonsCamel + ucFirstLogicCamel)
-		daoName = "Addons" + ucFirstLogicCamel
-	}
-
-	content := fmt.Sprintf(
-		logicSysTemplate,
-		importPrefix,
-		importPrefix,
-		importPrefix,
-		ucFirstLogicCamel, ucFirstLogicCamel,
-		ucFirstLogicCamel,
-		ucFirstLogicCamel, ucFirstLogicCamel, ucFirstLogicCamel,
-		daoName, ucFirstLogicCamel, ucFirstLogicCamel,
-	)
-	if err := gfile.PutContents(logicFile, content); err != nil {
-		return fmt.Errorf("写入 %s 失败: %v", logicFile, err)
+		data.Name = gstr.UcFirst(addonsCamel + data.Name)
+		data.DaoName = "Addons" + data.Name
 	}
-	fmt.Printf("生成逻辑实现: %s\n", logicFile)
+	return generateFile("逻辑", filepath.Join(logicSysDir, undersName+".go"), "logic.go.tmpl", data)
+}
 
-	return nil
+// 控制器模板参数
+type controllerData struct {
+	Package      string // 包名,即所属模块 admin、app
+	ImportPrefix string // 模块的导入路径
+	Name         string // 控制器名称,如 ShopOrder
+	Var          string // 变量名,如 shopOrder
+	Prefix       string // 路由前缀
 }
 
 // 新增：支持自定义 basePath 和 importPrefix 的控制器生成函数
 func generateControllerAtPath(addonsCamel, controllerCamel, module, basePath, importPrefix string) error {
 
-	const controllerTemplate = `package %s
-
-import (
-
-	logic "%s/logic/sys"
-
-	"github.com/gzdzh-cn/dzhcore"
-)
-
-type %sController struct {
-	*dzhcore.Controller
-}
-
-func init() {
-	var %sController = &%sController{
-		&dzhcore.Controller{
-			Prefix:  "/%s/%s",
-			Api:     []string{"Add", "Delete", "Update", "Info", "List", "Page"},
-			Service: logic.News%sService(),
-		},
-	}
-
-	// 注册路由
-	dzhcore.AddController(%sController)
-}
-`
-
 	// 驼峰转下划线
 	undersAddonsName := gstr.CaseSnakeFirstUpper(addonsCamel)
 
-	upperController := gstr.UcFirst(controllerCamel)
 	controllerDir := filepath.Join(basePath, "controller", module)
-	if err := gfile.Mkdir(controllerDir); err != nil && !gfile.Exists(controllerDir) {
-		return fmt.Errorf("创建目录失败: %s, 错误: %v", controllerDir, err)
+	if err := mkdirGen(controllerDir); err != nil {
+		return err
 	}
 	//下划线命名
 	undersName := gstr.CaseSnakeFirstUpper(controllerCamel)
 	if addonsCamel != "" {
 		undersName = undersAddonsName + "_" + gstr.CaseSnakeFirstUpper(controllerCamel)
 	}
-	controllerPath := filepath.Join(controllerDir, undersName+".go")
-	if gfile.Exists(controllerPath) {
-		fmt.Printf("控制器文件已存在: %s\n", controllerPath)
-		return nil
-	}
 
-	if addonsCamel != "" {
-		upperController = gstr.UcFirst(addonsCamel) + upperController
-		controllerCamel = addonsCamel + gstr.UcFirst(controllerCamel)
+	data := &controllerData{
+		Package:      module,
+		ImportPrefix: importPrefix,
+		Name:         gstr.UcFirst(controllerCamel),
+		Var:          controllerCamel,
+		Prefix:       "/" + module + "/" + processAddonsPathWithUnderscore(undersName, undersAddonsName),
 	}
-
-	content := fmt.Sprintf(
-		controllerTemplate,
-		module,
-		importPrefix,
-		upperController,
-		controllerCamel, upperController,
-		module, processAddonsPathWithUnderscore(undersName, undersAddonsName),
-		upperController,
-		controllerCamel,
-	)
-	if err := gfile.PutContents(controllerPath, content); err != nil {
-		return fmt.Errorf("写入控制器文件失败: %v", err)
+	if addonsCamel != "" {
+		data.Name = gstr.UcFirst(addonsCamel) + data.Name
+		data.Var = addonsCamel + gstr.UcFirst(controllerCamel)
 	}
-	fmt.Printf("生成控制器: %s\n", controllerPath)
-
-	return nil
+	return generateFile("控制器", filepath.Join(controllerDir, undersName+".go"), "controller.go.tmpl", data)
 }
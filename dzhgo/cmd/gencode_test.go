@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "更新 testdata 中的生成结果")
+
+// 在临时项目目录中执行
+func chdirProject(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/app\n\ngo 1.24\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(dir)
+	return dir
+}
+
+// 目录下的全部文件 key:相对路径
+func readTree(t *testing.T, root string) map[string]string {
+	t.Helper()
+	files := make(map[string]string)
+	err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(root, path)
+		files[strings.TrimSuffix(filepath.ToSlash(rel), ".golden")] = string(content)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return files
+}
+
+// 生成的文件与 golden 目录一致,-update 时更新 golden 目录
+// golden 为绝对路径,需要在 chdirProject 之前获取
+func checkGolden(t *testing.T, golden string, got map[string]string) {
+	t.Helper()
+	if *update {
+		if err := os.RemoveAll(golden); err != nil {
+			t.Fatal(err)
+		}
+		for name, content := range got {
+			file := filepath.Join(golden, filepath.FromSlash(name)+".golden")
+			if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return
+	}
+	want := readTree(t, golden)
+	for name, content := range want {
+		if got[name] != content {
+			t.Errorf("%s 与 testdata 不一致:\n%s", name, got[name])
+		}
+	}
+	for name := range got {
+		if _, ok := want[name]; !ok {
+			t.Errorf("多生成了 %s", name)
+		}
+	}
+}
+
+// 内置模板生成的插件与 testdata/gen 一致,默认模型的字段与按模板生成之前相同
+func TestGenerateAddonGolden(t *testing.T) {
+	golden, err := filepath.Abs("testdata/gen")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := chdirProject(t)
+	if err = generateAddonCode("shop", "", "order", "order", "order", nil); err != nil {
+		t.Fatal(err)
+	}
+	checkGolden(t, golden, readTree(t, filepath.Join(dir, "addons")))
+}
@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+)
+
+// 差异的上下文行数
+const diffContext = 3
+
+// 按行比较内容,返回 unified diff 格式的差异,old 为空时为新增文件
+func unifiedDiff(file, old, new string) string {
+	var (
+		a   = splitLines(old)
+		b   = splitLines(new)
+		ops = diffLines(a, b)
+		buf strings.Builder
+	)
+	fromFile := "a/" + file
+	if old == "" {
+		fromFile = "/dev/null"
+	}
+	fmt.Fprintf(&buf, "--- %s\n+++ b/%s\n", fromFile, file)
+
+	// 按上下文合并相邻的修改为 hunk
+	for i := 0; i < len(ops); {
+		if ops[i].kind == ' ' {
+			i++
+			continue
+		}
+		start := max(i-diffContext, 0)
+		end := i
+		for end < len(ops) {
+			if ops[end].kind != ' ' {
+				end++
+				continue
+			}
+			// 连续相同的行超过两倍上下文时结束 hunk
+			same := end
+			for same < len(ops) && ops[same].kind == ' ' {
+				same++
+			}
+			if same == len(ops) || same-end > 2*diffContext {
+				end = min(end+diffContext, len(ops))
+				break
+			}
+			end = same
+		}
+
+		var aStart, bStart, aLines, bLines int
+		aStart, bStart = ops[start].a+1, ops[start].b+1
+		for _, op := range ops[start:end] {
+			if op.kind != '+' {
+				aLines++
+			}
+			if op.kind != '-' {
+				bLines++
+			}
+		}
+		if aLines == 0 {
+			aStart--
+		}
+		if bLines == 0 {
+			bStart--
+		}
+		fmt.Fprintf(&buf, "@@ -%d,%d +%d,%d @@\n", aStart, aLines, bStart, bLines)
+		for _, op := range ops[start:end] {
+			buf.WriteByte(op.kind)
+			buf.WriteString(op.line)
+			buf.WriteByte('\n')
+		}
+		i = end
+	}
+	return buf.String()
+}
+
+type diffOp struct {
+	kind byte // ' ' 相同, '-' 删除, '+' 新增
+	line string
+	a, b int // 在 old、new 中的行号,从 0 开始
+}
+
+// 最长公共子序列比较,生成的代码文件较小,直接使用动态规划
+func diffLines(a, b []string) []diffOp {
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	var (
+		ops  []diffOp
+		i, j int
+	)
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			ops = append(ops, diffOp{' ', a[i], i, j})
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			ops = append(ops, diffOp{'-', a[i], i, j})
+			i++
+		default:
+			ops = append(ops, diffOp{'+', b[j], i, j})
+			j++
+		}
+	}
+	return ops
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
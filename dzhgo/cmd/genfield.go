@@ -0,0 +1,315 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/gogf/gf/v2/os/gfile"
+	"github.com/gogf/gf/v2/text/gstr"
+)
+
+// 字段定义,格式为 名称:类型[:选项...],多个用逗号分隔,例如:
+// name:string:required:unique,price:decimal(10,2),status:int:dict=order_status
+//
+// 类型: string、string(50)、text、int、bigint、decimal(10,2)、float、bool、date、datetime,其他类型原样作为列类型
+// 选项: required 必填、unique 唯一、index 索引、dict=字典类型key、default=默认值、comment=注释、eq 精确查询、keyword 模糊搜索
+type fieldSpec struct {
+	Name     string
+	Type     string // 列类型,如 varchar(255)
+	Length   int64  // 字符串长度
+	Required bool
+	Unique   bool
+	Index    bool
+	Dict     string
+	Default  string
+	Comment  string
+	EQ       bool
+	KeyWord  bool
+}
+
+// 生成逻辑时的字段配置
+type logicSpec struct {
+	FieldEQ      []string
+	KeyWordField []string
+	NotNullKey   []*fieldMessage
+	UniqueKey    []*fieldMessage
+	Rules        []*fieldMessage
+	DictFields   []*fieldMessage
+}
+
+type fieldMessage struct {
+	Field string
+	Value string
+}
+
+var fieldNamePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*$`)
+
+// 解析字段定义
+func parseFieldSpecs(fields string) ([]*fieldSpec, error) {
+	var (
+		specs []*fieldSpec
+		names = make(map[string]bool)
+	)
+	for _, item := range splitFieldSpecs(fields) {
+		parts := strings.Split(item, ":")
+		spec := &fieldSpec{Name: strings.TrimSpace(parts[0])}
+		if !fieldNamePattern.MatchString(spec.Name) {
+			return nil, fmt.Errorf("字段名 %q 不正确", spec.Name)
+		}
+		if isBaseModelColumn(spec.Name) {
+			return nil, fmt.Errorf("字段 %s 已在 dzhcore.Model 中定义", spec.Name)
+		}
+		if names[spec.Name] {
+			return nil, fmt.Errorf("字段 %s 重复", spec.Name)
+		}
+		names[spec.Name] = true
+
+		fieldType := "string"
+		if len(parts) > 1 && strings.TrimSpace(parts[1]) != "" {
+			fieldType = strings.TrimSpace(parts[1])
+		}
+		if err := spec.setType(fieldType); err != nil {
+			return nil, err
+		}
+		for _, option := range parts[min(len(parts), 2):] {
+			key, value, _ := strings.Cut(strings.TrimSpace(option), "=")
+			switch key {
+			case "required":
+				spec.Required = true
+			case "unique":
+				spec.Unique = true
+			case "index":
+				spec.Index = true
+			case "dict":
+				if value == "" {
+					return nil, fmt.Errorf("字段 %s 的 dict 选项需要字典类型key,例如 dict=order_status", spec.Name)
+				}
+				spec.Dict = value
+			case "default":
+				spec.Default = value
+			case "comment":
+				spec.Comment = value
+			case "eq":
+				spec.EQ = true
+			case "keyword":
+				spec.KeyWord = true
+			case "":
+			default:
+				return nil, fmt.Errorf("字段 %s 的选项 %s 不支持", spec.Name, option)
+			}
+		}
+		specs = append(specs, spec)
+	}
+	if len(specs) == 0 {
+		return nil, fmt.Errorf("字段定义不能为空")
+	}
+	return specs, nil
+}
+
+// 按逗号分隔字段定义,忽略括号内的逗号,如 decimal(10,2)
+func splitFieldSpecs(fields string) (items []string) {
+	var (
+		depth int
+		start int
+	)
+	for i, c := range fields {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				items = append(items, fields[start:i])
+				start = i + 1
+			}
+		}
+	}
+	items = append(items, fields[start:])
+	var result []string
+	for _, item := range items {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+var lengthPattern = regexp.MustCompile(`^\w+\((\d+)\)$`)
+
+// 类型转换为列类型
+func (s *fieldSpec) setType(fieldType string) error {
+	t := strings.ToLower(fieldType)
+	switch {
+	case t == "string":
+		s.Type, s.Length = "varchar(255)", 255
+	case strings.HasPrefix(t, "string("), strings.HasPrefix(t, "varchar("):
+		match := lengthPattern.FindStringSubmatch(t)
+		if match == nil {
+			return fmt.Errorf("字段 %s 的类型 %s 不正确", s.Name, fieldType)
+		}
+		s.Type = "varchar(" + match[1] + ")"
+		fmt.Sscan(match[1], &s.Length)
+	case t == "int", t == "integer":
+		s.Type = "int"
+	case t == "bigint", t == "int64":
+		s.Type = "bigint"
+	case t == "float", t == "double":
+		s.Type = "double"
+	case t == "decimal":
+		s.Type = "decimal(10,2)"
+	case t == "bool", t == "boolean":
+		s.Type = "boolean"
+	default:
+		s.Type = t
+	}
+	return nil
+}
+
+// 显示名称,用于注释和错误信息
+func (s *fieldSpec) label() string {
+	if s.Comment != "" {
+		return s.Comment
+	}
+	return s.Name
+}
+
+// 字段定义转换为表结构,与读取已有表时的结构一致
+func fieldSpecsTable(table string, specs []*fieldSpec) *tableInfo {
+	info := &tableInfo{Group: "default", Name: table}
+	for _, spec := range specs {
+		column := &tableColumn{
+			Name:       spec.Name,
+			Type:       strings.SplitN(spec.Type, "(", 2)[0],
+			ColumnType: spec.Type,
+			Length:     spec.Length,
+			Nullable:   !spec.Required && spec.Default == "",
+			HasDefault: spec.Default != "",
+			Default:    spec.Default,
+			Comment:    spec.Comment,
+		}
+		info.Columns = append(info.Columns, column)
+		if spec.Unique || spec.Index {
+			info.Indexes = append(info.Indexes, &tableIndex{
+				Name:    fmt.Sprintf("idx_%s_%s", table, gstr.CaseSnake(spec.Name)),
+				Columns: []string{spec.Name},
+				Unique:  spec.Unique,
+			})
+		}
+	}
+	return info
+}
+
+// 字段定义转换为逻辑配置
+func fieldSpecsLogic(table string, specs []*fieldSpec) *logicSpec {
+	var (
+		spec     = &logicSpec{}
+		explicit bool
+	)
+	for _, field := range specs {
+		if field.EQ || field.KeyWord {
+			explicit = true
+		}
+	}
+	// 没有指定 eq、keyword 时按字段名和类型推断
+	if !explicit {
+		spec.FieldEQ, spec.KeyWordField = queryFields(fieldSpecsTable(table, specs))
+	}
+	for _, field := range specs {
+		label := field.label()
+		if field.EQ || (field.Dict != "" && !containsFold(spec.FieldEQ, field.Name)) {
+			spec.FieldEQ = append(spec.FieldEQ, field.Name)
+		}
+		if field.KeyWord {
+			spec.KeyWordField = append(spec.KeyWordField, field.Name)
+		}
+		if field.Required {
+			spec.NotNullKey = append(spec.NotNullKey, &fieldMessage{field.Name, label + "不能为空"})
+		}
+		if field.Unique {
+			spec.UniqueKey = append(spec.UniqueKey, &fieldMessage{field.Name, label + "已存在"})
+		}
+		if field.Dict != "" {
+			spec.DictFields = append(spec.DictFields, &fieldMessage{field.Name, field.Dict})
+		}
+		if rule := field.rule(); rule != "" {
+			spec.Rules = append(spec.Rules, &fieldMessage{field.Name, rule})
+		}
+	}
+	return spec
+}
+
+// 字段的 gvalid 校验规则,格式为 规则#错误信息
+func (s *fieldSpec) rule() string {
+	var (
+		label    = s.label()
+		rules    []string
+		messages []string
+	)
+	if s.Required {
+		rules = append(rules, "required")
+		messages = append(messages, label+"不能为空")
+	}
+	switch goType := strings.TrimPrefix(columnGoType(&tableColumn{Type: strings.SplitN(s.Type, "(", 2)[0]}), "*"); goType {
+	case "string":
+		if s.Length > 0 {
+			rules = append(rules, fmt.Sprintf("max-length:%d", s.Length))
+			messages = append(messages, fmt.Sprintf("%s长度不能超过%d", label, s.Length))
+		}
+	case "int", "int64":
+		rules = append(rules, "integer")
+		messages = append(messages, label+"必须是整数")
+	case "float64":
+		rules = append(rules, "float")
+		messages = append(messages, label+"必须是数字")
+	case "bool":
+		rules = append(rules, "boolean")
+		messages = append(messages, label+"必须是布尔值")
+	}
+	if len(rules) == 0 {
+		return ""
+	}
+	return strings.Join(rules, "|") + "#" + strings.Join(messages, "|")
+}
+
+// 生成种子数据文件 <basePath>/resource/initjson/<表名>.json,已存在时跳过
+func generateSeedStub(basePath, table, addonsCamel, modelName string) error {
+	seedDir := filepath.Join(basePath, "resource", "initjson")
+	if err := mkdirGen(seedDir); err != nil {
+		return err
+	}
+	seedPath := filepath.Join(seedDir, table+".json")
+	exists := gfile.Exists(seedPath)
+	if err := putGenFile("种子数据", seedPath, "[]\n", false); err != nil || exists {
+		return err
+	}
+	module := "base"
+	if addonsCamel != "" {
+		module = gstr.CaseSnakeFirstUpper(addonsCamel)
+	}
+	fmt.Printf("在 NewInit 中调用 dzhcore.FillInitData(ctx, \"%s\", model.New%s()) 写入种子数据\n", module, modelName)
+	return nil
+}
+
+// 校验规则的代码,格式为 字段@规则#错误信息,按定义顺序输出
+func rulesLiteral(list []*fieldMessage) string {
+	items := make([]string, 0, len(list))
+	for _, item := range list {
+		items = append(items, fmt.Sprintf("%q", item.Field+"@"+item.Value))
+	}
+	return "[]string{" + strings.Join(items, ", ") + "}"
+}
+
+// 字符串 map 的代码,按定义顺序输出
+func mapStrStrLiteral(list []*fieldMessage) string {
+	if len(list) == 0 {
+		return "g.MapStrStr{}"
+	}
+	items := make([]string, 0, len(list))
+	for _, item := range list {
+		items = append(items, fmt.Sprintf("%q: %q", item.Field, item.Value))
+	}
+	return "g.MapStrStr{" + strings.Join(items, ", ") + "}"
+}
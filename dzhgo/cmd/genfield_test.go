@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseFieldSpecs(t *testing.T) {
+	specs, err := parseFieldSpecs("name:string(50):required:unique:comment=名称, price:decimal(10,2):default=0, status:int:dict=order_status,remark:text")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, spec := range specs {
+		got = append(got, spec.Name+" "+spec.Type)
+	}
+	if want := "name varchar(50),price decimal(10,2),status int,remark text"; strings.Join(got, ",") != want {
+		t.Fatalf("解析结果 %v, 期望 %s", got, want)
+	}
+	name := specs[0]
+	if !name.Required || !name.Unique || name.Length != 50 || name.Comment != "名称" {
+		t.Fatalf("name 的选项 %+v", name)
+	}
+	if specs[1].Default != "0" || specs[2].Dict != "order_status" {
+		t.Fatalf("price 默认值 %q, status 字典 %q", specs[1].Default, specs[2].Dict)
+	}
+
+	for _, fields := range []string{
+		"",
+		"1name",
+		"id:string",
+		"name,name",
+		"status:int:dict",
+		"name:string:unknown",
+		"name:string(x)",
+	} {
+		if _, err = parseFieldSpecs(fields); err == nil {
+			t.Errorf("%q 应解析失败", fields)
+		}
+	}
+}
+
+// 必填、唯一、字典字段和校验规则,指定 eq、keyword 时不再推断查询字段
+func TestFieldSpecsLogic(t *testing.T) {
+	specs, err := parseFieldSpecs("title:string:required:unique,status:int:dict=order_status,price:float,paid:bool")
+	if err != nil {
+		t.Fatal(err)
+	}
+	spec := fieldSpecsLogic("shop_order", specs)
+	if got := strings.Join(spec.FieldEQ, ","); got != "status,paid" {
+		t.Errorf("FieldEQ %s, 期望 status,paid", got)
+	}
+	if got := strings.Join(spec.KeyWordField, ","); got != "title" {
+		t.Errorf("KeyWordField %s, 期望 title", got)
+	}
+	if got := mapStrStrLiteral(spec.NotNullKey) + " " + mapStrStrLiteral(spec.UniqueKey) + " " + mapStrStrLiteral(spec.DictFields); got !=
+		`g.MapStrStr{"title": "title不能为空"} g.MapStrStr{"title": "title已存在"} g.MapStrStr{"status": "order_status"}` {
+		t.Errorf("NotNullKey UniqueKey DictFields %s", got)
+	}
+	want := `[]string{"title@required|max-length:255#title不能为空|title长度不能超过255", "status@integer#status必须是整数", "price@float#price必须是数字", "paid@boolean#paid必须是布尔值"}`
+	if got := rulesLiteral(spec.Rules); got != want {
+		t.Errorf("Rules %s, 期望 %s", got, want)
+	}
+
+	specs, err = parseFieldSpecs("title:string,code:string:keyword,status:int,typeId:bigint:eq")
+	if err != nil {
+		t.Fatal(err)
+	}
+	spec = fieldSpecsLogic("shop_order", specs)
+	if eq, keyword := strings.Join(spec.FieldEQ, ","), strings.Join(spec.KeyWordField, ","); eq != "typeId" || keyword != "code" {
+		t.Errorf("FieldEQ %s KeyWordField %s, 期望 typeId code", eq, keyword)
+	}
+}
+
+// 按字段定义生成的插件与 testdata/field 一致
+func TestGenerateFieldsGolden(t *testing.T) {
+	golden, err := filepath.Abs("testdata/field")
+	if err != nil {
+		t.Fatal(err)
+	}
+	specs, err := parseFieldSpecs("name:string:required:unique:comment=名称,price:decimal(10,2):default=0,status:int:dict=order_status,payTime:datetime")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := chdirProject(t)
+	if err = generateAddonCode("shop", "admin", "order", "order", "order", specs); err != nil {
+		t.Fatal(err)
+	}
+	checkGolden(t, golden, readTree(t, filepath.Join(dir, "addons")))
+}
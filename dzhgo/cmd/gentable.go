@@ -0,0 +1,350 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"go/format"
+	"path/filepath"
+	"strings"
+
+	"github.com/gogf/gf/v2/encoding/gjson"
+	"github.com/gogf/gf/v2/os/gfile"
+	"github.com/gogf/gf/v2/os/gtime"
+	"github.com/gogf/gf/v2/text/gstr"
+)
+
+// 表结构,由项目中的 table inspect 命令读取,与 dzhcore.TableInfo 一致
+type tableInfo struct {
+	Group   string         `json:"group"`
+	Name    string         `json:"name"`
+	Comment string         `json:"comment"`
+	Columns []*tableColumn `json:"columns"`
+	Indexes []*tableIndex  `json:"indexes"`
+}
+
+type tableColumn struct {
+	Name          string `json:"name"`
+	Type          string `json:"type"`
+	ColumnType    string `json:"columnType"`
+	Length        int64  `json:"length"`
+	Nullable      bool   `json:"nullable"`
+	PrimaryKey    bool   `json:"primaryKey"`
+	AutoIncrement bool   `json:"autoIncrement"`
+	HasDefault    bool   `json:"hasDefault"`
+	Default       string `json:"default"`
+	Comment       string `json:"comment"`
+}
+
+type tableIndex struct {
+	Name    string   `json:"name"`
+	Columns []string `json:"columns"`
+	Unique  bool     `json:"unique"`
+}
+
+// dzhcore.Model 中已定义的列,自动建表时不存在会新增
+var baseModelColumns = []string{"id", "createTime", "updateTime"}
+
+// 生成模型时跳过的列
+var skipModelColumns = []string{"id", "createTime", "updateTime", "deleted_at", "deletedAt"}
+
+// 模糊搜索的常用字段
+var keyWordColumns = []string{"name", "title", "username", "userName", "nickName", "realName", "phone", "mobile", "email", "code", "no", "label", "keyword"}
+
+// 精确查询的常用字段
+var eqColumns = []string{"status", "type", "state", "level", "category"}
+
+// 根据数据库中已有的表生成模型、控制器和逻辑
+func generateFromTables(ctx context.Context, addonsCamel, module, tables string, allTables bool, group string) error {
+	if addonsCamel == "" && module != "" {
+		return fmt.Errorf("没有 addons 参数时，不能使用 module 参数")
+	}
+	modName, err := getModName()
+	if err != nil {
+		return err
+	}
+
+	// 在项目中读取表结构
+	output := gfile.Temp(fmt.Sprintf("dzhgo-table-%d.json", gtime.TimestampNano()))
+	defer gfile.Remove(output)
+	args := []string{"table", "inspect", "-o", output}
+	if allTables {
+		args = append(args, "-A")
+	} else {
+		args = append(args, "-n", tables)
+	}
+	if group != "" {
+		args = append(args, "-g", group)
+	}
+	if err = runProject(ctx, args...); err != nil {
+		return fmt.Errorf("读取表结构失败，项目主命令需要通过 corecmd.AddCommands 挂载 dzhcore 命令: %v", err)
+	}
+	var list []*tableInfo
+	if err = gjson.DecodeTo(gfile.GetBytes(output), &list); err != nil {
+		return fmt.Errorf("解析表结构失败: %v", err)
+	}
+	if len(list) == 0 {
+		fmt.Println("没有需要生成的表")
+		return nil
+	}
+	return generateTables(addonsCamel, module, modName, list)
+}
+
+// 按表结构生成模型、控制器和逻辑
+func generateTables(addonsCamel, module, modName string, list []*tableInfo) (err error) {
+	var (
+		basePath     = "internal"
+		importPrefix = modName + "/internal"
+		undersAddons = gstr.CaseSnakeFirstUpper(addonsCamel)
+		modules      = []string{"admin"}
+	)
+	if addonsCamel != "" {
+		basePath = filepath.Join("addons", undersAddons)
+		importPrefix = modName + "/addons/" + undersAddons
+		if err = generateAddonModule(addonsCamel, module, basePath, importPrefix); err != nil {
+			return err
+		}
+		switch module {
+		case "":
+			modules = []string{"admin", "app"}
+		default:
+			modules = []string{module}
+		}
+	}
+
+	for _, info := range list {
+		checkBaseModelColumns(info)
+		modelCamel := tableModelName(info.Name, undersAddons)
+		if err = generateModelFromTable(addonsCamel, modelCamel, basePath, info); err != nil {
+			return err
+		}
+		for _, m := range modules {
+			if err = generateControllerAtPath(addonsCamel, modelCamel, m, basePath, importPrefix); err != nil {
+				return err
+			}
+		}
+		spec := &logicSpec{}
+		spec.FieldEQ, spec.KeyWordField = queryFields(info)
+		if err = generateLogicSysAtPath(modelCamel, basePath, importPrefix, addonsCamel, spec); err != nil {
+			return err
+		}
+		fmt.Printf("表 %s 处理完成\n", info.Name)
+	}
+	return nil
+}
+
+// 提示表中缺少的 dzhcore.Model 列
+func checkBaseModelColumns(info *tableInfo) {
+	existing := make(map[string]bool)
+	for _, column := range info.Columns {
+		existing[column.Name] = true
+	}
+	for _, column := range baseModelColumns {
+		if !existing[column] {
+			fmt.Printf("表 %s 缺少 dzhcore.Model 的列 %s，自动建表时会新增\n", info.Name, column)
+		}
+	}
+}
+
+// 表名转模型名,去掉 addons_ 和插件名前缀
+func tableModelName(table, undersAddons string) string {
+	name := strings.TrimPrefix(table, "addons_")
+	if undersAddons != "" {
+		name = strings.TrimPrefix(name, undersAddons+"_")
+	}
+	return gstr.CaseCamelLower(name)
+}
+
+// 模型模板参数
+type modelData struct {
+	Name    string        // 模型名称,如 ShopOrder
+	Table   string        // 表名
+	Group   string        // 数据库分组
+	Comment string        // 表注释
+	UseTime bool          // 是否有 time.Time 类型的字段
+	Fields  []*modelField // 字段,不包括 dzhcore.Model 中的字段
+}
+
+type modelField struct {
+	Name    string       // 字段名
+	Type    string       // go 类型
+	Gorm    string       // gorm 标签
+	Json    string       // json 标签
+	Comment string       // 注释
+	Column  *tableColumn // 列结构
+}
+
+// 根据表结构生成模型
+func generateModelFromTable(addonsCamel, modelCamel, basePath string, info *tableInfo) error {
+	data, file, err := newModelData(addonsCamel, modelCamel, basePath, info)
+	if err != nil {
+		return err
+	}
+	for _, column := range info.Columns {
+		if isBaseModelColumn(column.Name) {
+			continue
+		}
+		field := &modelField{
+			Name:    gstr.CaseCamel(column.Name),
+			Type:    columnGoType(column),
+			Gorm:    columnGormTag(column, info.Indexes),
+			Json:    gstr.CaseCamelLower(column.Name),
+			Comment: column.Comment,
+			Column:  column,
+		}
+		if field.Comment == "" {
+			field.Comment = column.Name
+		}
+		if strings.Contains(field.Type, "time.Time") {
+			data.UseTime = true
+		}
+		data.Fields = append(data.Fields, field)
+	}
+	return generateFile("模型", file, "model.go.tmpl", data)
+}
+
+// 模型模板参数和模型文件,不包括字段
+func newModelData(addonsCamel, modelCamel, basePath string, info *tableInfo) (data *modelData, file string, err error) {
+	undersModelName := gstr.CaseSnakeFirstUpper(modelCamel)
+	if addonsCamel != "" {
+		undersModelName = gstr.CaseSnakeFirstUpper(addonsCamel) + "_" + undersModelName
+	}
+	modelDir := filepath.Join(basePath, "model")
+	if err = mkdirGen(modelDir); err != nil {
+		return nil, "", err
+	}
+	data = &modelData{
+		Name:    gstr.CaseCamel(undersModelName),
+		Table:   info.Name,
+		Group:   info.Group,
+		Comment: info.Comment,
+	}
+	if data.Group == "" {
+		data.Group = "default"
+	}
+	return data, filepath.Join(modelDir, undersModelName+".go"), nil
+}
+
+func isBaseModelColumn(name string) bool {
+	return containsFold(skipModelColumns, name)
+}
+
+// 列类型对应的 go 类型,可空的列使用指针
+func columnGoType(column *tableColumn) string {
+	var (
+		t      = strings.ToLower(column.Type)
+		goType string
+	)
+	switch {
+	case strings.Contains(t, "bigint"), t == "int8", t == "bigserial":
+		goType = "int64"
+	case t == "bool", t == "boolean":
+		goType = "bool"
+	case strings.Contains(t, "int"), strings.Contains(t, "serial"), t == "year":
+		goType = "int"
+	case strings.Contains(t, "float"), strings.Contains(t, "double"), t == "real",
+		strings.Contains(t, "decimal"), strings.Contains(t, "numeric"), t == "money":
+		goType = "float64"
+	case strings.Contains(t, "date"), strings.Contains(t, "timestamp"):
+		goType = "time.Time"
+	case strings.Contains(t, "blob"), strings.Contains(t, "binary"), t == "bytea":
+		return "[]byte"
+	default:
+		goType = "string"
+	}
+	if column.Nullable {
+		return "*" + goType
+	}
+	return goType
+}
+
+// 列的 gorm 标签
+func columnGormTag(column *tableColumn, indexes []*tableIndex) string {
+	tags := []string{"column:" + column.Name}
+	if column.Comment != "" {
+		tags = append(tags, "comment:"+strings.ReplaceAll(column.Comment, ";", "，"))
+	}
+	tags = append(tags, "type:"+column.ColumnType)
+	if column.PrimaryKey {
+		tags = append(tags, "primaryKey")
+	}
+	if column.AutoIncrement {
+		tags = append(tags, "autoIncrement")
+	}
+	if !column.Nullable && !column.PrimaryKey {
+		tags = append(tags, "not null")
+	}
+	if column.HasDefault && column.Default != "" && !strings.EqualFold(column.Default, "null") && !strings.ContainsAny(column.Default, ";\"`") {
+		tags = append(tags, "default:"+column.Default)
+	}
+	for _, index := range indexes {
+		for i, name := range index.Columns {
+			if name != column.Name {
+				continue
+			}
+			kind := "index"
+			if index.Unique {
+				kind = "uniqueIndex"
+			}
+			tag := kind + ":" + index.Name
+			if len(index.Columns) > 1 {
+				tag += fmt.Sprintf(",priority:%d", i+1)
+			}
+			tags = append(tags, tag)
+		}
+	}
+	return strings.Join(tags, ";")
+}
+
+// 根据列名和类型推断列表、分页的默认查询字段
+func queryFields(info *tableInfo) (fieldEQ, keyWordField []string) {
+	var texts []string
+	for _, column := range info.Columns {
+		if isBaseModelColumn(column.Name) {
+			continue
+		}
+		goType := strings.TrimPrefix(columnGoType(column), "*")
+		switch {
+		case goType == "string" && containsFold(keyWordColumns, column.Name):
+			keyWordField = append(keyWordField, column.Name)
+		case goType == "string" && !strings.Contains(column.Type, "text") && (column.Length == 0 || column.Length <= 255):
+			texts = append(texts, column.Name)
+		}
+		switch {
+		case containsFold(eqColumns, column.Name),
+			strings.HasSuffix(column.Name, "Id"), strings.HasSuffix(column.Name, "_id"),
+			goType == "bool":
+			fieldEQ = append(fieldEQ, column.Name)
+		}
+	}
+	// 没有常用字段时使用前两个短文本列
+	if len(keyWordField) == 0 && len(texts) > 0 {
+		keyWordField = texts[:min(len(texts), 2)]
+	}
+	return
+}
+
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// 字符串切片的代码,为空时为 []string{""}
+func stringsLiteral(list []string) string {
+	if len(list) == 0 {
+		return `[]string{""}`
+	}
+	return fmt.Sprintf(`[]string{"%s"}`, strings.Join(list, `", "`))
+}
+
+// 格式化生成的代码,失败时原样返回
+func formatSource(content string) string {
+	source, err := format.Source([]byte(content))
+	if err != nil {
+		return content
+	}
+	return string(source)
+}
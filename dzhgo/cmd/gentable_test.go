@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// 已有的订单表,包含可空列、默认值、单列和联合索引
+var shopOrderTable = &tableInfo{
+	Name:    "addons_shop_order",
+	Comment: "订单",
+	Columns: []*tableColumn{
+		{Name: "id", Type: "varchar", ColumnType: "varchar(255)", Length: 255, PrimaryKey: true},
+		{Name: "createTime", Type: "datetime", ColumnType: "datetime"},
+		{Name: "updateTime", Type: "datetime", ColumnType: "datetime"},
+		{Name: "orderNo", Type: "varchar", ColumnType: "varchar(50)", Length: 50, Comment: "订单号"},
+		{Name: "title", Type: "varchar", ColumnType: "varchar(255)", Length: 255, Nullable: true, Comment: "标题;备注"},
+		{Name: "userId", Type: "bigint", ColumnType: "bigint", Comment: "用户"},
+		{Name: "amount", Type: "decimal", ColumnType: "decimal(10,2)", HasDefault: true, Default: "0.00", Comment: "金额"},
+		{Name: "status", Type: "int", ColumnType: "int", HasDefault: true, Default: "0", Comment: "状态"},
+		{Name: "paid", Type: "boolean", ColumnType: "boolean", Nullable: true},
+		{Name: "payTime", Type: "datetime", ColumnType: "datetime", Nullable: true, Comment: "支付时间"},
+		{Name: "content", Type: "text", ColumnType: "text", Nullable: true, Comment: "内容"},
+		{Name: "deleted_at", Type: "datetime", ColumnType: "datetime", Nullable: true},
+	},
+	Indexes: []*tableIndex{
+		{Name: "idx_order_no", Columns: []string{"orderNo"}, Unique: true},
+		{Name: "idx_user_status", Columns: []string{"userId", "status"}},
+	},
+}
+
+// 按表结构生成的模型、控制器和逻辑与 testdata/table 一致
+func TestGenerateTablesGolden(t *testing.T) {
+	golden, err := filepath.Abs("testdata/table")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := chdirProject(t)
+	if err = generateTables("shop", "", "example.com/app", []*tableInfo{shopOrderTable}); err != nil {
+		t.Fatal(err)
+	}
+	checkGolden(t, golden, readTree(t, filepath.Join(dir, "addons")))
+}
+
+func TestColumnGormTag(t *testing.T) {
+	columns := make(map[string]*tableColumn)
+	for _, column := range shopOrderTable.Columns {
+		columns[column.Name] = column
+	}
+	for _, tc := range []struct {
+		column string
+		goType string
+		tag    string
+	}{
+		{"orderNo", "string", "column:orderNo;comment:订单号;type:varchar(50);not null;uniqueIndex:idx_order_no"},
+		{"title", "*string", "column:title;comment:标题，备注;type:varchar(255)"},
+		{"userId", "int64", "column:userId;comment:用户;type:bigint;not null;index:idx_user_status,priority:1"},
+		{"amount", "float64", "column:amount;comment:金额;type:decimal(10,2);not null;default:0.00"},
+		{"status", "int", "column:status;comment:状态;type:int;not null;default:0;index:idx_user_status,priority:2"},
+		{"paid", "*bool", "column:paid;type:boolean"},
+		{"payTime", "*time.Time", "column:payTime;comment:支付时间;type:datetime"},
+	} {
+		column := columns[tc.column]
+		if got := columnGoType(column); got != tc.goType {
+			t.Errorf("%s 类型 %s, 期望 %s", tc.column, got, tc.goType)
+		}
+		if got := columnGormTag(column, shopOrderTable.Indexes); got != tc.tag {
+			t.Errorf("%s 标签 %s, 期望 %s", tc.column, got, tc.tag)
+		}
+	}
+}
+
+// 常用字段名模糊搜索,状态、关联 id 和布尔列精确查询,没有常用字段时使用前两个短文本列
+func TestQueryFields(t *testing.T) {
+	fieldEQ, keyWordField := queryFields(shopOrderTable)
+	if got := strings.Join(fieldEQ, ","); got != "userId,status,paid" {
+		t.Errorf("FieldEQ %s, 期望 userId,status,paid", got)
+	}
+	if got := strings.Join(keyWordField, ","); got != "title" {
+		t.Errorf("KeyWordField %s, 期望 title", got)
+	}
+
+	fieldEQ, keyWordField = queryFields(&tableInfo{Columns: []*tableColumn{
+		{Name: "a", Type: "varchar", Length: 50},
+		{Name: "b", Type: "varchar", Length: 1000},
+		{Name: "c", Type: "text"},
+		{Name: "d", Type: "char", Length: 10},
+		{Name: "e", Type: "varchar", Length: 20},
+	}})
+	if len(fieldEQ) != 0 || strings.Join(keyWordField, ",") != "a,d" {
+		t.Errorf("FieldEQ %v KeyWordField %v, 期望 [] [a d]", fieldEQ, keyWordField)
+	}
+}
@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/gogf/gf/v2/os/gfile"
+	"github.com/gogf/gf/v2/text/gstr"
+)
+
+// 代码生成模板,项目中 .dzhgo/templates/<模板名> 存在时优先使用
+//
+//go:embed templates/gen
+var genTemplates embed.FS
+
+const (
+	genTemplateRoot    = "templates/gen"
+	genTemplateProject = ".dzhgo/templates"
+)
+
+// 代码生成选项,由 gen 命令的参数设置
+var genOptions struct {
+	DryRun bool // 只打印将要生成的文件和差异,不写入
+	Force  bool // 覆盖已存在的文件
+}
+
+// 模板中可用的函数
+var genTemplateFuncs = template.FuncMap{
+	"strings":    stringsLiteral,
+	"mapStrStr":  mapStrStrLiteral,
+	"rules":      rulesLiteral,
+	"camel":      gstr.CaseCamel,
+	"camelLower": gstr.CaseCamelLower,
+	"snake":      gstr.CaseSnake,
+	"ucFirst":    gstr.UcFirst,
+	"lcFirst":    gstr.LcFirst,
+}
+
+// 执行模板,name 为模板文件名,如 model.go.tmpl
+func renderTemplate(name string, data interface{}) (string, error) {
+	var (
+		content []byte
+		err     error
+		local   = filepath.Join(genTemplateProject, name)
+	)
+	if gfile.Exists(local) {
+		content = gfile.GetBytes(local)
+	} else if content, err = genTemplates.ReadFile(path.Join(genTemplateRoot, name)); err != nil {
+		return "", fmt.Errorf("模板 %s 不存在", name)
+	}
+	tpl, err := template.New(name).Funcs(genTemplateFuncs).Parse(string(content))
+	if err != nil {
+		return "", fmt.Errorf("解析模板 %s 失败: %v", name, err)
+	}
+	var buf bytes.Buffer
+	if err = tpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("执行模板 %s 失败: %v", name, err)
+	}
+	return buf.String(), nil
+}
+
+// 执行模板并写入文件,文件已存在时跳过,使用 --force 时覆盖
+func generateFile(kind, file, name string, data interface{}) error {
+	content, err := renderTemplate(name, data)
+	if err != nil {
+		return err
+	}
+	return putGenFile(kind, file, content, false)
+}
+
+// 写入生成的文件,update 为 true 时文件已存在也会修改
+// --dry-run 时只打印文件和与已有文件的差异
+func putGenFile(kind, file, content string, update bool) error {
+	if strings.HasSuffix(file, ".go") {
+		content = formatSource(content)
+	}
+	exists := gfile.Exists(file)
+	var old string
+	if exists {
+		old = gfile.GetContents(file)
+		if old == content {
+			fmt.Printf("%s未变化: %s\n", kind, file)
+			return nil
+		}
+		if !update && !genOptions.Force {
+			fmt.Printf("%s文件已存在: %s，使用 --force 覆盖\n", kind, file)
+			return nil
+		}
+	}
+
+	if genOptions.DryRun {
+		action := "新增"
+		if exists {
+			action = "覆盖"
+		}
+		fmt.Printf("[dry-run] %s%s: %s\n", action, kind, file)
+		fmt.Print(unifiedDiff(file, old, content))
+		return nil
+	}
+	if err := gfile.PutContents(file, content); err != nil {
+		return fmt.Errorf("写入%s文件失败: %s, 错误: %v", kind, file, err)
+	}
+	fmt.Printf("生成%s: %s\n", kind, file)
+	return nil
+}
+
+// 创建目录,--dry-run 时不创建
+func mkdirGen(dir string) error {
+	if genOptions.DryRun || gfile.Exists(dir) {
+		return nil
+	}
+	if err := gfile.Mkdir(dir); err != nil {
+		return fmt.Errorf("创建目录失败: %s, 错误: %v", dir, err)
+	}
+	return nil
+}
+
+// 导出默认模板到 .dzhgo/templates,已存在的模板不覆盖,使用 --force 时覆盖
+func exportTemplates() error {
+	return fs.WalkDir(genTemplates, genTemplateRoot, func(file string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		content, err := genTemplates.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(genTemplateProject, strings.TrimPrefix(file, genTemplateRoot+"/"))
+		return putGenFile("模板", target, string(content), false)
+	})
+}
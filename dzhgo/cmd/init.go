@@ -1,167 +1,264 @@
 package cmd
 
 import (
+	"bufio"
+	"bytes"
 	"context"
+	"embed"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
+	"text/template"
 
 	"github.com/gogf/gf/v2/os/gcmd"
 	"github.com/gogf/gf/v2/os/gfile"
+	"github.com/gogf/gf/v2/util/grand"
+	"github.com/gzdzh-cn/dzhcore/dzhgo/config"
 )
 
+// 项目模板,文件路径去掉 .tmpl 后即为生成的文件路径
+//
+//go:embed all:templates/init
+var initTemplates embed.FS
+
+const initTemplateRoot = "templates/init"
+
 var (
 	// InitProject 初始化项目命令
 	InitProject = &gcmd.Command{
 		Name:  "init",
 		Usage: "init",
-		Brief: "初始化项目结构",
+		Brief: "初始化 dzhcore 项目",
 		Func:  initProjectFunc,
 		Arguments: []gcmd.Argument{
 			{
 				Name:  "name",
 				Short: "n",
-				Brief: "项目名称，例如: myproject",
+				Brief: "项目名称，同时作为 go.mod 的 module 名称，例如: myproject",
 			},
 			{
 				Name:  "path",
 				Short: "p",
 				Brief: "项目路径，默认为当前目录",
 			},
+			{
+				Name:  "db",
+				Short: "d",
+				Brief: "数据库类型 sqlite、mysql、pgsql，默认 sqlite",
+			},
+			{
+				Name:   "redis",
+				Short:  "r",
+				Brief:  "开启 redis 缓存",
+				Orphan: true,
+			},
+			{
+				Name:   "desktop",
+				Short:  "D",
+				Brief:  "桌面端模式，生产环境 sqlite 数据库和上传文件保存到系统的应用数据目录",
+				Orphan: true,
+			},
+			{
+				Name:  "project",
+				Brief: "项目名称，位置参数，与 name 相同",
+				IsArg: true,
+			},
+			{
+				Name:  "project-path",
+				Brief: "项目路径，位置参数，与 path 相同",
+				IsArg: true,
+			},
+			{
+				Name:   "interactive",
+				Short:  "i",
+				Brief:  "交互模式，逐项输入项目配置",
+				Orphan: true,
+			},
 		},
 	}
 )
 
+// 项目模板参数
+type projectOptions struct {
+	Name        string // 项目名称,即 go.mod 的 module 名称
+	AppName     string // 应用名称,module 名称的最后一段
+	DB          string // 数据库类型
+	DBHost      string
+	DBPort      string
+	DBUser      string
+	DBPass      string
+	DBName      string
+	Redis       bool   // 开启 redis
+	Desktop     bool   // 桌面端模式
+	JwtSecret   string // jwt 密钥,写入 .env
+	GoVersion   string
+	GfVersion   string
+	CoreVersion string // dzhcore 版本
+}
+
+// 数据库默认端口
+var dbPorts = map[string]string{
+	"mysql": "3306",
+	"pgsql": "5432",
+}
+
 // 初始化项目执行函数
 func initProjectFunc(ctx context.Context, parser *gcmd.Parser) (err error) {
-	// 获取参数
-	name := parser.GetOpt("name").String()
-	if name == "" {
-		name = parser.GetArg(1).String()
+	opts := &projectOptions{
+		Name:        parser.GetOpt("name").String(),
+		DB:          parser.GetOpt("db", "sqlite").String(),
+		Redis:       parser.GetOpt("redis") != nil,
+		Desktop:     parser.GetOpt("desktop") != nil,
+		JwtSecret:   grand.S(32),
+		GoVersion:   config.GoVersion,
+		GfVersion:   config.GfVersion,
+		CoreVersion: config.CoreVersion,
 	}
-	if name == "" {
-		return fmt.Errorf("请提供项目名称，使用 -n 或 --name 参数")
+	if opts.Name == "" {
+		opts.Name = parser.GetArg(2).String()
 	}
 
 	// 获取项目路径
 	path := parser.GetOpt("path").String()
 	if path == "" {
-		path = parser.GetArg(2).String()
+		path = parser.GetArg(3).String()
 	}
 	if path == "" {
 		path = "." // 默认是当前目录
 	}
 
-	projectPath := filepath.Join(path, name)
+	// 没有项目名称时进入交互模式
+	if parser.GetOpt("interactive") != nil || opts.Name == "" {
+		promptProjectOptions(opts)
+	}
+	if opts.Name == "" {
+		return fmt.Errorf("请提供项目名称，使用 -n 或 --name 参数")
+	}
+	opts.AppName = filepath.Base(opts.Name)
+	if _, ok := dbPorts[opts.DB]; !ok && opts.DB != "sqlite" {
+		return fmt.Errorf("不支持的数据库类型: %s，可选 sqlite、mysql、pgsql", opts.DB)
+	}
+	if opts.DB != "sqlite" {
+		if opts.DBHost == "" {
+			opts.DBHost = "127.0.0.1"
+		}
+		if opts.DBPort == "" {
+			opts.DBPort = dbPorts[opts.DB]
+		}
+		if opts.DBUser == "" {
+			opts.DBUser = "root"
+			if opts.DB == "pgsql" {
+				opts.DBUser = "postgres"
+			}
+		}
+		if opts.DBName == "" {
+			opts.DBName = strings.ReplaceAll(opts.AppName, "-", "_")
+		}
+	}
+
+	projectPath := filepath.Join(path, opts.AppName)
 
 	// 检查目录是否已存在
 	if gfile.Exists(projectPath) {
 		return fmt.Errorf("项目目录已存在: %s", projectPath)
 	}
 
-	// 创建项目目录
-	fmt.Printf("正在创建项目: %s, 路径: %s\n", name, projectPath)
-
-	// 创建项目基本目录结构
-	dirs := []string{
-		filepath.Join(projectPath, "internal", "controller", "admin"),
-		filepath.Join(projectPath, "internal", "controller", "app"),
-		filepath.Join(projectPath, "internal", "model", "entity"),
-		filepath.Join(projectPath, "internal", "service"),
-		filepath.Join(projectPath, "internal", "logic", "admin"),
-		filepath.Join(projectPath, "internal", "logic", "app"),
-		filepath.Join(projectPath, "cmd"),
-		filepath.Join(projectPath, "api"),
+	fmt.Printf("正在创建项目: %s, 路径: %s\n", opts.Name, projectPath)
+	if err = writeProjectTemplates(projectPath, opts); err != nil {
+		return err
 	}
-
-	for _, dir := range dirs {
-		if err := os.MkdirAll(dir, 0755); err != nil {
-			return fmt.Errorf("创建目录失败: %s, 错误: %v", dir, err)
+	// sqlite 数据库目录
+	if opts.DB == "sqlite" {
+		if err = gfile.Mkdir(filepath.Join(projectPath, "data", "database")); err != nil {
+			return fmt.Errorf("创建目录失败: %v", err)
 		}
 	}
 
-	// 创建基本文件
-	files := map[string]string{
-		filepath.Join(projectPath, "main.go"): `package main
-
-import (
-	"{{.Name}}/cmd"
-	"github.com/gogf/gf/v2/os/gctx"
-)
-
-func main() {
-	ctx := gctx.New()
-	cmd.Main.Run(ctx)
-}`,
-
-		filepath.Join(projectPath, "go.mod"): `module {{.Name}}
-
-go 1.20
-
-require (
-	github.com/gogf/gf/v2 v2.5.7
-)`,
-
-		filepath.Join(projectPath, "cmd", "cmd.go"): `package cmd
-
-import (
-	"context"
-	"{{.Name}}/internal"
-	
-	"github.com/gogf/gf/v2/frame/g"
-	"github.com/gogf/gf/v2/os/gcmd"
-)
+	fmt.Println("项目创建完成!")
+	fmt.Printf("数据库: %s, redis: %v, 桌面端: %v\n", opts.DB, opts.Redis, opts.Desktop)
+	fmt.Printf("可以通过以下命令运行项目:\n")
+	fmt.Printf("cd %s\n", projectPath)
+	fmt.Printf("go mod tidy\n")
+	fmt.Printf("go run .\n")
+	return nil
+}
 
-var (
-	Main = gcmd.Command{
-		Name:  "main",
-		Usage: "main",
-		Brief: "start http server",
-		Func: func(ctx context.Context, parser *gcmd.Parser) (err error) {
-			// 初始化
-			internal.Init()
-			
-			// 启动服务器
-			s := g.Server()
-			s.Run()
-			
-			return nil
-		},
+// 交互模式输入项目配置,直接回车使用默认值
+func promptProjectOptions(opts *projectOptions) {
+	opts.Name = prompt("项目名称", opts.Name)
+	opts.DB = prompt("数据库类型 sqlite/mysql/pgsql", opts.DB)
+	if port, ok := dbPorts[opts.DB]; ok {
+		opts.DBHost = prompt("数据库地址", "127.0.0.1")
+		opts.DBPort = prompt("数据库端口", port)
+		opts.DBUser = prompt("数据库用户", opts.DBUser)
+		opts.DBPass = prompt("数据库密码", "")
+		opts.DBName = prompt("数据库名称", strings.ReplaceAll(filepath.Base(opts.Name), "-", "_"))
 	}
-)`,
-
-		filepath.Join(projectPath, "internal", "init.go"): `package internal
+	opts.Redis = confirm("开启 redis 缓存", opts.Redis)
+	opts.Desktop = confirm("桌面端模式", opts.Desktop)
+}
 
-import (
-	"context"
-	"github.com/gogf/gf/v2/frame/g"
-)
+// 交互输入共用一个 reader,输入来自管道时不会丢失缓冲的内容
+var stdin = bufio.NewReader(os.Stdin)
 
-// Init 初始化应用
-func Init() {
-	ctx := context.Background()
-	g.Log().Info(ctx, "应用初始化...")
-}`,
+func readline(format string, args ...interface{}) string {
+	fmt.Printf(format, args...)
+	line, _ := stdin.ReadString('\n')
+	return strings.TrimSpace(line)
+}
 
-		filepath.Join(projectPath, "README.md"): "# {{.Name}}\n\n基于 GoFrame 的项目\n\n## 目录结构\n\n- cmd: 命令行入口\n- internal: 内部代码\n  - controller: 控制器\n  - model: 数据模型\n  - service: 服务接口\n  - logic: 业务逻辑\n- api: API文档\n\n## 运行项目\n\n```bash\ngo run main.go\n```",
+func prompt(label, def string) string {
+	if def != "" {
+		label = fmt.Sprintf("%s [%s]", label, def)
 	}
-
-	// 写入文件
-	for filePath, content := range files {
-		// 替换模板变量
-		content = strings.Replace(content, "{{.Name}}", name, -1)
-
-		if err := gfile.PutContents(filePath, content); err != nil {
-			return fmt.Errorf("写入文件失败: %s, 错误: %v", filePath, err)
-		}
+	if value := readline("%s: ", label); value != "" {
+		return value
 	}
+	return def
+}
 
-	fmt.Println("项目创建完成!")
-	fmt.Printf("可以通过以下命令运行项目:\n")
-	fmt.Printf("cd %s\n", projectPath)
-	fmt.Printf("go mod tidy\n")
-	fmt.Printf("go run main.go\n")
+func confirm(label string, def bool) bool {
+	value := "y/N"
+	if def {
+		value = "Y/n"
+	}
+	switch strings.ToLower(readline("%s (%s): ", label, value)) {
+	case "y", "yes":
+		return true
+	case "n", "no":
+		return false
+	}
+	return def
+}
 
-	return nil
+// 按模板生成项目文件
+func writeProjectTemplates(projectPath string, opts *projectOptions) error {
+	return fs.WalkDir(initTemplates, initTemplateRoot, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		content, err := initTemplates.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		tpl, err := template.New(path).Parse(string(content))
+		if err != nil {
+			return fmt.Errorf("解析模板 %s 失败: %v", path, err)
+		}
+		var buf bytes.Buffer
+		if err = tpl.Execute(&buf, opts); err != nil {
+			return fmt.Errorf("执行模板 %s 失败: %v", path, err)
+		}
+		target := filepath.Join(projectPath, strings.TrimSuffix(strings.TrimPrefix(path, initTemplateRoot+"/"), ".tmpl"))
+		output := buf.String()
+		if strings.HasSuffix(target, ".go") {
+			output = formatSource(output)
+		}
+		if err = gfile.PutContents(target, output); err != nil {
+			return fmt.Errorf("写入文件失败: %s, 错误: %v", target, err)
+		}
+		return nil
+	})
 }
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gogf/gf/v2/encoding/gyaml"
+	"github.com/gogf/gf/v2/frame/g"
+)
+
+func testProjectOptions(db string) *projectOptions {
+	return &projectOptions{
+		Name:        "example.com/demo",
+		AppName:     "demo",
+		DB:          db,
+		JwtSecret:   "secret",
+		GoVersion:   "1.24",
+		GfVersion:   "v2.9.0",
+		CoreVersion: "v1.0.0",
+	}
+}
+
+// 默认 sqlite 项目与 testdata/init 一致
+func TestWriteProjectTemplatesGolden(t *testing.T) {
+	golden, err := filepath.Abs("testdata/init")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	if err = writeProjectTemplates(dir, testProjectOptions("sqlite")); err != nil {
+		t.Fatal(err)
+	}
+	checkGolden(t, golden, readTree(t, dir))
+}
+
+// 各模板选项生成的 go 文件可以解析,配置文件是合法的 yaml
+func TestWriteProjectTemplatesOptions(t *testing.T) {
+	for _, tc := range []struct {
+		db      string
+		redis   bool
+		desktop bool
+	}{
+		{"sqlite", false, true},
+		{"mysql", true, false},
+		{"pgsql", false, false},
+	} {
+		opts := testProjectOptions(tc.db)
+		opts.Redis, opts.Desktop = tc.redis, tc.desktop
+		if tc.db != "sqlite" {
+			opts.DBHost, opts.DBPort, opts.DBUser, opts.DBName = "127.0.0.1", dbPorts[tc.db], "root", "demo"
+		}
+		dir := t.TempDir()
+		if err := writeProjectTemplates(dir, opts); err != nil {
+			t.Fatal(err)
+		}
+		files := readTree(t, dir)
+		for name, content := range files {
+			if !strings.HasSuffix(name, ".go") {
+				continue
+			}
+			if _, err := parser.ParseFile(token.NewFileSet(), name, content, 0); err != nil {
+				t.Errorf("%s %s: %v", tc.db, name, err)
+			}
+		}
+
+		var cfg g.Map
+		if err := gyaml.DecodeTo([]byte(files["manifest/config/config.yaml"]), &cfg); err != nil {
+			t.Fatalf("%s config.yaml: %v", tc.db, err)
+		}
+		v := g.NewVar(cfg).MapStrVar()
+		database := g.NewVar(v["database"].Map()["default"]).MapStrVar()
+		if database["type"].String() != tc.db {
+			t.Errorf("%s database.default.type %s", tc.db, database["type"])
+		}
+		if tc.db != "sqlite" && database["port"].String() != dbPorts[tc.db] {
+			t.Errorf("%s database.default.port %s", tc.db, database["port"])
+		}
+		if enable := g.NewVar(v["redis"].Map()["enable"]).Bool(); enable != tc.redis {
+			t.Errorf("%s redis.enable %v", tc.db, enable)
+		}
+		if desktop := g.NewVar(v["core"].Map()["isDesktop"]).Bool(); desktop != tc.desktop {
+			t.Errorf("%s core.isDesktop %v", tc.db, desktop)
+		}
+
+		goMod := files["go.mod"]
+		if !strings.Contains(goMod, "github.com/gogf/gf/contrib/drivers/"+tc.db+"/v2 v2.9.0") {
+			t.Errorf("%s go.mod 没有数据库驱动:\n%s", tc.db, goMod)
+		}
+		if strings.Contains(goMod, "nosql/redis") != tc.redis {
+			t.Errorf("%s go.mod redis 依赖:\n%s", tc.db, goMod)
+		}
+	}
+}
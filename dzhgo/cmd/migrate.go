@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gogf/gf/v2/os/gcmd"
+	"github.com/gogf/gf/v2/os/gfile"
+)
+
+var (
+	// Migrate 数据库迁移命令
+	Migrate = &gcmd.Command{
+		Name:  "migrate",
+		Usage: "migrate up|down|status|new",
+		Brief: "数据库迁移",
+	}
+
+	migrateUp = &gcmd.Command{
+		Name:  "up",
+		Usage: "migrate up [-n 步数]",
+		Brief: "执行未执行的迁移",
+		Arguments: []gcmd.Argument{
+			{Name: "steps", Short: "n", Brief: "最多执行的迁移个数，默认全部"},
+		},
+		Func: func(ctx context.Context, parser *gcmd.Parser) (err error) {
+			return proxyProject(ctx)
+		},
+	}
+
+	migrateDown = &gcmd.Command{
+		Name:  "down",
+		Usage: "migrate down [-n 步数]",
+		Brief: "回滚最近执行的迁移",
+		Arguments: []gcmd.Argument{
+			{Name: "steps", Short: "n", Brief: "回滚的迁移个数，默认 1"},
+		},
+		Func: func(ctx context.Context, parser *gcmd.Parser) (err error) {
+			return proxyProject(ctx)
+		},
+	}
+
+	migrateStatus = &gcmd.Command{
+		Name:  "status",
+		Usage: "migrate status",
+		Brief: "查看迁移执行状态",
+		Func: func(ctx context.Context, parser *gcmd.Parser) (err error) {
+			return proxyProject(ctx)
+		},
+	}
+
+	migrateNew = &gcmd.Command{
+		Name:  "new",
+		Usage: "migrate new NAME [-a addons] [-s]",
+		Brief: "创建迁移文件",
+		Func:  migrateNewFunc,
+		Arguments: []gcmd.Argument{
+			{Name: "name", IsArg: true, Brief: "迁移名称，只能包含小写字母、数字和下划线，例如: create_user"},
+			{Name: "addons", Short: "a", Brief: "创建到 addons 插件的 migration 目录，不填时创建到 internal/migration"},
+			{Name: "sql", Short: "s", Brief: "创建 sql 迁移文件，默认创建 Go 迁移文件", Orphan: true},
+		},
+	}
+
+	migrationNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+)
+
+// Go 迁移模板
+const migrationGoTemplate = `package migration
+
+import (
+	"context"
+
+	"github.com/gogf/gf/v2/database/gdb"
+	"github.com/gzdzh-cn/dzhcore"
+)
+
+func init() {
+	dzhcore.AddMigration(&dzhcore.Migration{
+		Version: "{{Version}}",
+		Module:  "{{Module}}",
+		Up: func(ctx context.Context, tx gdb.TX) error {
+			return nil
+		},
+		Down: func(ctx context.Context, tx gdb.TX) error {
+			return nil
+		},
+	})
+}
+`
+
+// sql 迁移注册模板
+const migrationEmbedTemplate = `package migration
+
+import (
+	"embed"
+
+	"github.com/gzdzh-cn/dzhcore"
+)
+
+//go:embed *.sql
+var sqlFiles embed.FS
+
+func init() {
+	if err := dzhcore.AddMigrationFS("{{Module}}", sqlFiles, "."); err != nil {
+		panic(err)
+	}
+}
+`
+
+// 创建迁移文件
+func migrateNewFunc(ctx context.Context, parser *gcmd.Parser) (err error) {
+	name := parser.GetArg(3).String()
+	if name == "" {
+		return fmt.Errorf("请提供迁移名称，例如: dzhgo migrate new create_user")
+	}
+	if !migrationNamePattern.MatchString(name) {
+		return fmt.Errorf("迁移名称只能包含小写字母、数字和下划线，并以字母开头: %s", name)
+	}
+
+	var (
+		addons  = parser.GetOpt("addons").String()
+		isSql   = parser.GetOpt("sql") != nil
+		module  = "base"
+		dir     = filepath.Join("internal", "migration")
+		version = time.Now().Format("20060102150405") + "_" + name
+		replace = func(content string) string {
+			return strings.NewReplacer("{{Version}}", version, "{{Module}}", module).Replace(content)
+		}
+	)
+	if addons != "" {
+		module = addons
+		dir = filepath.Join("addons", addons, "migration")
+	}
+
+	files := map[string]string{}
+	if isSql {
+		files[filepath.Join(dir, version+".up.sql")] = "-- " + version + " up\n"
+		files[filepath.Join(dir, version+".down.sql")] = "-- " + version + " down\n"
+		if embedFile := filepath.Join(dir, "migration.go"); !gfile.Exists(embedFile) {
+			files[embedFile] = replace(migrationEmbedTemplate)
+		}
+	} else {
+		files[filepath.Join(dir, version+".go")] = replace(migrationGoTemplate)
+	}
+
+	for filePath, content := range files {
+		if gfile.Exists(filePath) {
+			return fmt.Errorf("文件已存在: %s", filePath)
+		}
+		if err = gfile.PutContents(filePath, content); err != nil {
+			return fmt.Errorf("写入文件失败: %s, 错误: %v", filePath, err)
+		}
+		fmt.Printf("已创建: %s\n", filePath)
+	}
+	fmt.Printf("请确认已在模块中导入迁移包: _ \"<module>/%s\"\n", filepath.ToSlash(dir))
+	return nil
+}
+
+func init() {
+	if err := Migrate.AddCommand(migrateUp, migrateDown, migrateStatus, migrateNew); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/gogf/gf/v2/os/gfile"
+)
+
+// 在当前项目中执行 go run . <args>
+// 项目主命令需要通过 corecmd.AddCommands 挂载 dzhcore 命令
+func runProject(ctx context.Context, args ...string) error {
+	if !gfile.Exists("go.mod") {
+		return fmt.Errorf("当前目录不是项目根目录，未找到 go.mod")
+	}
+	command := exec.CommandContext(ctx, "go", append([]string{"run", "."}, args...)...)
+	command.Stdin = os.Stdin
+	command.Stdout = os.Stdout
+	command.Stderr = os.Stderr
+	return command.Run()
+}
+
+// 转发当前命令到项目中执行
+func proxyProject(ctx context.Context) error {
+	return runProject(ctx, os.Args[1:]...)
+}
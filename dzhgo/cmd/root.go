@@ -23,24 +23,31 @@ DzhGO 代码生成工具，用于快速生成控制器、模型和服务代码
 ## 命令说明
 
 ### init 命令 - 初始化项目结构
-用于快速创建一个基于 GoFrame 的项目基础结构。
+用于创建一个可以直接启动的 dzhcore 项目。
 
 **使用示例：**
 1. 在当前目录创建项目: dzhgo init -n myproject
 2. 在指定路径创建项目: dzhgo init -n myproject -p /path/to/project
 3. 使用位置参数: dzhgo init myproject
+4. 使用 mysql 并开启 redis: dzhgo init -n myproject -d mysql -r
+5. 桌面端项目: dzhgo init -n myproject -D
+6. 交互模式: dzhgo init -i
 
 **参数说明：**
-- -n, --name: 项目名称（必填）
+- -n, --name: 项目名称，同时作为 go.mod 的 module 名称（必填，交互模式中输入）
 - -p, --path: 项目路径（可选，默认为当前目录）
+- -d, --db: 数据库类型 sqlite、mysql、pgsql（可选，默认 sqlite）
+- -r, --redis: 开启 redis 缓存
+- -D, --desktop: 桌面端模式
+- -i, --interactive: 交互模式，没有项目名称时也会进入交互模式
 
 **生成内容：**
-- 完整的项目目录结构
-- main.go 入口文件
-- go.mod 模块文件
-- cmd/cmd.go 命令行入口
-- internal/init.go 初始化文件
-- README.md 项目说明文档
+- go.mod 依赖 dzhcore 和对应的数据库驱动
+- main.go 入口文件，cmd/cmd.go 调用 dzhcore.NewInit() 启动服务并挂载 dzhcore 命令
+- manifest/config/config.yaml 配置文件，.env 本地环境变量
+- internal 模块目录和 internal/resource/initjson 初始数据目录
+- addons/addons.go 插件导入文件，dzhgo gen -a 生成插件时自动导入
+- sqlite 数据库目录 data/database
 
 ### gen 命令 - 生成代码文件
 用于生成控制器、模型、逻辑等代码文件。
@@ -61,12 +68,89 @@ DzhGO 代码生成工具，用于快速生成控制器、模型和服务代码
 13. 只指定 addons 和 controller，同时生成 admin 和 app: dzhgo gen -a user -C comm
 14. 只指定 addons 和 model，生成模型: dzhgo gen -a user -M model
 15. 只指定 addons 和 logic，生成逻辑: dzhgo gen -a user -L logic
+16. 根据已有的表生成模型、控制器和逻辑: dzhgo gen -t base_sys_user,base_sys_role
+17. 根据全部业务表生成到 addons 中: dzhgo gen -a shop -A
+18. 根据字段定义生成完整的增删改查模块: dzhgo gen -a shop -M order -C order -L order -f "name:string:required:unique,price:decimal(10,2),status:int:dict=order_status"
+19. 预览生成的文件和差异，不写入: dzhgo gen -a shop -M order --dry-run --force
+20. 导出默认模板到 .dzhgo/templates 后修改: dzhgo gen --export-templates
 
 **使用规则：**
 - 有 addons 参数时，name 和 module 参数可以搭配使用，如果name为空且没有指定特定的生成参数则用addons名称，如果module为空则同时生成admin和app
 - 有 addons 参数时，可以只指定 addons 和 controller/model/logic，分别生成对应的文件
 - 没有 addons 参数时，只能使用 model、controller 或 logic 参数生成 internal 下的文件
 - model、controller、logic 可以单独使用，只生成对应的逻辑模板
+- from-table、all-tables 在项目中读取表结构，项目主命令需要通过 corecmd.AddCommands 挂载 dzhcore 命令
+- fields 与 model、logic 配合使用，生成的逻辑中包含非空键、唯一键、校验规则和字典字段
+- 已存在的文件默认跳过，使用 --force 覆盖；项目中 .dzhgo/templates 下的模板优先于内置模板
+
+### migrate 命令 - 数据库迁移
+up、down、status 在当前项目中通过 go run . 执行，项目主命令需通过 corecmd.AddCommands 挂载 dzhcore 命令。
+
+**使用示例：**
+1. 执行全部未执行的迁移: dzhgo migrate up
+2. 回滚最近 2 个迁移: dzhgo migrate down -n 2
+3. 查看迁移状态: dzhgo migrate status
+4. 创建 Go 迁移到 internal/migration: dzhgo migrate new create_user
+5. 创建 sql 迁移到 addons/shop/migration: dzhgo migrate new add_order_index -a shop -s
+
+### schema 命令 - 表结构对比
+在当前项目中通过 go run . 执行，对比已注册模型与数据库表结构，只读取不修改。
+
+**使用示例：**
+1. 查看模型与数据库的差异: dzhgo schema diff
+2. 部署前检查，存在破坏性差异时返回错误: dzhgo schema diff -s
+
+### seed 命令 - 种子数据
+在当前项目中通过 go run . 执行，按唯一键新增或更新种子数据，版本变化后重新执行。
+
+**使用示例：**
+1. 执行版本变化的种子数据: dzhgo seed apply
+2. 重新执行指定的种子数据: dzhgo seed apply -n base/base_sys_menu -f
+3. 查看种子数据状态: dzhgo seed status
+4. 导出表中的数据到插件的 resource/initjson: dzhgo seed export -t addons_shop_category -w "status = 1" -a shop
+5. 在其他环境导入: dzhgo seed import -i addons_shop_category.json
+
+### eps 命令 - 前端接口类型
+根据控制器开启的接口和模型的列生成 TypeScript 类型和请求方法，默认在当前项目中通过 go run . eps 读取。
+
+**使用示例：**
+1. 生成到 eps 目录: dzhgo eps
+2. 生成到前端项目: dzhgo eps -o ../admin-vue/src/eps
+3. 从运行中的服务读取: dzhgo eps -u http://127.0.0.1:8000/admin/core/eps -t "Bearer xxx"
+4. 从导出的 json 文件读取: dzhgo eps -i eps.json
+
+### doctor 命令 - 检查配置和运行环境
+检查 Go 环境和项目依赖，再在项目中通过 go run . doctor 检查配置项、数据库、redis、文件上传和目录权限，存在失败项时退出码为 1。
+
+**使用示例：**
+1. 检查: dzhgo doctor
+2. 在 CI 中使用，存在警告也返回错误: dzhgo doctor -s -o doctor.json
+
+### config 命令 - 查看配置
+配置项依次从配置文件、环境变量（含 .env）、coreconfig 中的默认值读取，在项目中通过 go run . config 执行。
+
+**使用示例：**
+1. 查看生效的配置和来源: dzhgo config show
+2. 只查看文件上传的配置: dzhgo config show -k core.file
+3. 生成 JSON Schema 到 manifest/config/config.schema.json: dzhgo config schema
+
+### addon 命令 - 插件打包和安装
+打包 addons 下的插件为 zip，安装时解压到 addons 目录、替换导入路径、导入到 addons/addons.go 并执行迁移。
+
+**使用示例：**
+1. 打包插件: dzhgo addon pack shop
+2. 安装插件压缩包: dzhgo addon install shop-v1.0.0.zip
+3. 从 git 仓库安装指定标签: dzhgo addon install https://github.com/xxx/shop.git -b v1.0.0
+4. 查看项目中的插件: dzhgo addon list
+5. 删除插件: dzhgo addon remove shop
+
+### fake 命令 - 模拟数据
+按模型的结构和列注释生成模拟数据并分批插入，在当前项目中通过 go run . fake 执行。
+
+**使用示例：**
+1. 生成 10000 条: dzhgo fake --model ShopOrder --count 10000
+2. 先生成被关联的模型: dzhgo fake -m addons_shop_customer,addons_shop_invoice -n 1000
+3. 使用固定的随机数种子: dzhgo fake -m ShopOrder -n 100 -s 42
 
 `, config.Version),
 		Additional: fmt.Sprintf(`
@@ -93,5 +177,13 @@ func init() {
 	// 添加所有命令到根命令
 	Root.AddCommand(GenCode)
 	Root.AddCommand(InitProject)
+	Root.AddCommand(Migrate)
+	Root.AddCommand(Schema)
+	Root.AddCommand(Seed)
+	Root.AddCommand(Eps)
+	Root.AddCommand(Doctor)
+	Root.AddCommand(ConfigCmd)
+	Root.AddCommand(Addon)
+	Root.AddCommand(Fake)
 	Root.AddCommand(VersionCmd)
 }
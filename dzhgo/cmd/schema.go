@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/gogf/gf/v2/os/gcmd"
+)
+
+var (
+	// Schema 表结构命令
+	Schema = &gcmd.Command{
+		Name:  "schema",
+		Usage: "schema diff",
+		Brief: "表结构工具",
+	}
+
+	schemaDiff = &gcmd.Command{
+		Name:  "diff",
+		Usage: "schema diff [-s]",
+		Brief: "对比已注册模型与数据库表结构，只读取不修改",
+		Arguments: []gcmd.Argument{
+			{Name: "strict", Short: "s", Brief: "存在破坏性差异时返回错误，用于部署前检查", Orphan: true},
+		},
+		Func: func(ctx context.Context, parser *gcmd.Parser) (err error) {
+			return proxyProject(ctx)
+		},
+	}
+)
+
+func init() {
+	if err := Schema.AddCommand(schemaDiff); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/gogf/gf/v2/os/gcmd"
+)
+
+var (
+	// Seed 种子数据命令
+	Seed = &gcmd.Command{
+		Name:  "seed",
+		Usage: "seed apply|status|export|import",
+		Brief: "种子数据",
+	}
+
+	seedApply = &gcmd.Command{
+		Name:  "apply",
+		Usage: "seed apply [-n 名称] [-f]",
+		Brief: "执行版本变化的种子数据",
+		Arguments: []gcmd.Argument{
+			{Name: "name", Short: "n", Brief: "只执行指定的种子数据，多个用逗号分隔，格式为 名称 或 模块/名称"},
+			{Name: "force", Short: "f", Brief: "忽略版本号重新执行,按种子数据全部覆盖", Orphan: true},
+		},
+		Func: func(ctx context.Context, parser *gcmd.Parser) (err error) {
+			return proxyProject(ctx)
+		},
+	}
+
+	seedStatus = &gcmd.Command{
+		Name:  "status",
+		Usage: "seed status",
+		Brief: "查看种子数据执行状态",
+		Func: func(ctx context.Context, parser *gcmd.Parser) (err error) {
+			return proxyProject(ctx)
+		},
+	}
+
+	seedExport = &gcmd.Command{
+		Name:  "export",
+		Usage: "seed export -t 表名 [-w 条件] [-a 插件] [-o 文件] [-e 列] [-g 分组] [--keep-time]",
+		Brief: "导出表中的数据为种子数据文件，默认写入 internal/resource/initjson/<表名>.json",
+		Arguments: []gcmd.Argument{
+			{Name: "table", Short: "t", Brief: "表名"},
+			{Name: "where", Short: "w", Brief: "条件，如 \"status = 1\""},
+			{Name: "addons", Short: "a", Brief: "写入插件的 resource/initjson 目录"},
+			{Name: "output", Short: "o", Brief: "写入的文件，优先于 -a"},
+			{Name: "exclude", Short: "e", Brief: "不导出的列，多个用逗号分隔"},
+			{Name: "order", Brief: "排序，默认按 id"},
+			{Name: "group", Short: "g", Brief: "数据库分组，默认 default"},
+			{Name: "keep-time", Brief: "同时导出创建、更新、删除时间列", Orphan: true},
+		},
+		Func: func(ctx context.Context, parser *gcmd.Parser) (err error) {
+			return proxyProject(ctx)
+		},
+	}
+
+	seedImport = &gcmd.Command{
+		Name:  "import",
+		Usage: "seed import -i 文件 [-t 表名] [-k 唯一键] [-g 分组]",
+		Brief: "导入种子数据文件，按唯一键新增或更新，不写入执行记录",
+		Arguments: []gcmd.Argument{
+			{Name: "input", Short: "i", Brief: "数据文件，支持 json、yaml、yml、csv"},
+			{Name: "table", Short: "t", Brief: "表名，默认为文件名"},
+			{Name: "keys", Short: "k", Brief: "唯一键，多个用逗号分隔，默认 id"},
+			{Name: "group", Short: "g", Brief: "数据库分组，默认 default"},
+		},
+		Func: func(ctx context.Context, parser *gcmd.Parser) (err error) {
+			return proxyProject(ctx)
+		},
+	}
+)
+
+func init() {
+	if err := Seed.AddCommand(seedApply, seedStatus, seedExport, seedImport); err != nil {
+		panic(err)
+	}
+}
@@ -1,3 +1,10 @@
 package config
 
 const Version = "v1.3.7"
+
+// 初始化项目时使用的版本
+const (
+	GoVersion   = "1.24.5" // go.mod 中的 go 版本
+	GfVersion   = "v2.9.0" // GoFrame 版本
+	CoreVersion = "v1.3.8" // dzhcore 版本,生成的项目使用 corecmd,不能低于该版本
+)
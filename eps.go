@@ -0,0 +1,196 @@
+package dzhcore
+
+import (
+	"context"
+	"reflect"
+	"strings"
+
+	"github.com/gogf/gf/v2/container/garray"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/util/gconv"
+	"github.com/gogf/gf/v2/util/gmeta"
+	"github.com/gzdzh-cn/dzhcore/coreconfig"
+)
+
+// 前端接口描述 eps,按路由前缀列出控制器开启的接口和模型的列,供 dzhgo eps 生成 TypeScript 类型和请求方法
+// 开启 core.eps 时提供 /admin/core/eps 接口,命令行通过 go run . eps 导出
+
+// Controller 的增删改查接口,只有在 Api 中开启的才会导出
+var crudActions = []string{"Add", "Delete", "Update", "UpdateBatch", "Upsert", "Info", "List", "Page", "Options"}
+
+// Eps 控制器的接口描述
+type Eps struct {
+	Prefix  string        `json:"prefix"`  // 路由前缀
+	Name    string        `json:"name"`    // 模型名称,没有模型时为控制器名称
+	Group   string        `json:"group"`   // 数据库分组
+	Table   string        `json:"table"`   // 表名,不带增删改查的控制器为空
+	Api     []*EpsApi     `json:"api"`     // 接口
+	Columns []*ColumnInfo `json:"columns"` // 表的列
+}
+
+// EpsApi 接口描述
+type EpsApi struct {
+	Name    string      `json:"name"`    // 方法名,如 Add、Page
+	Method  string      `json:"method"`  // 请求方式
+	Path    string      `json:"path"`    // 完整路径
+	Summary string      `json:"summary"` // 接口说明,取自请求结构体 g.Meta 的 summary
+	Params  []*EpsParam `json:"params"`  // 请求参数
+}
+
+// EpsParam 请求参数
+type EpsParam struct {
+	Name     string `json:"name"`     // 参数名,取自 json 标签
+	Type     string `json:"type"`     // Go 类型,如 string、[]int
+	Required bool   `json:"required"` // 校验规则中包含 required
+	Comment  string `json:"comment"`  // 说明,取自 dc 标签
+}
+
+// GetEps 获取全部控制器的接口描述,开启 core.eps 时优先使用注册路由时读取的列
+func GetEps(ctx context.Context) (list []*Eps, err error) {
+	for _, c := range Controllers {
+		var sController = &Controller{}
+		if err = gconv.Struct(c, &sController); err != nil {
+			return nil, err
+		}
+		eps := &Eps{
+			Prefix: sController.Prefix,
+			Name:   typeName(c),
+			Api:    controllerApi(c, sController.Prefix, sController.Api),
+		}
+		eps.Group, eps.Table = sController.groupTable()
+		if model := sController.Service.GetModel(); model != nil {
+			eps.Name = typeName(model)
+		}
+		if columns, ok := ModelInfo[eps.Prefix]; ok {
+			eps.Columns = columns
+		} else if eps.Table != "" {
+			if eps.Columns, err = getModelInfo(ctx, eps.Group, eps.Table); err != nil {
+				return nil, err
+			}
+		}
+		list = append(list, eps)
+	}
+	for _, c := range ControllerSimples {
+		var sController = &ControllerSimple{}
+		if err = gconv.Struct(c, &sController); err != nil {
+			return nil, err
+		}
+		list = append(list, &Eps{
+			Prefix: sController.Prefix,
+			Name:   typeName(c),
+			Api:    controllerApi(c, sController.Prefix, nil),
+		})
+	}
+	return
+}
+
+// 按 gf 规范路由的方法签名 func(ctx, *Req) (*Res, error) 读取控制器的接口
+// enabled 不为空时,增删改查接口只导出 enabled 中的
+func controllerApi(c interface{}, prefix string, enabled []string) (list []*EpsApi) {
+	var (
+		value   = reflect.ValueOf(c)
+		ctxType = reflect.TypeOf((*context.Context)(nil)).Elem()
+		errType = reflect.TypeOf((*error)(nil)).Elem()
+		crud    = garray.NewStrArrayFrom(crudActions)
+		api     = garray.NewStrArrayFrom(enabled)
+	)
+	for i := 0; i < value.NumMethod(); i++ {
+		var (
+			method = value.Type().Method(i)
+			fn     = method.Type
+		)
+		if fn.NumIn() != 3 || fn.NumOut() != 2 || fn.In(1) != ctxType || fn.Out(1) != errType {
+			continue
+		}
+		if fn.In(2).Kind() != reflect.Ptr || fn.In(2).Elem().Kind() != reflect.Struct {
+			continue
+		}
+		if crud.Contains(method.Name) && enabled != nil && !api.Contains(method.Name) {
+			continue
+		}
+		req := reflect.New(fn.In(2).Elem()).Interface()
+		path := gmeta.Get(req, "path").String()
+		if path == "" {
+			continue
+		}
+		list = append(list, &EpsApi{
+			Name:    method.Name,
+			Method:  strings.ToUpper(gmeta.Get(req, "method").String()),
+			Path:    strings.TrimSuffix(prefix, "/") + path,
+			Summary: gmeta.Get(req, "summary").String(),
+			Params:  requestParams(fn.In(2).Elem()),
+		})
+	}
+	return
+}
+
+// 请求结构体的参数,跳过 g.Meta 和没有导出的字段
+func requestParams(t reflect.Type) (params []*EpsParam) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() || field.Type == reflect.TypeOf(g.Meta{}) {
+			continue
+		}
+		// 嵌入的结构体展开
+		if field.Anonymous {
+			ft := field.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				params = append(params, requestParams(ft)...)
+				continue
+			}
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		rule, _, _ := strings.Cut(field.Tag.Get("v"), "#")
+		params = append(params, &EpsParam{
+			Name:     name,
+			Type:     field.Type.String(),
+			Required: garray.NewStrArrayFrom(strings.Split(rule, "|")).Contains("required"),
+			Comment:  field.Tag.Get("dc"),
+		})
+	}
+	return
+}
+
+// 结构体的类型名称
+func typeName(v interface{}) string {
+	t := reflect.TypeOf(v)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Name()
+}
+
+// EpsController 前端接口描述
+type EpsController struct {
+	*ControllerSimple
+}
+
+type EpsReq struct {
+	g.Meta `path:"/eps" method:"GET" summary:"前端接口描述"`
+}
+
+// Eps 获取全部控制器的接口和模型的列
+func (c *EpsController) Eps(ctx context.Context, req *EpsReq) (res *BaseRes, err error) {
+	list, err := GetEps(ctx)
+	if err != nil {
+		return Fail(err.Error()), err
+	}
+	return Ok(list), nil
+}
+
+func init() {
+	if coreconfig.Config.Core.Eps {
+		AddControllerSimple(&EpsController{
+			&ControllerSimple{Prefix: "/admin/core"},
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package dzhcore_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gzdzh-cn/dzhcore"
+	"github.com/gzdzh-cn/dzhcore/dzhcoretest"
+)
+
+// 增删改查接口只导出 Api 中开启的,列取自模型的表
+func TestGetEps(t *testing.T) {
+	app := dzhcoretest.Setup(t)
+	list, err := dzhcore.GetEps(app.Ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	eps := make(map[string]*dzhcore.Eps, len(list))
+	for _, item := range list {
+		eps[item.Prefix] = item
+	}
+
+	product := eps["/admin/test/product"]
+	if product == nil {
+		t.Fatal("没有 /admin/test/product")
+	}
+	if product.Name != "testProduct" || product.Table != "test_product" || product.Group != "default" {
+		t.Fatalf("name=%s table=%s group=%s", product.Name, product.Table, product.Group)
+	}
+	var api []string
+	for _, item := range product.Api {
+		api = append(api, item.Name+" "+item.Method+" "+item.Path)
+	}
+	if got := strings.Join(api, ","); got != "UpdateBatch POST /admin/test/product/updateBatch,Upsert POST /admin/test/product/upsert" {
+		t.Fatalf("接口 %s", got)
+	}
+	var columns []string
+	for _, column := range product.Columns {
+		columns = append(columns, column.PropertyName)
+	}
+	if got := strings.Join(columns, ","); !strings.Contains(got, "code") || !strings.Contains(got, "price") {
+		t.Fatalf("列 %s, 期望包含 code、price", got)
+	}
+
+	gate := eps["/admin/test/gate"]
+	if gate == nil || len(gate.Api) != 1 || gate.Api[0].Path != "/admin/test/gate/wait" || gate.Api[0].Method != "GET" {
+		t.Fatalf("/admin/test/gate 的接口 %+v", gate)
+	}
+}
+
+func TestEpsController(t *testing.T) {
+	app := dzhcoretest.Setup(t)
+	res := app.Client.Get(t, "/admin/core/eps")
+	if !res.Ok() {
+		t.Fatalf("读取接口描述失败: %s", res.Body)
+	}
+	var prefixes []string
+	for _, item := range g.NewVar(res.Data).Maps() {
+		prefixes = append(prefixes, g.NewVar(item["prefix"]).String())
+	}
+	if got := strings.Join(prefixes, ","); !strings.Contains(got, "/admin/test/product") || !strings.Contains(got, "/admin/core") {
+		t.Fatalf("接口描述的前缀 %s", got)
+	}
+}
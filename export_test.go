@@ -0,0 +1,38 @@
+package dzhcore
+
+// 供 dzhcore_test 使用的内部函数
+var (
+	CheckDatabase    = checkDatabase
+	CloseConnections = closeConnections
+	GetDBbyGroup     = getDBbyGroup
+	CreateFullText   = createFullTextIndex
+)
+
+// FullTextEnabled 全文索引是否可用
+func (s *Service) FullTextEnabled() bool {
+	return s.fullTextEnabled()
+}
+
+// SplitSqlStatements 拆分sql语句
+func SplitSqlStatements(sql string, mysql bool) []string {
+	return splitSqlStatements(sql, mysql)
+}
+
+// 未开启 core.dict.enable 时测试字典使用的服务
+var (
+	NewDictTypeService = newDictTypeService
+	NewDictItemService = newDictItemService
+)
+
+// MaskFieldValue 掩码处理
+func MaskFieldValue(value string, mask string, maskChar string) string {
+	return maskFieldValue(value, mask, maskChar)
+}
+
+// doctor 的检查项
+var (
+	DoctorDatabase = doctorDatabase
+	DoctorFile     = doctorFile
+	DoctorDirs     = doctorDirs
+	DoctorJwt      = doctorJwt
+)
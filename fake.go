@@ -0,0 +1,468 @@
+package dzhcore
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gogf/gf/v2/crypto/gmd5"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/util/gconv"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// 模拟数据,按模型的 gorm 结构和列注释生成数据,用于演示和压测
+// 按列名和注释判断姓名、手机号、邮箱、金额、日期等,唯一列不重复,外键从关联表中已有的数据中选择
+// 通过 gorm 连接分批插入
+
+// FakeFunc 指定列的生成函数,i 为本次生成的序号
+type FakeFunc func(r *rand.Rand, i int) interface{}
+
+// FakeOption 生成模拟数据的选项
+type FakeOption struct {
+	Count     int                 // 条数
+	BatchSize int                 // 每批插入的条数,默认 500
+	Seed      int64               // 随机数种子,为 0 时使用当前时间
+	Values    map[string]FakeFunc // 指定列的生成函数,key 为列名
+}
+
+// 默认每批插入的条数
+const fakeBatchSize = 500
+
+// 单条 insert 语句的参数个数上限,sqlite 的旧版本为 999
+const (
+	fakeMaxParams       = 30000
+	fakeSqliteMaxParams = 999
+)
+
+// FindModel 按表名或结构体名查找已注册的模型,结构体名不区分大小写
+func FindModel(name string) (IModel, error) {
+	for _, model := range Models {
+		if model.TableName() == name || strings.EqualFold(reflect.Indirect(reflect.ValueOf(model)).Type().Name(), name) {
+			return model, nil
+		}
+	}
+	return nil, gerror.Newf("未找到模型 %s,请确认已通过 AddModel 注册", name)
+}
+
+// FakeModel 生成模拟数据并分批插入,返回插入的条数
+func FakeModel(ctx context.Context, model IModel, opt *FakeOption) (count int, err error) {
+	if opt == nil || opt.Count <= 0 {
+		return 0, gerror.New("条数必须大于 0")
+	}
+	db := getDBbyModel(model)
+	if !db.Migrator().HasTable(model) {
+		return 0, gerror.Newf("表 %s 不存在,请先建表", model.TableName())
+	}
+	faker, err := newFaker(ctx, db, model, opt)
+	if err != nil {
+		return 0, err
+	}
+	batchSize := opt.BatchSize
+	if batchSize <= 0 {
+		batchSize = fakeBatchSize
+	}
+	maxParams := fakeMaxParams
+	if db.Dialector.Name() == "sqlite" {
+		maxParams = fakeSqliteMaxParams
+	}
+	if limit := max(1, maxParams/len(faker.fields)); batchSize > limit {
+		batchSize = limit
+	}
+	table := faker.schema.Table
+	err = db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for count < opt.Count {
+			n := min(batchSize, opt.Count-count)
+			rows := make([]map[string]interface{}, 0, n)
+			for i := 0; i < n; i++ {
+				rows = append(rows, faker.row(count+i))
+			}
+			if err := tx.Session(&gorm.Session{SkipHooks: true}).Table(table).Create(rows).Error; err != nil {
+				return err
+			}
+			count += n
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, gerror.Wrapf(err, "插入表 %s 失败", table)
+	}
+	return
+}
+
+// 模拟数据生成器
+type faker struct {
+	rand    *rand.Rand
+	schema  *schema.Schema
+	fields  []*schema.Field
+	values  map[string]FakeFunc
+	uniques [][]string          // 唯一索引的列
+	seen    []map[string]bool   // 各唯一索引已有的值
+	refs    map[string][]string // 外键列可选的值
+	seqs    map[string]int64    // 不自增的整数主键的起始值
+}
+
+func newFaker(ctx context.Context, db *gorm.DB, model IModel, opt *FakeOption) (*faker, error) {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return nil, err
+	}
+	seed := opt.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	f := &faker{
+		rand:   rand.New(rand.NewSource(seed)),
+		schema: stmt.Schema,
+		values: opt.Values,
+		refs:   make(map[string][]string),
+		seqs:   make(map[string]int64),
+	}
+	group := model.GroupName()
+	for _, dbName := range stmt.Schema.DBNames {
+		field := stmt.Schema.FieldsByDBName[dbName]
+		if (field.PrimaryKey && field.AutoIncrement) || isFakeSkipped(field) {
+			continue
+		}
+		f.fields = append(f.fields, field)
+		if field.PrimaryKey && (field.GORMDataType == schema.Int || field.GORMDataType == schema.Uint) {
+			value, err := g.DB(group).Model(stmt.Schema.Table).Ctx(ctx).Max(dbName)
+			if err != nil {
+				return nil, err
+			}
+			f.seqs[dbName] = int64(value) + 1
+		}
+	}
+	if len(f.fields) == 0 {
+		return nil, gerror.Newf("表 %s 没有可生成的列", stmt.Schema.Table)
+	}
+
+	// 外键,gorm 的 BelongsTo 关联和模型声明的 BelongsTo 关联
+	for _, rel := range stmt.Schema.Relationships.BelongsTo {
+		for _, ref := range rel.References {
+			if ref.OwnPrimaryKey || ref.PrimaryKey == nil || ref.ForeignKey == nil {
+				continue
+			}
+			if err := f.loadRef(ctx, ref.ForeignKey, group, rel.FieldSchema.Table, ref.PrimaryKey.DBName); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if m, ok := model.(IRelationModel); ok {
+		for _, rel := range m.Relations() {
+			if rel == nil || rel.Type != BelongsTo || rel.Model == nil {
+				continue
+			}
+			if field := stmt.Schema.LookUpField(rel.ForeignKey); field != nil {
+				if err := f.loadRef(ctx, field, rel.Model.GroupName(), rel.Model.TableName(), rel.localKey()); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	// 唯一列,读取表中已有的值避免重复
+	for _, field := range f.fields {
+		if field.Unique || field.PrimaryKey {
+			f.uniques = append(f.uniques, []string{field.DBName})
+		}
+	}
+	generated := make(map[string]bool, len(f.fields))
+	for _, field := range f.fields {
+		generated[field.DBName] = true
+	}
+	for _, index := range stmt.Schema.ParseIndexes() {
+		if index.Class != "UNIQUE" {
+			continue
+		}
+		var columns []string
+		for _, option := range index.Fields {
+			if option.Field != nil && generated[option.DBName] {
+				columns = append(columns, option.DBName)
+			}
+		}
+		if len(columns) > 0 {
+			f.uniques = append(f.uniques, columns)
+		}
+	}
+	for _, columns := range f.uniques {
+		result, err := g.DB(group).Model(stmt.Schema.Table).Ctx(ctx).Unscoped().Fields(columns).All()
+		if err != nil {
+			return nil, err
+		}
+		seen := make(map[string]bool, len(result))
+		for _, record := range result {
+			seen[uniqueKey(record.Map(), columns)] = true
+		}
+		f.seen = append(f.seen, seen)
+	}
+	return f, nil
+}
+
+// 读取外键可选的值,关联表为空且列不能为空时返回错误
+func (f *faker) loadRef(ctx context.Context, field *schema.Field, group, table, key string) error {
+	values, err := g.DB(group).Model(table).Ctx(ctx).Fields(key).Limit(10000).Array()
+	if err != nil {
+		return err
+	}
+	if len(values) == 0 {
+		if field.NotNull {
+			return gerror.Newf("列 %s 关联的表 %s 没有数据,请先生成 %s 的数据", field.DBName, table, table)
+		}
+		return nil
+	}
+	f.refs[field.DBName] = gconv.Strings(values)
+	return nil
+}
+
+// 删除时间列和二进制列不生成
+func isFakeSkipped(field *schema.Field) bool {
+	if field.DBName == "" || field.GORMDataType == schema.Bytes {
+		return true
+	}
+	switch field.DBName {
+	case "deletedAt", "deleted_at":
+		return true
+	}
+	return false
+}
+
+// 生成一行,唯一索引的值重复时重新生成,多次重复后在值后追加序号
+func (f *faker) row(i int) map[string]interface{} {
+	row := make(map[string]interface{}, len(f.fields))
+	for _, field := range f.fields {
+		row[field.DBName] = f.value(field, i)
+	}
+	for n, columns := range f.uniques {
+		key := uniqueKey(row, columns)
+		for try := 0; f.seen[n][key] && try < 10; try++ {
+			for _, column := range columns {
+				if _, ok := f.refs[column]; !ok {
+					row[column] = f.value(f.schema.FieldsByDBName[column], i)
+				}
+			}
+			key = uniqueKey(row, columns)
+		}
+		for try := 0; f.seen[n][key] && try < 1000; try++ {
+			column := columns[len(columns)-1]
+			row[column] = uniqueValue(f.schema.FieldsByDBName[column], row[column], i, try)
+			key = uniqueKey(row, columns)
+		}
+		f.seen[n][key] = true
+	}
+	// 更新时间不早于创建时间
+	if created, ok := row["createTime"].(time.Time); ok {
+		if updated, ok := row["updateTime"].(time.Time); ok && updated.Before(created) {
+			row["updateTime"] = created.Add(time.Duration(f.rand.Int63n(int64(30 * 24 * time.Hour))))
+		}
+	}
+	return row
+}
+
+func uniqueKey(row map[string]interface{}, columns []string) string {
+	values := make([]string, len(columns))
+	for i, column := range columns {
+		values[i] = gconv.String(row[column])
+	}
+	return strings.Join(values, "\x00")
+}
+
+// 重复时追加序号后的值
+func uniqueValue(field *schema.Field, value interface{}, i, try int) interface{} {
+	switch field.GORMDataType {
+	case schema.Int, schema.Uint:
+		return gconv.Int64(value) + int64(i+1)*1000 + int64(try)
+	case schema.Float:
+		return gconv.Float64(value) + float64(i+1) + float64(try)
+	case schema.Time:
+		return gconv.Time(value).Add(time.Duration(i*1000+try+1) * time.Second)
+	case schema.String:
+		suffix := fmt.Sprintf("_%d_%d", i, try)
+		s := gconv.String(value)
+		if size := fieldSize(field); size > 0 && len([]rune(s))+len(suffix) > size {
+			s = string([]rune(s)[:max(0, size-len(suffix))])
+		}
+		return s + suffix
+	}
+	return value
+}
+
+var (
+	fakeSizePattern  = regexp.MustCompile(`\((\d+)`)
+	fakeEnumPattern  = regexp.MustCompile(`(-?\d+)\s*[:：=]`)
+	fakeSurnames     = []rune("王李张刘陈杨黄赵吴周徐孙马朱胡郭何高林罗郑梁谢宋唐许韩冯邓曹彭曾肖田董袁潘于蒋蔡余杜叶程苏魏吕丁任沈姚卢")
+	fakeGivenNames   = []rune("伟芳娜敏静丽强磊军洋勇艳杰娟涛明超秀霞平刚桂英华玉兰萍红鹏辉建文斌宇浩凯晨欣怡子涵梓轩雨佳思")
+	fakeCities       = []string{"北京市", "上海市", "广州市", "深圳市", "杭州市", "成都市", "武汉市", "南京市", "西安市", "重庆市", "苏州市", "长沙市"}
+	fakeStreets      = []string{"人民路", "中山路", "解放路", "建设路", "和平路", "新华路", "文化路", "长江路", "科技路", "朝阳路"}
+	fakeWords        = []string{"优质", "经典", "新款", "精选", "专业", "智能", "便携", "高端", "实用", "热销", "基础", "标准"}
+	fakeNouns        = []string{"商品", "服务", "套餐", "课程", "项目", "方案", "产品", "工具", "设备", "资料", "活动", "订单"}
+	fakeSentences    = []string{"这是一条用于演示的数据", "数据由模拟数据工具生成", "请勿用于正式环境", "内容仅供测试使用", "可以按需修改或删除"}
+	fakeEmailDomains = []string{"example.com", "example.cn", "test.com"}
+	fakeLetters      = "ABCDEFGHJKLMNPQRSTUVWXYZ0123456789"
+)
+
+// 按列名、注释和类型生成值
+func (f *faker) value(field *schema.Field, i int) interface{} {
+	if fn, ok := f.values[field.DBName]; ok {
+		return fn(f.rand, i)
+	}
+	if values, ok := f.refs[field.DBName]; ok {
+		return values[f.rand.Intn(len(values))]
+	}
+	r := f.rand
+	if field.PrimaryKey {
+		if seq, ok := f.seqs[field.DBName]; ok {
+			return seq + int64(i)
+		}
+		if field.GORMDataType == schema.String {
+			return NodeSnowflake.Generate().String()
+		}
+	}
+	var (
+		name    = strings.ToLower(field.DBName)
+		comment = field.Comment
+		has     = func(keys ...string) bool {
+			for _, key := range keys {
+				if strings.Contains(name, strings.ToLower(key)) || strings.Contains(comment, key) {
+					return true
+				}
+			}
+			return false
+		}
+	)
+
+	switch field.GORMDataType {
+	case schema.Bool:
+		return r.Intn(2) == 1
+	case schema.Int, schema.Uint:
+		if enums := fakeEnumPattern.FindAllStringSubmatch(comment, -1); len(enums) >= 2 {
+			return gconv.Int64(enums[r.Intn(len(enums))][1])
+		}
+		switch {
+		case has("status", "type", "state", "状态", "类型"):
+			return int64(r.Intn(2))
+		case has("sort", "order", "排序"):
+			return int64(r.Intn(100))
+		case has("level", "grade", "等级", "级别"):
+			return int64(1 + r.Intn(5))
+		case has("age", "年龄"):
+			return int64(18 + r.Intn(50))
+		case has("gender", "sex", "性别"):
+			return int64(r.Intn(3))
+		case has("count", "num", "qty", "stock", "数量", "库存"):
+			return int64(r.Intn(1000))
+		case has("amount", "price", "money", "fee", "total", "balance", "金额", "价格"):
+			return int64(r.Intn(100000))
+		}
+		return int64(r.Intn(10000))
+	case schema.Float:
+		scale := field.Scale
+		if scale == 0 {
+			scale = 2
+		}
+		value := r.Float64() * 1000
+		if has("amount", "price", "money", "fee", "total", "balance", "金额", "价格", "费用") {
+			value = 0.01 + r.Float64()*9999
+		} else if has("rate", "ratio", "percent", "比例", "率") {
+			value = r.Float64()
+		}
+		pow := math.Pow(10, float64(scale))
+		return math.Round(value*pow) / pow
+	case schema.Time:
+		if has("birth", "生日", "出生") {
+			return fakeBirthday(r)
+		}
+		return time.Now().Add(-time.Duration(r.Int63n(int64(365 * 24 * time.Hour))))
+	}
+
+	// 字符串类型的日期列,如 type:date
+	birth := fakeBirthday(r)
+	switch strings.ToLower(string(field.DataType)) {
+	case "date":
+		if !has("birth", "生日", "出生") {
+			birth = time.Now().AddDate(0, 0, -r.Intn(365))
+		}
+		return birth.Format(time.DateOnly)
+	case "datetime", "timestamp":
+		return time.Now().Add(-time.Duration(r.Int63n(int64(365 * 24 * time.Hour)))).Format(time.DateTime)
+	}
+
+	var value string
+	switch {
+	case has("birth", "生日", "出生"):
+		value = birth.Format(time.DateOnly)
+	case has("email", "mail", "邮箱"):
+		value = fmt.Sprintf("user%d%d@%s", i, r.Intn(1000), fakeEmailDomains[r.Intn(len(fakeEmailDomains))])
+	case has("phone", "mobile", "tel", "手机", "电话"):
+		value = fmt.Sprintf("1%d%09d", 3+r.Intn(7), r.Intn(1000000000))
+	case has("password", "pass", "密码"):
+		value = gmd5.MustEncryptString("123456")
+	case has("avatar", "image", "img", "pic", "photo", "头像", "图片"):
+		value = fmt.Sprintf("https://example.com/images/%d.png", r.Intn(10000))
+	case has("url", "link", "链接"):
+		value = fmt.Sprintf("https://example.com/%d", r.Intn(10000))
+	case has("address", "地址"):
+		value = fmt.Sprintf("%s%s%d号", fakeCities[r.Intn(len(fakeCities))], fakeStreets[r.Intn(len(fakeStreets))], 1+r.Intn(999))
+	case has("city", "城市"):
+		value = fakeCities[r.Intn(len(fakeCities))]
+	case name == "ip" || strings.HasSuffix(name, "ip") || strings.Contains(comment, "IP"):
+		value = fmt.Sprintf("%d.%d.%d.%d", 1+r.Intn(223), r.Intn(256), r.Intn(256), 1+r.Intn(254))
+	case has("username", "account", "账号", "用户名"):
+		value = fmt.Sprintf("user%d%04d", i, r.Intn(10000))
+	case has("realname", "nickname", "contact", "姓名", "昵称", "联系人"):
+		value = f.personName()
+	case has("title", "name", "名称", "标题"):
+		value = fakeWords[r.Intn(len(fakeWords))] + fakeNouns[r.Intn(len(fakeNouns))] + strconv.Itoa(1+r.Intn(1000))
+	case has("remark", "desc", "content", "note", "comment", "备注", "描述", "内容", "说明"):
+		value = fakeSentences[r.Intn(len(fakeSentences))]
+	case has("code", "编号", "编码", "单号") || strings.HasSuffix(name, "no") || strings.HasSuffix(name, "sn"):
+		value = f.code(10)
+	case has("date", "time", "日期", "时间"):
+		value = time.Now().Add(-time.Duration(r.Int63n(int64(365 * 24 * time.Hour)))).Format(time.DateTime)
+	default:
+		value = fakeWords[r.Intn(len(fakeWords))] + f.code(6)
+	}
+	if size := fieldSize(field); size > 0 && len([]rune(value)) > size {
+		value = string([]rune(value)[:size])
+	}
+	return value
+}
+
+func fakeBirthday(r *rand.Rand) time.Time {
+	return time.Date(1960+r.Intn(45), time.Month(1+r.Intn(12)), 1+r.Intn(28), 0, 0, 0, 0, time.Local)
+}
+
+func (f *faker) personName() string {
+	name := string(fakeSurnames[f.rand.Intn(len(fakeSurnames))])
+	for n := 1 + f.rand.Intn(2); n > 0; n-- {
+		name += string(fakeGivenNames[f.rand.Intn(len(fakeGivenNames))])
+	}
+	return name
+}
+
+func (f *faker) code(n int) string {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = fakeLetters[f.rand.Intn(len(fakeLetters))]
+	}
+	return string(b)
+}
+
+// 字符串列的长度,没有 size 时读取 type 中的长度,如 varchar(255)
+func fieldSize(field *schema.Field) int {
+	if field.Size > 0 {
+		return field.Size
+	}
+	if match := fakeSizePattern.FindStringSubmatch(string(field.DataType)); match != nil && field.GORMDataType == schema.String {
+		return gconv.Int(match[1])
+	}
+	return 0
+}
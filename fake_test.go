@@ -0,0 +1,134 @@
+package dzhcore_test
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gzdzh-cn/dzhcore"
+	"github.com/gzdzh-cn/dzhcore/dzhcoretest"
+)
+
+type testFakeItem struct {
+	*dzhcore.Model
+	Code       string `gorm:"column:code;type:varchar(8);uniqueIndex" json:"code"`
+	ShopId     int64  `gorm:"column:shopId;not null;uniqueIndex:idx_test_fake_item_sku,priority:1" json:"shopId"`
+	Sku        string `gorm:"column:sku;type:varchar(20);not null;uniqueIndex:idx_test_fake_item_sku,priority:2" json:"sku"`
+	CustomerId string `gorm:"column:customerId;type:varchar(255);not null" json:"customerId"`
+	Status     int    `gorm:"column:status;comment:状态 0:禁用 1:启用" json:"status"`
+}
+
+func (*testFakeItem) TableName() string {
+	return "test_fake_item"
+}
+
+func (*testFakeItem) GroupName() string {
+	return "default"
+}
+
+func (*testFakeItem) Relations() map[string]*dzhcore.Relation {
+	return map[string]*dzhcore.Relation{
+		"customer": {Type: dzhcore.BelongsTo, Model: &testCustomer{}, ForeignKey: "customerId"},
+	}
+}
+
+func init() {
+	testOptions.Models = append(testOptions.Models, &testFakeItem{})
+}
+
+func TestFindModel(t *testing.T) {
+	dzhcoretest.Setup(t)
+	for _, name := range []string{"test_fake_item", "testFakeItem", "TESTFAKEITEM"} {
+		model, err := dzhcore.FindModel(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if model.TableName() != "test_fake_item" {
+			t.Fatalf("%s 找到 %s", name, model.TableName())
+		}
+	}
+	if _, err := dzhcore.FindModel("test_missing"); err == nil {
+		t.Fatal("模型不存在时没有错误")
+	}
+}
+
+// 外键从关联表中已有的数据中选择,关联表为空时报错
+func TestFakeModelRef(t *testing.T) {
+	app := dzhcoretest.Setup(t)
+	if _, err := dzhcore.FakeModel(app.Ctx, &testFakeItem{}, &dzhcore.FakeOption{Count: 1}); err == nil || !strings.Contains(err.Error(), "没有数据") {
+		t.Fatalf("关联表为空时错误 %v", err)
+	}
+	if _, err := dzhcore.FakeModel(app.Ctx, &testFakeItem{}, nil); err == nil {
+		t.Fatal("没有条数时没有错误")
+	}
+
+	app.Fixture(t, "test_customer", g.List{{"id": "c1", "name": "张三"}, {"id": "c2", "name": "李四"}})
+	count, err := dzhcore.FakeModel(app.Ctx, &testFakeItem{}, &dzhcore.FakeOption{Count: 50, BatchSize: 7, Seed: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 50 {
+		t.Fatalf("插入 %d 条, 期望 50", count)
+	}
+	result, err := g.DB().Model("test_fake_item").All()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result) != 50 {
+		t.Fatalf("表中 %d 条, 期望 50", len(result))
+	}
+	for _, record := range result {
+		if customer := record["customerId"].String(); customer != "c1" && customer != "c2" {
+			t.Errorf("customerId %s 不在关联表中", customer)
+		}
+		if status := record["status"].Int(); status != 0 && status != 1 {
+			t.Errorf("status %d 不在注释的枚举中", status)
+		}
+		if record["createTime"].IsEmpty() || record["id"].IsEmpty() {
+			t.Errorf("缺少 id 或创建时间: %v", record)
+		}
+	}
+}
+
+// 唯一列和联合唯一索引不与表中已有的数据和本次生成的数据重复,追加序号后不超过列的长度
+func TestFakeModelUnique(t *testing.T) {
+	app := dzhcoretest.Setup(t)
+	app.Fixture(t, "test_customer", g.List{{"id": "c1", "name": "张三"}})
+	app.Fixture(t, "test_fake_item", g.List{{"code": "dup", "shopId": 1, "sku": "s", "customerId": "c1"}})
+	_, err := dzhcore.FakeModel(app.Ctx, &testFakeItem{}, &dzhcore.FakeOption{
+		Count:     30,
+		BatchSize: 7,
+		Seed:      1,
+		Values: map[string]dzhcore.FakeFunc{
+			"code":   func(r *rand.Rand, i int) interface{} { return "dup" },
+			"shopId": func(r *rand.Rand, i int) interface{} { return 1 },
+			"sku":    func(r *rand.Rand, i int) interface{} { return "s" },
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := g.DB().Model("test_fake_item").All()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result) != 31 {
+		t.Fatalf("表中 %d 条, 期望 31", len(result))
+	}
+	var (
+		codes = make(map[string]bool)
+		skus  = make(map[string]bool)
+	)
+	for _, record := range result {
+		code := record["code"].String()
+		if len(code) > 8 || !strings.HasPrefix(code, "d") {
+			t.Errorf("code %q 超过长度或不是指定的值", code)
+		}
+		codes[code] = true
+		skus[record["shopId"].String()+"/"+record["sku"].String()] = true
+	}
+	if len(codes) != 31 || len(skus) != 31 {
+		t.Fatalf("code 不重复 %d 个, shopId/sku 不重复 %d 个, 期望 31", len(codes), len(skus))
+	}
+}
@@ -0,0 +1,151 @@
+package dzhcore
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gogf/gf/v2/container/garray"
+	"github.com/gogf/gf/v2/database/gdb"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gtime"
+	"github.com/gogf/gf/v2/util/gconv"
+)
+
+// 内置掩码规则 保留前几位,后几位
+var fieldMasks = map[string][2]int{
+	"phone":    {3, 4},
+	"idcard":   {6, 4},
+	"bankcard": {4, 4},
+	"name":     {1, 0},
+}
+
+// FieldRule 字段输出规则,在 Info/List/Page/导出 中统一生效
+type FieldRule struct {
+	Hide        bool     // 隐藏字段
+	Mask        string   // 掩码规则 phone|idcard|bankcard|name|email 或 "3,4" 表示保留前3位和后4位
+	MaskChar    string   // 掩码字符,默认 *
+	Format      string   // 格式化 日期如 Y-m-d H:i:s, 数字如 %.2f
+	Rename      string   // 输出时重命名字段
+	ExemptRoles []string // 特权角色ID,拥有其中任一角色时不隐藏、不掩码
+}
+
+// GetCtxRoleIds 获取当前请求用户的角色ID,默认读取上下文变量 admin 中的 roleIds,可自定义覆盖
+var GetCtxRoleIds = func(ctx context.Context) []string {
+	r := g.RequestFromCtx(ctx)
+	if r == nil {
+		return nil
+	}
+	admin := r.GetCtxVar("admin")
+	if admin.IsNil() {
+		return nil
+	}
+	var claims *Claims
+	if err := admin.Struct(&claims); err != nil || claims == nil {
+		return nil
+	}
+	return claims.RoleIds
+}
+
+// 处理输出结果,字段输出规则等在 Info/List/Page/导出 中统一生效
+func (s *Service) processResult(ctx context.Context, result gdb.Result) gdb.Result {
+	if len(result) == 0 {
+		return result
+	}
+	result = s.loadRelations(ctx, result)
+	result = s.applyDictFields(ctx, result)
+	return s.applyFieldRules(ctx, result)
+}
+
+// 处理单条输出结果
+func (s *Service) processRecord(ctx context.Context, record gdb.Record) gdb.Record {
+	if record == nil {
+		return record
+	}
+	return s.processResult(ctx, gdb.Result{record})[0]
+}
+
+// 按字段输出规则处理结果
+func (s *Service) applyFieldRules(ctx context.Context, result gdb.Result) gdb.Result {
+	if len(s.FieldRules) == 0 {
+		return result
+	}
+	roleIds := garray.NewStrArrayFrom(GetCtxRoleIds(ctx))
+	for field, rule := range s.FieldRules {
+		exempt := false
+		for _, role := range rule.ExemptRoles {
+			if roleIds.Contains(role) {
+				exempt = true
+				break
+			}
+		}
+		for _, record := range result {
+			value, ok := record[field]
+			if !ok {
+				continue
+			}
+			if rule.Hide && !exempt {
+				delete(record, field)
+				continue
+			}
+			if !value.IsNil() {
+				if rule.Format != "" {
+					value = g.NewVar(formatFieldValue(value, rule.Format))
+				}
+				if rule.Mask != "" && !exempt {
+					value = g.NewVar(maskFieldValue(value.String(), rule.Mask, rule.MaskChar))
+				}
+			}
+			if rule.Rename != "" {
+				delete(record, field)
+				record[rule.Rename] = value
+				continue
+			}
+			record[field] = value
+		}
+	}
+	return result
+}
+
+// 格式化字段值,包含 % 时按数字格式化,否则按日期格式化
+func formatFieldValue(value *g.Var, format string) string {
+	if strings.Contains(format, "%") {
+		return fmt.Sprintf(format, value.Float64())
+	}
+	t := gtime.New(value.Val())
+	if t == nil || t.IsZero() {
+		return value.String()
+	}
+	return t.Format(format)
+}
+
+// 掩码处理
+func maskFieldValue(value string, mask string, maskChar string) string {
+	if maskChar == "" {
+		maskChar = "*"
+	}
+	runes := []rune(value)
+	if len(runes) == 0 {
+		return value
+	}
+	if mask == "email" {
+		at := strings.LastIndex(value, "@")
+		if at <= 0 {
+			return maskFieldValue(value, "name", maskChar)
+		}
+		return maskFieldValue(value[:at], "name", maskChar) + value[at:]
+	}
+	keep, ok := fieldMasks[mask]
+	if !ok {
+		parts := gconv.Ints(strings.Split(mask, ","))
+		if len(parts) != 2 {
+			return strings.Repeat(maskChar, len(runes))
+		}
+		keep = [2]int{parts[0], parts[1]}
+	}
+	// 长度不足时只保留第一个字符
+	if keep[0]+keep[1] >= len(runes) {
+		return string(runes[:1]) + strings.Repeat(maskChar, len(runes)-1)
+	}
+	return string(runes[:keep[0]]) + strings.Repeat(maskChar, len(runes)-keep[0]-keep[1]) + string(runes[len(runes)-keep[1]:])
+}
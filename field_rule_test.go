@@ -0,0 +1,119 @@
+package dzhcore_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gogf/gf/v2/encoding/gjson"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gzdzh-cn/dzhcore"
+	"github.com/gzdzh-cn/dzhcore/dzhcoretest"
+)
+
+func init() {
+	s := dzhcore.NewModelService(&testContact{})
+	s.Dao = dzhcore.NewModelDao(&testContact{})
+	s.FieldRules = map[string]*dzhcore.FieldRule{
+		"name":       {Mask: "name", ExemptRoles: []string{"admin"}},
+		"phone":      {Mask: "phone", MaskChar: "#", Rename: "mobile", ExemptRoles: []string{"admin"}},
+		"city":       {Hide: true, ExemptRoles: []string{"admin", "ops"}},
+		"createTime": {Format: "Y-m-d"},
+	}
+	testOptions.Controllers = append(testOptions.Controllers,
+		&dzhcore.Controller{Prefix: "/admin/test/contactRule", Api: []string{"Info", "List", "Page"}, Service: s},
+	)
+}
+
+// 请求使用的角色
+func setRoleIds(t *testing.T, roleIds ...string) {
+	getCtxRoleIds := dzhcore.GetCtxRoleIds
+	dzhcore.GetCtxRoleIds = func(ctx context.Context) []string { return roleIds }
+	t.Cleanup(func() { dzhcore.GetCtxRoleIds = getCtxRoleIds })
+}
+
+func setupFieldRule(t *testing.T) *dzhcoretest.App {
+	app := dzhcoretest.Setup(t)
+	app.Fixture(t, "test_contact", g.List{
+		{"id": "1", "name": "张三丰", "phone": "13812345678", "city": "深圳", "createTime": "2025-01-02 03:04:05"},
+	})
+	return app
+}
+
+func TestMaskFieldValue(t *testing.T) {
+	for _, tc := range []struct {
+		value, mask, maskChar, want string
+	}{
+		{"13812345678", "phone", "", "138****5678"},
+		{"440301199001011234", "idcard", "", "440301********1234"},
+		{"6222020200112233445", "bankcard", "#", "6222###########3445"},
+		{"张三丰", "name", "", "张**"},
+		{"alice@example.com", "email", "", "a****@example.com"},
+		{"abcdefgh", "2,1", "", "ab*****h"},
+		{"abc", "phone", "", "a**"}, // 长度不足时只保留第一个字符
+		{"abc", "x", "", "***"},     // 无法识别的规则全部掩码
+		{"", "phone", "", ""},
+	} {
+		if got := dzhcore.MaskFieldValue(tc.value, tc.mask, tc.maskChar); got != tc.want {
+			t.Errorf("%s 按 %s 掩码为 %q, 期望 %q", tc.value, tc.mask, got, tc.want)
+		}
+	}
+}
+
+// 没有特权角色时隐藏、掩码、重命名和格式化都生效
+func TestFieldRules(t *testing.T) {
+	app := setupFieldRule(t)
+	setRoleIds(t, "user")
+	var (
+		info = app.Client.Get(t, "/admin/test/contactRule/info?id=1")
+		list = app.Client.Post(t, "/admin/test/contactRule/list", nil)
+		page = app.Client.Page(t, "/admin/test/contactRule", nil)
+	)
+	for _, res := range []*dzhcoretest.Response{info, list, page} {
+		if !res.Ok() {
+			t.Fatalf("请求失败: %s", res.Body)
+		}
+	}
+	records := map[string]*gjson.Json{
+		"info": info.Json(),
+		"list": list.Json().GetJson("0"),
+		"page": page.Json().GetJson("list.0"),
+	}
+	want := g.MapStrStr{"name": "张**", "mobile": "138####5678", "createTime": "2025-01-02"}
+	for api, record := range records {
+		for k, v := range want {
+			if got := record.Get(k).String(); got != v {
+				t.Errorf("%s %s = %q, 期望 %q", api, k, got, v)
+			}
+		}
+		for _, k := range []string{"phone", "city"} {
+			if record.Contains(k) {
+				t.Errorf("%s 输出了 %s", api, k)
+			}
+		}
+	}
+}
+
+// 拥有特权角色时不隐藏、不掩码,重命名和格式化仍然生效
+func TestFieldRulesExemptRoles(t *testing.T) {
+	app := setupFieldRule(t)
+	setRoleIds(t, "ops")
+	record := app.Client.Get(t, "/admin/test/contactRule/info?id=1").Json()
+	if got := record.Get("city").String(); got != "深圳" {
+		t.Errorf("ops 角色 city = %q, 期望 深圳", got)
+	}
+	if got := record.Get("mobile").String(); got != "138####5678" {
+		t.Errorf("ops 角色 mobile = %q, 期望掩码", got)
+	}
+
+	setRoleIds(t, "user", "admin")
+	record = app.Client.Get(t, "/admin/test/contactRule/info?id=1").Json()
+	want := g.MapStrStr{"name": "张三丰", "mobile": "13812345678", "city": "深圳", "createTime": "2025-01-02"}
+	for k, v := range want {
+		if got := record.Get(k).String(); got != v {
+			t.Errorf("admin 角色 %s = %q, 期望 %q", k, got, v)
+		}
+	}
+	if record.Contains("phone") {
+		t.Error("admin 角色输出了重命名前的 phone")
+	}
+}
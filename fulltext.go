@@ -0,0 +1,322 @@
+package dzhcore
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/gogf/gf/v2/container/garray"
+	"github.com/gogf/gf/v2/container/gmap"
+	"github.com/gogf/gf/v2/container/gset"
+	"github.com/gogf/gf/v2/database/gdb"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/util/gconv"
+)
+
+// 全文检索相关度排序字段,请求参数 order=relevance 时按相关度排序
+const FullTextOrderRelevance = "relevance"
+
+// 全文检索配置
+// sqlite 使用 fts5 虚拟表+触发器, mysql 使用 FULLTEXT 索引 + MATCH AGAINST, pgsql 使用 tsvector + GIN 索引
+// 数据库不支持或索引创建失败时自动回退为 LIKE 模糊查询
+type FullTextOp struct {
+	Fields    []string // 参与全文检索的字段,为空时使用 PageQueryOp/ListQueryOp 的 KeyWordField
+	Tokenizer string   // 分词器 mysql 为 WITH PARSER 名称,如 ngram; sqlite 为 fts5 的 tokenize,如 trigram
+	Language  string   // pgsql 分词配置,默认 simple
+}
+
+var (
+	fullTextMu    sync.Mutex
+	fullTextReady = gmap.NewStrAnyMap(true) // 已就绪的全文索引 key: 分组:表名 value: 字段
+	fullTextTried = gset.NewStrSet(true)    // 已尝试创建全文索引的表 key: 分组:表名
+)
+
+// GetService 获取 Service 本身,供框架内部读取 Service 配置
+func (s *Service) GetService() *Service {
+	return s
+}
+
+// 从控制器中获取 Service 配置
+func getControllerService(c IController) *Service {
+	var sController = &Controller{}
+	if err := gconv.Struct(c, &sController); err != nil || sController.Service == nil {
+		return nil
+	}
+	if sv, ok := sController.Service.(interface{ GetService() *Service }); ok {
+		return sv.GetService()
+	}
+	return nil
+}
+
+// 全文检索字段
+func (s *Service) fullTextFields() []string {
+	if s.FullText == nil {
+		return nil
+	}
+	if len(s.FullText.Fields) > 0 {
+		return s.FullText.Fields
+	}
+	fields := garray.NewStrArray()
+	for _, op := range []*QueryOp{s.PageQueryOp, s.ListQueryOp} {
+		if op == nil {
+			continue
+		}
+		for _, field := range op.KeyWordField {
+			if field != "" && !strings.Contains(field, ".") && !fields.Contains(field) {
+				fields.Append(field)
+			}
+		}
+	}
+	return fields.Slice()
+}
+
+// 全文索引是否可用,未通过控制器注册的 Service 在第一次使用时创建全文索引
+func (s *Service) fullTextEnabled() bool {
+	if s.FullText == nil {
+		return false
+	}
+	group, table := s.groupTable()
+	key := group + ":" + table
+	if !fullTextTried.Contains(key) {
+		s.initFullText()
+	}
+	return fullTextReady.Contains(key)
+}
+
+// initFullText 为控制器中开启全文检索的 Service 创建全文索引
+func initFullText() {
+	for _, controller := range Controllers {
+		if s := getControllerService(controller); s != nil && s.FullText != nil {
+			s.initFullText()
+		}
+	}
+}
+
+// 创建全文索引,每个表只尝试一次,失败时回退为 LIKE 查询
+func (s *Service) initFullText() {
+	group, table := s.groupTable()
+	fields := s.fullTextFields()
+	if table == "" || len(fields) == 0 {
+		return
+	}
+	key := group + ":" + table
+	fullTextMu.Lock()
+	defer fullTextMu.Unlock()
+	if fullTextTried.Contains(key) {
+		return
+	}
+	fullTextTried.Add(key)
+	if err := createFullTextIndex(group, table, fields, s.FullText); err != nil {
+		g.Log().Warningf(ctx, "表 %v 创建全文索引失败,回退为 LIKE 查询: %v", table, err)
+		return
+	}
+	fullTextReady.Set(key, fields)
+	g.Log().Debugf(ctx, "表 %v 全文索引已就绪, 字段: %v", table, fields)
+}
+
+// 按数据库类型创建全文索引
+func createFullTextIndex(group, table string, fields []string, op *FullTextOp) (err error) {
+	db := g.DB(group)
+	switch db.GetConfig().Type {
+	case "sqlite", "sqlitemem":
+		return createSqliteFullText(db, table, fields, op)
+	case "mysql", "mariadb", "tidb":
+		return createMysqlFullText(db, table, fields, op)
+	case "pgsql":
+		return createPgsqlFullText(db, table, fields, op)
+	}
+	return fmt.Errorf("数据库类型 %s 不支持全文检索", db.GetConfig().Type)
+}
+
+// sqlite fts5 外部内容虚拟表,通过触发器同步数据
+// 已有虚拟表的建表语句与当前字段、分词器不一致时删除后重建
+func createSqliteFullText(db gdb.DB, table string, fields []string, op *FullTextOp) (err error) {
+	var (
+		ftsTable  = table + "_fts"
+		columns   = quoteColumns(fields, `"`, "")
+		newValues = quoteColumns(fields, `"`, "new.")
+		oldValues = quoteColumns(fields, `"`, "old.")
+		tokenize  string
+	)
+	if op.Tokenizer != "" {
+		tokenize = fmt.Sprintf(", tokenize='%s'", op.Tokenizer)
+	}
+	create := fmt.Sprintf(`CREATE VIRTUAL TABLE "%s" USING fts5(%s, content='%s'%s)`, ftsTable, columns, table, tokenize)
+	current, err := db.GetValue(ctx, "SELECT sql FROM sqlite_master WHERE type='table' AND name=?", ftsTable)
+	if err != nil {
+		return err
+	}
+	if current.String() == create {
+		return nil
+	}
+	sqls := []string{
+		fmt.Sprintf(`DROP TRIGGER IF EXISTS "%s_ai"`, ftsTable),
+		fmt.Sprintf(`DROP TRIGGER IF EXISTS "%s_ad"`, ftsTable),
+		fmt.Sprintf(`DROP TRIGGER IF EXISTS "%s_au"`, ftsTable),
+		fmt.Sprintf(`DROP TABLE IF EXISTS "%s"`, ftsTable),
+		create,
+		fmt.Sprintf(`CREATE TRIGGER "%s_ai" AFTER INSERT ON "%s" BEGIN INSERT INTO "%s"(rowid, %s) VALUES (new.rowid, %s); END`, ftsTable, table, ftsTable, columns, newValues),
+		fmt.Sprintf(`CREATE TRIGGER "%s_ad" AFTER DELETE ON "%s" BEGIN INSERT INTO "%s"("%s", rowid, %s) VALUES ('delete', old.rowid, %s); END`, ftsTable, table, ftsTable, ftsTable, columns, oldValues),
+		fmt.Sprintf(`CREATE TRIGGER "%s_au" AFTER UPDATE ON "%s" BEGIN INSERT INTO "%s"("%s", rowid, %s) VALUES ('delete', old.rowid, %s); INSERT INTO "%s"(rowid, %s) VALUES (new.rowid, %s); END`, ftsTable, table, ftsTable, ftsTable, columns, oldValues, ftsTable, columns, newValues),
+		// 重建索引,同步已有数据
+		fmt.Sprintf(`INSERT INTO "%s"("%s") VALUES ('rebuild')`, ftsTable, ftsTable),
+	}
+	return db.Transaction(ctx, func(ctx context.Context, tx gdb.TX) error {
+		for _, sql := range sqls {
+			if _, err := tx.Exec(sql); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// 索引注释中记录的字段和分词配置,变化时重建索引
+func fullTextComment(fields []string, option string) string {
+	return "dzhcore fulltext: " + strings.Join(fields, ",") + ";" + option
+}
+
+// mysql、pgsql 全文索引名
+func fullTextIndexName(table string) string {
+	return "ft_" + table
+}
+
+// mysql FULLTEXT 索引,字段或分词器变化时删除后重建
+func createMysqlFullText(db gdb.DB, table string, fields []string, op *FullTextOp) (err error) {
+	var (
+		indexName = fullTextIndexName(table)
+		comment   = fullTextComment(fields, op.Tokenizer)
+	)
+	current, err := db.GetAll(ctx, "SELECT index_comment FROM information_schema.statistics WHERE table_schema=DATABASE() AND table_name=? AND index_name=? LIMIT 1", table, indexName)
+	if err != nil {
+		return err
+	}
+	if len(current) > 0 {
+		if current[0]["index_comment"].String() == comment {
+			return nil
+		}
+		if _, err = db.Exec(ctx, fmt.Sprintf("ALTER TABLE `%s` DROP INDEX `%s`", table, indexName)); err != nil {
+			return err
+		}
+	}
+	sql := fmt.Sprintf("ALTER TABLE `%s` ADD FULLTEXT INDEX `%s` (%s)", table, indexName, quoteColumns(fields, "`", ""))
+	if op.Tokenizer != "" {
+		sql += " WITH PARSER " + op.Tokenizer
+	}
+	sql += " COMMENT " + quoteLiteral(comment, true)
+	_, err = db.Exec(ctx, sql)
+	return err
+}
+
+// pgsql tsvector 表达式 GIN 索引,字段或分词配置变化时删除后重建
+func createPgsqlFullText(db gdb.DB, table string, fields []string, op *FullTextOp) (err error) {
+	var (
+		indexName = fullTextIndexName(table)
+		comment   = fullTextComment(fields, pgsqlLanguage(op))
+	)
+	current, err := db.GetAll(ctx, "SELECT obj_description(c.oid, 'pg_class') AS comment FROM pg_class c JOIN pg_namespace n ON n.oid = c.relnamespace WHERE c.relname = ? AND n.nspname = current_schema()", indexName)
+	if err != nil {
+		return err
+	}
+	if len(current) > 0 && current[0]["comment"].String() == comment {
+		return nil
+	}
+	sqls := []string{
+		fmt.Sprintf(`DROP INDEX IF EXISTS "%s"`, indexName),
+		fmt.Sprintf(`CREATE INDEX "%s" ON "%s" USING GIN (%s)`, indexName, table, pgsqlTsVector(fields, op, "")),
+		fmt.Sprintf(`COMMENT ON INDEX "%s" IS %s`, indexName, quoteLiteral(comment, false)),
+	}
+	return db.Transaction(ctx, func(ctx context.Context, tx gdb.TX) error {
+		for _, sql := range sqls {
+			if _, err := tx.Exec(sql); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// fullTextWhere 返回全文检索条件
+func (s *Service) fullTextWhere(op *QueryOp, keyWord string) (where string, args []interface{}) {
+	group, table := s.groupTable()
+	fields := gconv.Strings(fullTextReady.Get(group + ":" + table))
+	switch g.DB(group).GetConfig().Type {
+	case "sqlite", "sqlitemem":
+		prefix := tablePrefix(op, table, `"`)
+		where = fmt.Sprintf(`%srowid IN (SELECT rowid FROM "%s_fts" WHERE "%s_fts" MATCH ?)`, prefix, table, table)
+		args = append(args, sqliteMatchPhrase(keyWord))
+	case "mysql", "mariadb", "tidb":
+		// 自然语言模式,关键字中的 + - * " ( ) 等不作为布尔运算符解析
+		where = fmt.Sprintf("MATCH(%s) AGAINST(? IN NATURAL LANGUAGE MODE)", quoteColumns(fields, "`", tablePrefix(op, table, "`")))
+		args = append(args, keyWord)
+	case "pgsql":
+		where = fmt.Sprintf("%s @@ plainto_tsquery('%s', ?)", pgsqlTsVector(fields, s.FullText, tablePrefix(op, table, `"`)), pgsqlLanguage(s.FullText))
+		args = append(args, keyWord)
+	}
+	return
+}
+
+// fullTextOrder 返回按相关度排序语句
+func (s *Service) fullTextOrder(op *QueryOp, keyWord string) gdb.Raw {
+	group, table := s.groupTable()
+	fields := gconv.Strings(fullTextReady.Get(group + ":" + table))
+	switch g.DB(group).GetConfig().Type {
+	case "sqlite", "sqlitemem":
+		prefix := tablePrefix(op, table, `"`)
+		// fts5 的 rank 越小相关度越高
+		return gdb.Raw(fmt.Sprintf(`(SELECT rank FROM "%s_fts" WHERE "%s_fts" MATCH %s AND "%s_fts".rowid = %srowid) ASC`, table, table, quoteLiteral(sqliteMatchPhrase(keyWord), false), table, prefix))
+	case "mysql", "mariadb", "tidb":
+		return gdb.Raw(fmt.Sprintf("MATCH(%s) AGAINST(%s IN NATURAL LANGUAGE MODE) DESC", quoteColumns(fields, "`", tablePrefix(op, table, "`")), quoteLiteral(keyWord, true)))
+	case "pgsql":
+		return gdb.Raw(fmt.Sprintf("ts_rank(%s, plainto_tsquery('%s', %s)) DESC", pgsqlTsVector(fields, s.FullText, tablePrefix(op, table, `"`)), pgsqlLanguage(s.FullText), quoteLiteral(keyWord, false)))
+	}
+	return ""
+}
+
+// 主表字段前缀,有别名时使用别名,否则使用表名,避免关联查询时字段歧义
+func tablePrefix(op *QueryOp, table string, quote string) string {
+	if op != nil && op.As != "" {
+		return op.As + "."
+	}
+	return quote + table + quote + "."
+}
+
+// pgsql 分词配置
+func pgsqlLanguage(op *FullTextOp) string {
+	if op == nil || op.Language == "" {
+		return "simple"
+	}
+	return op.Language
+}
+
+// pgsql 多字段拼接的 tsvector 表达式
+func pgsqlTsVector(fields []string, op *FullTextOp, prefix string) string {
+	var parts []string
+	for _, field := range fields {
+		parts = append(parts, fmt.Sprintf(`coalesce(%s"%s"::text, '')`, prefix, field))
+	}
+	return fmt.Sprintf("to_tsvector('%s', %s)", pgsqlLanguage(op), strings.Join(parts, " || ' ' || "))
+}
+
+// sqlite MATCH 使用短语查询,避免关键字中的特殊字符被解析为 fts5 语法
+func sqliteMatchPhrase(keyWord string) string {
+	return `"` + strings.ReplaceAll(keyWord, `"`, `""`) + `"`
+}
+
+// 字段加引号并用逗号连接
+func quoteColumns(fields []string, quote string, prefix string) string {
+	var columns []string
+	for _, field := range fields {
+		columns = append(columns, prefix+quote+field+quote)
+	}
+	return strings.Join(columns, ", ")
+}
+
+// 字符串转义为 sql 字面量, mysql 需要额外转义反斜杠
+func quoteLiteral(s string, escapeBackslash bool) string {
+	if escapeBackslash {
+		s = strings.ReplaceAll(s, `\`, `\\`)
+	}
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
@@ -0,0 +1,145 @@
+package dzhcore_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gzdzh-cn/dzhcore"
+	"github.com/gzdzh-cn/dzhcore/dzhcoretest"
+)
+
+type testArticle struct {
+	*dzhcore.Model
+	Title   string `gorm:"column:title;type:varchar(255)" json:"title"`
+	Content string `gorm:"column:content;type:text" json:"content"`
+}
+
+func (*testArticle) TableName() string {
+	return "test_article"
+}
+
+func (*testArticle) GroupName() string {
+	return "default"
+}
+
+// 未注册控制器的表
+type testNote struct {
+	*dzhcore.Model
+	Title string `gorm:"column:title;type:varchar(255)" json:"title"`
+}
+
+func (*testNote) TableName() string {
+	return "test_note"
+}
+
+func (*testNote) GroupName() string {
+	return "default"
+}
+
+func init() {
+	s := dzhcore.NewModelService(&testArticle{})
+	s.Dao = dzhcore.NewModelDao(&testArticle{})
+	s.FullText = &dzhcore.FullTextOp{}
+	s.PageQueryOp = &dzhcore.QueryOp{KeyWordField: []string{"title", "content"}}
+	testOptions.Models = append(testOptions.Models, &testArticle{}, &testNote{})
+	testOptions.Controllers = append(testOptions.Controllers, &dzhcore.Controller{
+		Prefix:  "/admin/test/article",
+		Api:     []string{"Page"},
+		Service: s,
+	})
+}
+
+func setupArticle(t *testing.T) *dzhcoretest.App {
+	app := dzhcoretest.Setup(t)
+	app.Fixture(t, "test_article", g.List{
+		{"id": "1", "title": "golang database", "content": "sqlite fts5 search"},
+		{"id": "2", "title": "release notes", "content": "golang golang golang"},
+		{"id": "3", "title": "other", "content": "nothing here"},
+	})
+	return app
+}
+
+func articleIds(t *testing.T, res *dzhcoretest.Response) string {
+	t.Helper()
+	if !res.Ok() {
+		t.Fatalf("分页失败: %s", res.Body)
+	}
+	var ids []string
+	for _, item := range res.Json().Get("list").Maps() {
+		ids = append(ids, g.NewVar(item["id"]).String())
+	}
+	return strings.Join(ids, ",")
+}
+
+func TestFullTextSearch(t *testing.T) {
+	app := setupArticle(t)
+	if ids := articleIds(t, app.Client.Page(t, "/admin/test/article", g.Map{"keyWord": "golang", "order": "id", "sort": "asc"})); ids != "1,2" {
+		t.Fatalf("搜索结果 %s, 期望 1,2", ids)
+	}
+	// 按相关度排序,出现次数多的在前
+	if ids := articleIds(t, app.Client.Page(t, "/admin/test/article", g.Map{"keyWord": "golang", "order": dzhcore.FullTextOrderRelevance})); ids != "2,1" {
+		t.Fatalf("相关度排序 %s, 期望 2,1", ids)
+	}
+	// 关键字中的特殊字符不解析为全文检索语法
+	for _, keyWord := range []string{`"golang`, `golang*`, `(golang)`, `golang OR other`} {
+		res := app.Client.Page(t, "/admin/test/article", g.Map{"keyWord": keyWord})
+		if !res.Ok() {
+			t.Fatalf("关键字 %s 查询失败: %s", keyWord, res.Body)
+		}
+	}
+}
+
+// 全文检索字段变化时重建虚拟表、触发器和索引数据
+func TestFullTextRebuild(t *testing.T) {
+	app := setupArticle(t)
+	defer func() {
+		if err := dzhcore.CreateFullText("default", "test_article", []string{"title", "content"}, &dzhcore.FullTextOp{}); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	if err := dzhcore.CreateFullText("default", "test_article", []string{"title"}, &dzhcore.FullTextOp{}); err != nil {
+		t.Fatal(err)
+	}
+	sql, err := g.DB().GetValue(app.Ctx, "SELECT sql FROM sqlite_master WHERE name = 'test_article_fts'")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(sql.String(), "content\"") {
+		t.Fatalf("重建后的虚拟表仍包含 content: %s", sql)
+	}
+	// 字段不变时不重建,直接写入索引的数据保留
+	if _, err = g.DB().Exec(app.Ctx, `INSERT INTO test_article_fts(rowid, title) VALUES (999, 'marker')`); err != nil {
+		t.Fatal(err)
+	}
+	if err = dzhcore.CreateFullText("default", "test_article", []string{"title"}, &dzhcore.FullTextOp{}); err != nil {
+		t.Fatal(err)
+	}
+	if count, _ := g.DB().GetValue(app.Ctx, `SELECT count(*) FROM test_article_fts WHERE test_article_fts MATCH 'marker'`); count.Int() != 1 {
+		t.Fatal("字段不变时重建了全文索引")
+	}
+	// 已有数据重新写入索引,新数据通过重建的触发器写入
+	app.Fixture(t, "test_article", g.List{{"id": "4", "title": "golang new"}})
+	count, err := g.DB().GetValue(app.Ctx, `SELECT count(*) FROM test_article_fts WHERE test_article_fts MATCH 'golang'`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count.Int() != 2 {
+		t.Fatalf("标题匹配 %d 条, 期望 2", count.Int())
+	}
+}
+
+// 未通过控制器注册的 Service 在第一次使用时创建全文索引
+func TestFullTextWithoutController(t *testing.T) {
+	app := dzhcoretest.Setup(t)
+	s := dzhcore.NewModelService(&testNote{})
+	s.Dao = dzhcore.NewModelDao(&testNote{})
+	s.FullText = &dzhcore.FullTextOp{Fields: []string{"title"}}
+	if !s.FullTextEnabled() {
+		t.Fatal("全文索引未就绪")
+	}
+	count, err := g.DB().GetValue(app.Ctx, "SELECT count(*) FROM sqlite_master WHERE name = 'test_note_fts'")
+	if err != nil || count.Int() != 1 {
+		t.Fatalf("未创建全文索引虚拟表: %v", err)
+	}
+}
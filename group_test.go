@@ -0,0 +1,78 @@
+package dzhcore_test
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gzdzh-cn/dzhcore"
+	"github.com/gzdzh-cn/dzhcore/dzhcoretest"
+)
+
+type testLog struct {
+	*dzhcore.Model
+	Content string `gorm:"column:content;type:varchar(255)" json:"content"`
+}
+
+func (*testLog) TableName() string {
+	return "test_log"
+}
+
+func (*testLog) GroupName() string {
+	return "log"
+}
+
+func init() {
+	s := dzhcore.NewModelService(&testLog{})
+	s.Dao = dzhcore.NewModelDao(&testLog{})
+	testOptions.Models = append(testOptions.Models, &testLog{})
+	testOptions.Controllers = append(testOptions.Controllers, &dzhcore.Controller{
+		Prefix:  "/admin/test/log",
+		Api:     []string{"Add", "Page"},
+		Service: s,
+	})
+}
+
+// 读取 database 下的全部分组,多节点写法同样处理 sqlite 配置
+func TestDbGroups(t *testing.T) {
+	dzhcoretest.Setup(t)
+	if got := strings.Join(dzhcore.DbGroups, ","); got != "default,log" {
+		t.Fatalf("DbGroups %s, 期望 default,log", got)
+	}
+	cfg := g.DB("log").GetConfig()
+	if cfg.Type != "sqlite" || filepath.Base(cfg.Name) != "log.sqlite" || cfg.Host != "" {
+		t.Fatalf("log 分组配置 type=%s name=%s host=%s", cfg.Type, cfg.Name, cfg.Host)
+	}
+	if filepath.Base(g.DB().GetConfig().Name) != "dzhcore.sqlite" {
+		t.Fatalf("default 分组 name=%s", g.DB().GetConfig().Name)
+	}
+}
+
+// 模型建表、控制器事务和 gorm 连接都使用模型所在的分组
+func TestModelGroup(t *testing.T) {
+	app := dzhcoretest.Setup(t)
+	res := app.Client.Add(t, "/admin/test/log", g.Map{"content": "a"})
+	if !res.Ok() {
+		t.Fatalf("新增失败: %s", res.Body)
+	}
+	if count, err := g.DB("log").Model("test_log").Count(); err != nil || count != 1 {
+		t.Fatalf("log 分组 %d 条, 期望 1: %v", count, err)
+	}
+	if fields, _ := g.DB().TableFields(app.Ctx, "test_log"); len(fields) > 0 {
+		t.Fatal("default 分组中创建了 test_log")
+	}
+
+	db, err := dzhcore.GetDBbyGroup("log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var content string
+	if err = db.Table("test_log").Select("content").Scan(&content).Error; err != nil || content != "a" {
+		t.Fatalf("gorm 读取 %q, 期望 a: %v", content, err)
+	}
+	page := app.Client.Page(t, "/admin/test/log", nil)
+	if total := page.Json().Get("pagination.total").Int(); total != 1 {
+		t.Fatalf("分页总数 %d, 期望 1: %s", total, page.Body)
+	}
+}
@@ -3,22 +3,21 @@ package dzhcore
 import (
 	"gorm.io/gorm"
 
-	"github.com/gogf/gf/v2/database/gdb"
-
 	"strings"
+	"sync"
 
 	"github.com/gogf/gf/v2/container/garray"
 	"github.com/gzdzh-cn/dzhcore/coreconfig"
 	"github.com/gzdzh-cn/dzhcore/coredb"
 
-	"github.com/gogf/gf/v2/encoding/gjson"
-	"github.com/gogf/gf/v2/errors/gerror"
 	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gfile"
 	"github.com/gogf/gf/v2/os/gres"
 )
 
 var (
 	Models []IModel
+	gormMu sync.Mutex // 保护 GormDBS
 )
 
 // IModel数组
@@ -27,12 +26,25 @@ func AddModel(model IModel) {
 }
 
 func InitModels() {
+	// 检查表结构差异,需在自动建表之前
+	checkSchema()
 	if coreconfig.Config.Core.AutoMigrate {
-		g.Log().Debugf(ctx, "InitModels,数量： %v", len(Models))
-		for _, model := range Models {
-			g.Log().Debugf(ctx, "model: %v", model.TableName())
-			CreateTable(model)
-		}
+		AutoMigrateModels()
+	}
+	// 执行数据库迁移
+	runMigrations()
+	// 创建全文索引,需在建表和迁移之后,不依赖 autoMigrate
+	initFullText()
+	// 执行种子数据
+	runSeeds()
+}
+
+// AutoMigrateModels 根据已注册的模型自动建表
+func AutoMigrateModels() {
+	g.Log().Debugf(ctx, "InitModels,数量： %v", len(Models))
+	for _, model := range Models {
+		g.Log().Debugf(ctx, "model: %v", model.TableName())
+		CreateTable(model)
 	}
 }
 
@@ -54,28 +66,40 @@ func InitDB(group string) (*gorm.DB, error) {
 	if err != nil {
 		panic(err.Error())
 	}
+	// 使用与 g.DB() 相同的连接池配置
+	if err = setGormPool(db, config); err != nil {
+		panic(err.Error())
+	}
+	if coreconfig.Config.Core.SQLObserver.Enable {
+		if err = db.Use(&sqlObserverPlugin{group: group}); err != nil {
+			panic(err.Error())
+		}
+	}
 
 	GormDBS[group] = db
 	return db, nil
 }
 
+// 根据分组获取 *gorm.DB,首次使用时创建连接
+func getDBbyGroup(group string) (*gorm.DB, error) {
+	if group == "" {
+		group = "default"
+	}
+	gormMu.Lock()
+	defer gormMu.Unlock()
+	if db, ok := GormDBS[group]; ok {
+		return db, nil
+	}
+	return InitDB(group)
+}
+
 // 根据entity结构体获取 *gorm.DB
 func getDBbyModel(model IModel) *gorm.DB {
-
-	group := model.GroupName()
-	// 判断是否存在 GormDBS[group] 字段，如果存在，则使用该字段的值作为DB，否则初始化DB
-	if _, ok := GormDBS[group]; ok {
-		return GormDBS[group]
-	} else {
-
-		db, err := InitDB(group)
-		if err != nil {
-			panic("failed to connect database")
-		}
-		// 把重新初始化的GormDBS存入全局变量中
-		GormDBS[group] = db
-		return db
+	db, err := getDBbyGroup(model.GroupName())
+	if err != nil {
+		panic("failed to connect database")
 	}
+	return db
 }
 
 // CreateTable 根据entity结构体创建表
@@ -87,71 +111,118 @@ func CreateTable(model IModel) error {
 }
 
 // FillInitData 数据库填充初始数据
+// 读取 <模块>/resource/initjson/<表名>.json,按 id 新增或更新,文件内容变化后重新写入
+// 数据没有 id 时按模型的唯一索引新增或更新,没有可用的唯一索引时只在第一次写入
+// 在 base_sys_init 中登记过但没有种子数据执行记录的表视为已初始化,只记录当前版本
 func FillInitData(ctx g.Ctx, moduleName string, model IModel) {
 
+	pathName := "addons/" + moduleName
+	if moduleName == "base" {
+		pathName = "internal"
+	}
+	seed := &Seed{
+		Module: moduleName,
+		Model:  model,
+		Path:   pathName + "/resource/initjson/" + model.TableName() + ".json",
+	}
+	if !gres.Contains(seed.Path) && !gfile.Exists(seed.Path) {
+		g.Log().Debugf(ctx, "分组%s中的表%s无可用的初始化数据,跳过本次初始化. path:%v", model.GroupName(), model.TableName(), seed.Path)
+		return
+	}
+	if err := initSeedTable(); err != nil {
+		g.Log().Errorf(ctx, "创建表 %s 失败: %s", TableNameSeed, err.Error())
+		return
+	}
+	rows, _, err := seed.load()
+	if err != nil {
+		g.Log().Error(ctx, err.Error())
+		return
+	}
+	seed.Keys, seed.insertOnly = initDataKeys(model, rows)
+
 	mInit := g.DB("default").Model("base_sys_init")
 	value, err := mInit.Clone().Where("group", model.GroupName()).Where("module", moduleName).Value("tables")
 	if err != nil {
 		g.Log().Errorf(ctx, "读取表 base_sys_init 失败: %s", err.Error())
 	}
+	tableGarry := garray.NewStrArray()
+	if !value.IsEmpty() {
+		tableGarry.Append(strings.Split(value.String(), ",")...)
+	}
+	initialized := tableGarry.Contains(model.TableName())
 
-	// 模块第一次写入数据
-	if value.IsEmpty() {
-		id := NodeSnowflake.Generate().String()
-		if err = updateData(ctx, mInit, moduleName, model); err == nil {
-			g.Log().Debugf(ctx, "分组 %v,模块 %v 中的表 %v，第一次写入", model.GroupName(), moduleName, model.TableName())
-			_, err = mInit.Insert(g.Map{"id": id, "group": model.GroupName(), "module": moduleName, "tables": model.TableName()})
-			if err != nil {
-				g.Log().Error(ctx, err.Error())
-			}
-		}
+	applied, err := applySeed(ctx, seed, false, initialized)
+	if err != nil {
+		g.Log().Error(ctx, err.Error())
 		return
 	}
-
-	tableArr := strings.Split(value.String(), ",")
-	tableGarry := garray.NewStrArrayFrom(tableArr)
-	//写入过了，跳过
-	if tableGarry.Contains(model.TableName()) {
-		g.Log().Debugf(ctx, "分组 %v, 模块 %v 中的表 %v, 已经初始化过,跳过本次初始化.", model.GroupName(), moduleName, model.TableName())
+	if !applied {
+		g.Log().Debugf(ctx, "分组 %v, 模块 %v 中的表 %v, 初始化数据未变化,跳过本次初始化.", model.GroupName(), moduleName, model.TableName())
+		return
+	}
+	if initialized {
 		return
 	}
 
-	//更新写入
-	if err = updateData(ctx, mInit, moduleName, model); err == nil {
-		g.Log().Debugf(ctx, "分组 %v, 模块 %v 中的表 %v, 写入 ", model.GroupName(), moduleName, model.TableName())
-		tableGarry.Append(model.TableName())
-		str := strings.Join(tableGarry.Slice(), ",")
-		_, err := mInit.Where("group", model.GroupName()).Where("module", moduleName).Data(g.Map{"tables": str}).Update()
-		if err != nil {
-			return
-		}
+	// 登记到 base_sys_init,兼容旧的初始化记录
+	tableGarry.Append(model.TableName())
+	if value.IsEmpty() {
+		_, err = mInit.Clone().Insert(g.Map{"id": NodeSnowflake.Generate().String(), "group": model.GroupName(), "module": moduleName, "tables": model.TableName()})
+	} else {
+		_, err = mInit.Clone().Where("group", model.GroupName()).Where("module", moduleName).Data(g.Map{"tables": strings.Join(tableGarry.Slice(), ",")}).Update()
+	}
+	if err != nil {
+		g.Log().Error(ctx, err.Error())
+		return
 	}
 
 	g.Log().Debugf(ctx, "分组 %v, 模块 %v 中的表 %v, 初始化完成 ", model.GroupName(), moduleName, model.TableName())
 }
 
-// 写入文件
-func updateData(ctx g.Ctx, mInit *gdb.Model, moduleName string, model IModel) error {
-
-	m := g.DB(model.GroupName()).Model(model.TableName())
-	pathName := "addons/" + moduleName
-	if moduleName == "base" {
-		pathName = "internal"
+// 初始化数据的唯一键,每条数据都有 id 时使用 id,否则使用每条数据都包含的模型唯一索引
+// 都没有时 insertOnly 为 true,兼容没有 id 的旧数据
+func initDataKeys(model IModel, rows g.List) (keys []string, insertOnly bool) {
+	if hasColumns(rows, []string{"id"}) {
+		return nil, false
 	}
-	path := pathName + "/resource/initjson/" + model.TableName() + ".json"
-	jsonData, _ := gjson.LoadContent(gres.GetContent(path))
-
-	g.Log().Debugf(ctx, "model.TableName(): %v,path:%v", model.TableName(), path)
-
-	if jsonData.Var().Clone().IsEmpty() {
-		g.Log().Debugf(ctx, "分组%s中的表%s无可用的初始化数据,跳过本次初始化. jsonData:%v", model.GroupName(), model.TableName(), jsonData)
-		return gerror.New("无可用的初始化数据,跳过本次初始化")
+	stmt := &gorm.Statement{DB: getDBbyModel(model)}
+	if err := stmt.Parse(model); err != nil {
+		return nil, true
 	}
-	_, err := m.Data(jsonData).Insert()
-	if err != nil {
-		g.Log().Error(ctx, err.Error())
-		return err
+	var uniques [][]string
+	for _, field := range stmt.Schema.Fields {
+		if field.Unique && field.DBName != "" {
+			uniques = append(uniques, []string{field.DBName})
+		}
 	}
+	for _, index := range stmt.Schema.ParseIndexes() {
+		if index.Class != "UNIQUE" {
+			continue
+		}
+		var columns []string
+		for _, option := range index.Fields {
+			if option.Field != nil {
+				columns = append(columns, option.DBName)
+			}
+		}
+		uniques = append(uniques, columns)
+	}
+	for _, columns := range uniques {
+		if len(columns) > 0 && hasColumns(rows, columns) {
+			return columns, false
+		}
+	}
+	return nil, true
+}
 
-	return nil
+// 每条数据是否都包含 columns 中的字段
+func hasColumns(rows g.List, columns []string) bool {
+	for _, row := range rows {
+		for _, column := range columns {
+			if _, ok := row[column]; !ok {
+				return false
+			}
+		}
+	}
+	return true
 }
@@ -0,0 +1,54 @@
+package dzhcore_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gogf/gf/v2/encoding/gjson"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gzdzh-cn/dzhcore/dzhcoretest"
+
+	_ "github.com/gzdzh-cn/dzhcore/contrib/drivers/sqlite"
+)
+
+// 测试应用在进程内只初始化一次,各测试文件在 init 中注册模型和控制器
+var testOptions = &dzhcoretest.Options{}
+
+// 默认分组使用 sqlite 文件,备份恢复需要替换数据库文件,log 分组用于测试多分组
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "dzhcore")
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	testOptions.Config = gjson.New(g.Map{
+		"database": g.Map{
+			"default": g.Map{
+				"type": "sqlite",
+				"name": filepath.Join(dir, "dzhcore.sqlite"),
+			},
+			// 多节点写法的分组
+			"log": g.Slice{g.Map{
+				"type":      "sqlite",
+				"name":      filepath.Join(dir, "log.sqlite"),
+				"host":      "ignored",
+				"createdAt": "createTime",
+				"updatedAt": "updateTime",
+			}},
+		},
+		"core": g.Map{
+			"backup":      g.Map{"path": filepath.Join(dir, "backup"), "keep": 3},
+			"sqlObserver": g.Map{"enable": true},
+		},
+	}).MustToJsonString()
+	if _, err = dzhcoretest.Boot(testOptions); err != nil {
+		fmt.Println(err)
+		os.RemoveAll(dir)
+		os.Exit(1)
+	}
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
@@ -1,6 +1,7 @@
 package dzhcore
 
 import (
+	"fmt"
 	"runtime"
 	"time"
 
@@ -12,11 +13,18 @@ import (
 
 func init() {
 	var s = g.Server()
+	//恢复数据库时等待进行中的请求结束并阻塞新的请求
+	s.BindMiddlewareDefault(DbGate)
 	//请求日志运行明细开启
 	if coreconfig.Config.Core.RunLogger.Enable {
 		s.BindMiddleware("/admin/*", RunLog) //请求日志明细
 		s.BindMiddleware("/app/*", RunLog)   //请求日志明细
 	}
+	//请求SQL统计开启
+	if coreconfig.Config.Core.SQLObserver.Enable {
+		s.BindMiddleware("/admin/*", SqlObserve)
+		s.BindMiddleware("/app/*", SqlObserve)
+	}
 
 }
 
@@ -31,6 +39,18 @@ func RunLog(r *ghttp.Request) {
 
 	r.Middleware.Next()
 
+	//请求SQL统计
+	var extra []string
+	if stats := GetRequestSqlStats(r.Context()); stats != nil {
+		extra = append(extra, fmt.Sprintf("[ info ] [ SQL ] [ 查询次数：%d ] [ 数据库耗时：%dms ]\n", stats.Count, stats.Cost))
+		nPlusOne := int64(coreconfig.Config.Core.SQLObserver.NPlusOne)
+		for sql, count := range stats.Statements {
+			if nPlusOne > 0 && count >= nPlusOne {
+				extra = append(extra, fmt.Sprintf("[ warning ] [ N+1 ] [ 重复 %d 次 ] [ %s ]\n", count, sql))
+			}
+		}
+	}
+
 	//日志打印运行时间
-	util.NewToolUtil().StdOutLog(ctx, startTime, memStatsStart)
+	util.NewToolUtil().StdOutLog(ctx, startTime, memStatsStart, extra...)
 }
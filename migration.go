@@ -0,0 +1,593 @@
+package dzhcore
+
+import (
+	"context"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gogf/gf/v2/database/gdb"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gtime"
+	"github.com/gzdzh-cn/dzhcore/coreconfig"
+)
+
+// 数据库迁移
+// 各模块在 init 中通过 AddMigration 注册 Go 迁移,或通过 AddMigrationFS 注册 <版本>.up.sql / <版本>.down.sql 文件
+// 迁移按版本号排序执行,执行记录保存在 core_migration 表中,core_migration_lock 表防止多个节点同时执行
+// AutoMigrate 建表后,开启 core.migration.autoRun 时启动自动执行未执行的迁移
+
+const (
+	TableNameMigration     = "core_migration"
+	TableNameMigrationLock = "core_migration_lock"
+	migrationLockId        = "migrate"
+)
+
+var (
+	Migrations []*Migration
+)
+
+// Migration 数据库迁移
+type Migration struct {
+	Version string                                     // 版本号,按字符串排序执行,如 20250101120000_create_user
+	Module  string                                     // 所属模块
+	Group   string                                     // 数据库分组,默认 default
+	Up      func(ctx context.Context, tx gdb.TX) error // 升级
+	Down    func(ctx context.Context, tx gdb.TX) error // 回滚
+	UpSql   string                                     // 升级sql,Up 为空时执行
+	DownSql string                                     // 回滚sql,Down 为空时执行
+}
+
+// MigrationStatus 迁移状态
+type MigrationStatus struct {
+	Version   string      `json:"version"`   // 版本号
+	Module    string      `json:"module"`    // 所属模块
+	Group     string      `json:"group"`     // 数据库分组
+	Applied   bool        `json:"applied"`   // 是否已执行
+	Batch     int         `json:"batch"`     // 执行批次
+	AppliedAt *gtime.Time `json:"appliedAt"` // 执行时间
+}
+
+// MigrationRecord 迁移记录,映射表 <core_migration>
+type MigrationRecord struct {
+	*Model
+	Version string `gorm:"column:version;comment:版本号;type:varchar(255);not null;uniqueIndex" json:"version"` // 版本号
+	Module  string `gorm:"column:module;comment:所属模块;type:varchar(255)" json:"module"`                       // 所属模块
+	Batch   int    `gorm:"column:batch;comment:执行批次;type:int;not null;default:0" json:"batch"`               // 执行批次
+}
+
+// TableName MigrationRecord 的表名
+func (*MigrationRecord) TableName() string {
+	return TableNameMigration
+}
+
+// GroupName MigrationRecord 的表分组
+func (*MigrationRecord) GroupName() string {
+	return "default"
+}
+
+// MigrationLock 迁移锁,映射表 <core_migration_lock>
+type MigrationLock struct {
+	*Model
+	Owner      string    `gorm:"column:owner;comment:持有者;type:varchar(255)" json:"owner"`   // 持有者
+	ExpireTime time.Time `gorm:"column:expireTime;comment:过期时间;not null" json:"expireTime"` // 过期时间
+}
+
+// TableName MigrationLock 的表名
+func (*MigrationLock) TableName() string {
+	return TableNameMigrationLock
+}
+
+// GroupName MigrationLock 的表分组
+func (*MigrationLock) GroupName() string {
+	return "default"
+}
+
+// AddMigration 注册迁移
+func AddMigration(migrations ...*Migration) {
+	Migrations = append(Migrations, migrations...)
+}
+
+// AddMigrationFS 注册目录中的sql迁移文件,文件名为 <版本>.up.sql 和 <版本>.down.sql
+//
+//	//go:embed *.sql
+//	var files embed.FS
+//
+//	func init() {
+//		dzhcore.AddMigrationFS("user", files, ".")
+//	}
+func AddMigrationFS(module string, fsys fs.FS, dir string, group ...string) error {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return err
+	}
+	migrations := make(map[string]*Migration)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".sql") {
+			continue
+		}
+		var version, direction string
+		switch {
+		case strings.HasSuffix(name, ".up.sql"):
+			version, direction = strings.TrimSuffix(name, ".up.sql"), "up"
+		case strings.HasSuffix(name, ".down.sql"):
+			version, direction = strings.TrimSuffix(name, ".down.sql"), "down"
+		default:
+			continue
+		}
+		content, err := fs.ReadFile(fsys, path.Join(dir, name))
+		if err != nil {
+			return err
+		}
+		migration, ok := migrations[version]
+		if !ok {
+			migration = &Migration{Version: version, Module: module}
+			if len(group) > 0 {
+				migration.Group = group[0]
+			}
+			migrations[version] = migration
+		}
+		if direction == "up" {
+			migration.UpSql = string(content)
+		} else {
+			migration.DownSql = string(content)
+		}
+	}
+	for _, migration := range migrations {
+		if migration.UpSql == "" {
+			return gerror.Newf("迁移 %s 缺少 up.sql 文件", migration.Version)
+		}
+		AddMigration(migration)
+	}
+	return nil
+}
+
+// 迁移的数据库分组
+func (m *Migration) group() string {
+	if m.Group == "" {
+		return "default"
+	}
+	return m.Group
+}
+
+// 按版本号排序的迁移,版本号重复时返回错误
+func sortedMigrations() ([]*Migration, error) {
+	sorted := make([]*Migration, len(Migrations))
+	copy(sorted, Migrations)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Version < sorted[j].Version })
+	for i := 1; i < len(sorted); i++ {
+		if sorted[i].Version == sorted[i-1].Version {
+			return nil, gerror.Newf("迁移版本号重复: %s", sorted[i].Version)
+		}
+	}
+	return sorted, nil
+}
+
+// 迁移涉及的数据库分组
+func migrationGroups() []string {
+	groups := []string{"default"}
+	seen := map[string]bool{"default": true}
+	for _, m := range Migrations {
+		if !seen[m.group()] {
+			seen[m.group()] = true
+			groups = append(groups, m.group())
+		}
+	}
+	return groups
+}
+
+// 创建迁移记录表
+func initMigrationTable() (err error) {
+	if err = CreateTable(&MigrationLock{}); err != nil {
+		return err
+	}
+	for _, group := range migrationGroups() {
+		db, err := getDBbyGroup(group)
+		if err != nil {
+			return err
+		}
+		if err = db.AutoMigrate(&MigrationRecord{}); err != nil {
+			return err
+		}
+	}
+	return
+}
+
+// 已执行的迁移记录 key:版本号
+func appliedMigrations(ctx context.Context) (records map[string]gdb.Record, err error) {
+	records = make(map[string]gdb.Record)
+	for _, group := range migrationGroups() {
+		result, err := g.DB(group).Model(TableNameMigration).Ctx(ctx).All()
+		if err != nil {
+			return nil, err
+		}
+		for _, record := range result {
+			record["group"] = g.NewVar(group)
+			records[record["version"].String()] = record
+		}
+	}
+	return
+}
+
+// 获取迁移锁,锁过期后可被其他节点获取
+// 持有期间每隔超时时间的三分之一续期,迁移执行时间超过超时时间时不会被其他节点获取,返回释放锁的函数
+func lockMigration(ctx context.Context) (unlock func(), err error) {
+	var (
+		db      = g.DB("default")
+		timeout = time.Duration(coreconfig.Config.Core.Migration.LockTimeout) * time.Second
+	)
+	if _, err = db.Model(TableNameMigrationLock).Ctx(ctx).Unscoped().Where("id", migrationLockId).WhereLT("expireTime", time.Now()).Delete(); err != nil {
+		return nil, err
+	}
+	_, err = db.Model(TableNameMigrationLock).Ctx(ctx).Data(g.Map{
+		"id":         migrationLockId,
+		"owner":      ProcessFlag,
+		"expireTime": time.Now().Add(timeout),
+	}).Insert()
+	if err != nil {
+		owner, e := db.Model(TableNameMigrationLock).Ctx(ctx).Unscoped().Where("id", migrationLockId).Value("owner")
+		if e != nil || owner.IsEmpty() {
+			return nil, err
+		}
+		return nil, gerror.Newf("其他节点 %s 正在执行迁移,请稍后再试", owner.String())
+	}
+
+	var (
+		done    = make(chan struct{})
+		stopped = make(chan struct{})
+	)
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(timeout / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				refreshMigrationLock(ctx, timeout)
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-stopped
+		unlockMigration(ctx)
+	}, nil
+}
+
+// 迁移锁续期
+func refreshMigrationLock(ctx context.Context, timeout time.Duration) {
+	result, err := g.DB("default").Model(TableNameMigrationLock).Ctx(ctx).Unscoped().
+		Where("id", migrationLockId).Where("owner", ProcessFlag).
+		Data(g.Map{"expireTime": time.Now().Add(timeout)}).Update()
+	if err != nil {
+		g.Log().Errorf(ctx, "迁移锁续期失败: %v", err)
+		return
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		g.Log().Errorf(ctx, "迁移锁已被其他节点获取")
+	}
+}
+
+// 释放迁移锁
+func unlockMigration(ctx context.Context) {
+	_, err := g.DB("default").Model(TableNameMigrationLock).Ctx(ctx).Unscoped().Where("id", migrationLockId).Where("owner", ProcessFlag).Delete()
+	if err != nil {
+		g.Log().Errorf(ctx, "释放迁移锁失败: %v", err)
+	}
+}
+
+// MigrateUp 执行未执行的迁移,steps 大于 0 时最多执行 steps 个,返回执行的版本号
+func MigrateUp(ctx context.Context, steps int) (versions []string, err error) {
+	migrations, err := sortedMigrations()
+	if err != nil {
+		return nil, err
+	}
+	if err = initMigrationTable(); err != nil {
+		return nil, err
+	}
+	unlock, err := lockMigration(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	applied, err := appliedMigrations(ctx)
+	if err != nil {
+		return nil, err
+	}
+	batch := 0
+	for _, record := range applied {
+		if record["batch"].Int() > batch {
+			batch = record["batch"].Int()
+		}
+	}
+	batch++
+
+	for _, m := range migrations {
+		if steps > 0 && len(versions) >= steps {
+			break
+		}
+		if _, ok := applied[m.Version]; ok {
+			continue
+		}
+		g.Log().Infof(ctx, "执行迁移: %s", m.Version)
+		err = g.DB(m.group()).Transaction(ctx, func(ctx context.Context, tx gdb.TX) error {
+			if err := runMigration(ctx, tx, m.Up, m.UpSql); err != nil {
+				return err
+			}
+			_, err := tx.Model(TableNameMigration).Ctx(ctx).Data(g.Map{
+				"id":      NodeSnowflake.Generate().String(),
+				"version": m.Version,
+				"module":  m.Module,
+				"batch":   batch,
+			}).Insert()
+			return err
+		})
+		if err != nil {
+			return versions, gerror.Wrapf(err, "执行迁移 %s 失败", m.Version)
+		}
+		versions = append(versions, m.Version)
+	}
+	return
+}
+
+// MigrateDown 按版本号倒序回滚已执行的迁移,steps 小于等于 0 时回滚 1 个,返回回滚的版本号
+func MigrateDown(ctx context.Context, steps int) (versions []string, err error) {
+	if steps <= 0 {
+		steps = 1
+	}
+	migrations, err := sortedMigrations()
+	if err != nil {
+		return nil, err
+	}
+	if err = initMigrationTable(); err != nil {
+		return nil, err
+	}
+	unlock, err := lockMigration(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	applied, err := appliedMigrations(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var appliedVersions []string
+	for version := range applied {
+		appliedVersions = append(appliedVersions, version)
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(appliedVersions)))
+
+	registered := make(map[string]*Migration, len(migrations))
+	for _, m := range migrations {
+		registered[m.Version] = m
+	}
+	for _, version := range appliedVersions {
+		if len(versions) >= steps {
+			break
+		}
+		m, ok := registered[version]
+		if !ok {
+			return versions, gerror.Newf("迁移 %s 已执行但未注册,无法回滚", version)
+		}
+		if m.Down == nil && m.DownSql == "" {
+			return versions, gerror.Newf("迁移 %s 不支持回滚", version)
+		}
+		g.Log().Infof(ctx, "回滚迁移: %s", m.Version)
+		err = g.DB(m.group()).Transaction(ctx, func(ctx context.Context, tx gdb.TX) error {
+			if err := runMigration(ctx, tx, m.Down, m.DownSql); err != nil {
+				return err
+			}
+			_, err := tx.Model(TableNameMigration).Ctx(ctx).Unscoped().Where("version", m.Version).Delete()
+			return err
+		})
+		if err != nil {
+			return versions, gerror.Wrapf(err, "回滚迁移 %s 失败", m.Version)
+		}
+		versions = append(versions, m.Version)
+	}
+	return
+}
+
+// GetMigrationStatus 获取全部迁移的执行状态,包含已执行但未注册的迁移
+func GetMigrationStatus(ctx context.Context) (list []*MigrationStatus, err error) {
+	migrations, err := sortedMigrations()
+	if err != nil {
+		return nil, err
+	}
+	if err = initMigrationTable(); err != nil {
+		return nil, err
+	}
+	applied, err := appliedMigrations(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range migrations {
+		status := &MigrationStatus{Version: m.Version, Module: m.Module, Group: m.group()}
+		if record, ok := applied[m.Version]; ok {
+			status.Applied = true
+			status.Batch = record["batch"].Int()
+			status.AppliedAt = record["createTime"].GTime()
+			delete(applied, m.Version)
+		}
+		list = append(list, status)
+	}
+	for version, record := range applied {
+		list = append(list, &MigrationStatus{
+			Version:   version,
+			Module:    record["module"].String(),
+			Group:     record["group"].String(),
+			Applied:   true,
+			Batch:     record["batch"].Int(),
+			AppliedAt: record["createTime"].GTime(),
+		})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Version < list[j].Version })
+	return
+}
+
+// 执行迁移函数或sql
+func runMigration(ctx context.Context, tx gdb.TX, fn func(ctx context.Context, tx gdb.TX) error, sql string) error {
+	if fn != nil {
+		return fn(ctx, tx)
+	}
+	for _, statement := range splitSqlStatements(sql, tx.GetDB().GetConfig().Type == "mysql") {
+		if _, err := tx.Exec(statement); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 按分号拆分多条sql语句,忽略引号、注释、$$ 字符串以及 BEGIN...END、CASE...END 块中的分号,只有注释的语句不返回
+// mysql 为 true 时引号中的反斜杠转义下一个字符,# 开头为注释;不支持客户端的 DELIMITER 命令
+func splitSqlStatements(sql string, mysql bool) (statements []string) {
+	var (
+		start   int  // 当前语句的开始位置
+		depth   int  // BEGIN、CASE 块的嵌套层数
+		hasCode bool // 当前语句是否有注释以外的内容
+		n       = len(sql)
+	)
+	for i := 0; i < n; i++ {
+		c := sql[i]
+		switch {
+		case c == ';' && depth == 0:
+			if hasCode {
+				statements = append(statements, strings.TrimSpace(sql[start:i]))
+			}
+			start, hasCode = i+1, false
+			continue
+		case c == '-' && i+1 < n && sql[i+1] == '-', c == '#' && mysql:
+			i = skipUntil(sql, i, "\n") - 1
+			continue
+		case c == '/' && i+1 < n && sql[i+1] == '*':
+			i = skipUntil(sql, i+2, "*/") - 1
+			continue
+		case c == '\'' || c == '"' || c == '`':
+			i = skipQuoted(sql, i, mysql && c != '`')
+		case c == '$':
+			if tag := dollarTag(sql, i); tag != "" {
+				i = skipUntil(sql, i+len(tag), tag) - 1
+			}
+		case isWordByte(c) && (i == 0 || !isWordByte(sql[i-1]) && sql[i-1] != '.'):
+			word := readWord(sql, i)
+			switch strings.ToUpper(word) {
+			case "BEGIN":
+				// BEGIN; BEGIN TRANSACTION 等为开启事务
+				switch strings.ToUpper(nextWord(sql, i+len(word))) {
+				case "", "TRANSACTION", "WORK", "DEFERRED", "IMMEDIATE", "EXCLUSIVE":
+				default:
+					depth++
+				}
+			case "CASE":
+				depth++
+			case "END":
+				// END IF、END LOOP 等结束的块没有计入层数
+				switch strings.ToUpper(nextWord(sql, i+len(word))) {
+				case "IF", "LOOP", "WHILE", "REPEAT", "FOR":
+				default:
+					if depth > 0 {
+						depth--
+					}
+				}
+			}
+			i += len(word) - 1
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			continue
+		}
+		hasCode = true
+	}
+	if hasCode {
+		statements = append(statements, strings.TrimSpace(sql[start:]))
+	}
+	return
+}
+
+// 从 i 开始查找 end,返回 end 之后的位置,找不到时返回 sql 的长度
+func skipUntil(sql string, i int, end string) int {
+	if i > len(sql) {
+		return len(sql)
+	}
+	if j := strings.Index(sql[i:], end); j >= 0 {
+		return i + j + len(end)
+	}
+	return len(sql)
+}
+
+// 跳过引号中的内容,返回结束引号的位置,两个连续的引号表示引号本身
+func skipQuoted(sql string, i int, backslash bool) int {
+	quote := sql[i]
+	for i++; i < len(sql); i++ {
+		switch sql[i] {
+		case '\\':
+			if backslash {
+				i++
+			}
+		case quote:
+			if i+1 < len(sql) && sql[i+1] == quote {
+				i++
+				continue
+			}
+			return i
+		}
+	}
+	return len(sql)
+}
+
+// pgsql 的 $tag$ 字符串开始标记,不是时返回空
+func dollarTag(sql string, i int) string {
+	if i > 0 && isWordByte(sql[i-1]) {
+		return ""
+	}
+	j := i + 1
+	for j < len(sql) && isWordByte(sql[j]) {
+		j++
+	}
+	if j >= len(sql) || sql[j] != '$' || j > i+1 && sql[i+1] >= '0' && sql[i+1] <= '9' {
+		return ""
+	}
+	return sql[i : j+1]
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+// 从 i 开始的单词
+func readWord(sql string, i int) string {
+	j := i
+	for j < len(sql) && isWordByte(sql[j]) {
+		j++
+	}
+	return sql[i:j]
+}
+
+// i 之后跳过空白的下一个单词,下一个字符不是单词时返回空
+func nextWord(sql string, i int) string {
+	for i < len(sql) && strings.IndexByte(" \t\r\n", sql[i]) >= 0 {
+		i++
+	}
+	return readWord(sql, i)
+}
+
+// 启动时执行未执行的迁移
+func runMigrations() {
+	if !coreconfig.Config.Core.Migration.AutoRun || len(Migrations) == 0 {
+		return
+	}
+	versions, err := MigrateUp(ctx, 0)
+	if err != nil {
+		g.Log().Errorf(ctx, "执行数据库迁移失败: %v", err)
+		panic(err)
+	}
+	if len(versions) > 0 {
+		g.Log().Infof(ctx, "执行数据库迁移 %d 个: %s", len(versions), strings.Join(versions, ","))
+	}
+}
@@ -0,0 +1,208 @@
+package dzhcore_test
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/gogf/gf/v2/database/gdb"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gzdzh-cn/dzhcore"
+	"github.com/gzdzh-cn/dzhcore/coreconfig"
+	"github.com/gzdzh-cn/dzhcore/dzhcoretest"
+)
+
+// 只执行测试注册的迁移,结束后恢复
+func setupMigration(t *testing.T, migrations ...*dzhcore.Migration) *dzhcoretest.App {
+	app := dzhcoretest.Setup(t)
+	old := dzhcore.Migrations
+	dzhcore.Migrations = nil
+	dzhcore.AddMigration(migrations...)
+	t.Cleanup(func() { dzhcore.Migrations = old })
+	return app
+}
+
+func TestSplitSqlStatements(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		sql   string
+		mysql bool
+		want  []string
+	}{
+		{"多条语句", "CREATE TABLE a (id int);\nINSERT INTO a VALUES (1);", false,
+			[]string{"CREATE TABLE a (id int)", "INSERT INTO a VALUES (1)"}},
+		{"同一行多条语句", "DELETE FROM a; DELETE FROM b", false,
+			[]string{"DELETE FROM a", "DELETE FROM b"}},
+		{"引号中的分号", "INSERT INTO a VALUES ('x;y', \"it''s;\", 'a'';b');SELECT `c;d` FROM a", false,
+			[]string{"INSERT INTO a VALUES ('x;y', \"it''s;\", 'a'';b')", "SELECT `c;d` FROM a"}},
+		{"mysql 反斜杠转义", `INSERT INTO a VALUES ('x\';y');SELECT 1`, true,
+			[]string{`INSERT INTO a VALUES ('x\';y')`, "SELECT 1"}},
+		{"注释", "-- 注释;\nSELECT 1; /* 块;注释 */\n-- 只有注释的语句;\n", false,
+			[]string{"-- 注释;\nSELECT 1"}},
+		{"mysql # 注释", "# a;b\nSELECT 1;", true,
+			[]string{"# a;b\nSELECT 1"}},
+		{"触发器", "CREATE TRIGGER t AFTER INSERT ON a BEGIN\n  UPDATE b SET n = CASE WHEN n > 0 THEN n + 1 ELSE 1 END;\n  DELETE FROM c;\nEND;\nSELECT 1;", false,
+			[]string{"CREATE TRIGGER t AFTER INSERT ON a BEGIN\n  UPDATE b SET n = CASE WHEN n > 0 THEN n + 1 ELSE 1 END;\n  DELETE FROM c;\nEND", "SELECT 1"}},
+		{"存储过程", "CREATE PROCEDURE p() BEGIN IF 1 THEN SELECT 1; END IF; END;DROP TABLE x", true,
+			[]string{"CREATE PROCEDURE p() BEGIN IF 1 THEN SELECT 1; END IF; END", "DROP TABLE x"}},
+		{"开启事务", "BEGIN;UPDATE a SET begin_time = 1;END;", false,
+			[]string{"BEGIN", "UPDATE a SET begin_time = 1", "END"}},
+		{"pgsql $$ 字符串", "CREATE FUNCTION f() RETURNS int AS $$ BEGIN RETURN 1; END; $$ LANGUAGE plpgsql;SELECT $1", false,
+			[]string{"CREATE FUNCTION f() RETURNS int AS $$ BEGIN RETURN 1; END; $$ LANGUAGE plpgsql", "SELECT $1"}},
+	} {
+		if got := dzhcore.SplitSqlStatements(tc.sql, tc.mysql); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: %q, 期望 %q", tc.name, got, tc.want)
+		}
+	}
+}
+
+// 按版本号执行和回滚,与注册顺序无关
+func TestMigrateOrder(t *testing.T) {
+	var calls []string
+	migration := func(version string) *dzhcore.Migration {
+		return &dzhcore.Migration{
+			Version: version,
+			Module:  "test",
+			Up: func(ctx context.Context, tx gdb.TX) error {
+				calls = append(calls, "up "+version)
+				return nil
+			},
+			Down: func(ctx context.Context, tx gdb.TX) error {
+				calls = append(calls, "down "+version)
+				return nil
+			},
+		}
+	}
+	app := setupMigration(t, migration("003"), migration("001"), migration("002"))
+
+	versions, err := dzhcore.MigrateUp(app.Ctx, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(versions, ",") != "001,002" {
+		t.Fatalf("执行 %v, 期望 [001 002]", versions)
+	}
+	if versions, err = dzhcore.MigrateUp(app.Ctx, 0); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(versions, ",") != "003" {
+		t.Fatalf("执行 %v, 期望 [003]", versions)
+	}
+	if versions, err = dzhcore.MigrateDown(app.Ctx, 2); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(versions, ",") != "003,002" {
+		t.Fatalf("回滚 %v, 期望 [003 002]", versions)
+	}
+	want := "up 001,up 002,up 003,down 003,down 002"
+	if got := strings.Join(calls, ","); got != want {
+		t.Fatalf("调用 %s, 期望 %s", got, want)
+	}
+
+	list, err := dzhcore.GetMigrationStatus(app.Ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var applied []string
+	for _, status := range list {
+		if status.Applied {
+			applied = append(applied, status.Version)
+		}
+	}
+	if len(list) != 3 || strings.Join(applied, ",") != "001" {
+		t.Fatalf("迁移状态 %d 个,已执行 %v, 期望 3 个,已执行 [001]", len(list), applied)
+	}
+}
+
+// sql 文件中的触发器和带分号的字符串作为完整语句执行
+func TestMigrateSqlFile(t *testing.T) {
+	app := setupMigration(t)
+	err := dzhcore.AddMigrationFS("test", fstest.MapFS{
+		"001_counter.up.sql": {Data: []byte(`
+-- 计数表;
+CREATE TABLE test_counter (id INTEGER PRIMARY KEY, name TEXT, total INTEGER);
+CREATE TRIGGER test_counter_total AFTER INSERT ON test_counter BEGIN
+	UPDATE test_counter SET total = CASE WHEN NEW.id > 1 THEN 2 ELSE 1 END WHERE id = NEW.id;
+END;
+INSERT INTO test_counter (id, name) VALUES (1, 'a;b');
+`)},
+		"001_counter.down.sql": {Data: []byte("DROP TABLE test_counter;")},
+	}, ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = dzhcore.MigrateUp(app.Ctx, 0); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { dzhcore.MigrateDown(context.Background(), 1) })
+
+	record, err := g.DB().Model("test_counter").One()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if record["name"].String() != "a;b" || record["total"].Int() != 1 {
+		t.Fatalf("迁移后数据 %v, 期望 name=a;b total=1", record)
+	}
+}
+
+// 其他节点持有未过期的锁时不能执行,锁过期后可以执行
+func TestMigrateLock(t *testing.T) {
+	app := setupMigration(t, &dzhcore.Migration{
+		Version: "001",
+		Up:      func(ctx context.Context, tx gdb.TX) error { return nil },
+	})
+	if _, err := dzhcore.MigrateUp(app.Ctx, 0); err != nil {
+		t.Fatal(err)
+	}
+	app.Reset(t)
+
+	app.Fixture(t, dzhcore.TableNameMigrationLock, g.List{{"id": "migrate", "owner": "other", "expireTime": time.Now().Add(time.Minute)}})
+	if _, err := dzhcore.MigrateUp(app.Ctx, 0); err == nil || !strings.Contains(err.Error(), "other") {
+		t.Fatalf("其他节点持有锁时执行迁移: %v", err)
+	}
+
+	app.Reset(t)
+	app.Fixture(t, dzhcore.TableNameMigrationLock, g.List{{"id": "migrate", "owner": "other", "expireTime": time.Now().Add(-time.Minute)}})
+	versions, err := dzhcore.MigrateUp(app.Ctx, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(versions) != 1 {
+		t.Fatalf("锁过期后执行 %v, 期望 [001]", versions)
+	}
+	// 执行完成后释放锁
+	if count, _ := g.DB().Model(dzhcore.TableNameMigrationLock).Unscoped().Count(); count != 0 {
+		t.Fatalf("执行完成后仍有 %d 个迁移锁", count)
+	}
+}
+
+// 迁移执行时间超过锁超时时间时自动续期
+func TestMigrateLockRefresh(t *testing.T) {
+	timeout := coreconfig.Config.Core.Migration.LockTimeout
+	coreconfig.Config.Core.Migration.LockTimeout = 1
+	t.Cleanup(func() { coreconfig.Config.Core.Migration.LockTimeout = timeout })
+
+	var expireTime *time.Time
+	app := setupMigration(t, &dzhcore.Migration{
+		Version: "001",
+		Up: func(ctx context.Context, tx gdb.TX) error {
+			time.Sleep(1500 * time.Millisecond)
+			value, err := g.DB().Model(dzhcore.TableNameMigrationLock).Ctx(ctx).Unscoped().Where("id", "migrate").Value("expireTime")
+			if err != nil {
+				return err
+			}
+			expire := value.Time()
+			expireTime = &expire
+			return nil
+		},
+	})
+	if _, err := dzhcore.MigrateUp(app.Ctx, 0); err != nil {
+		t.Fatal(err)
+	}
+	if expireTime == nil || !expireTime.After(time.Now()) {
+		t.Fatalf("执行 1.5 秒后迁移锁过期时间 %v, 期望已续期", expireTime)
+	}
+}
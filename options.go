@@ -0,0 +1,223 @@
+package dzhcore
+
+import (
+	"context"
+	"regexp"
+	"time"
+
+	"github.com/gogf/gf/v2/container/garray"
+	"github.com/gogf/gf/v2/database/gdb"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/text/gstr"
+	"github.com/gogf/gf/v2/util/gconv"
+)
+
+// 名称模板中的字段 如 {name}
+var optionsTemplatePattern = regexp.MustCompile(`\{(\w+)\}`)
+
+// 下拉选项配置
+type OptionsOp struct {
+	ValueField     string                                   // 值字段,默认 id
+	LabelField     string                                   // 名称字段,默认 name
+	LabelTemplate  string                                   // 名称模板,如 {name}({phone}),不为空时优先于 LabelField
+	SearchFields   []string                                 // 关键字搜索的字段,为空时使用名称字段
+	Fields         []string                                 // 额外返回的字段
+	DistinctFields []string                                 // 允许获取去重值的字段,只有列出的字段可以获取去重值
+	AddOrderby     g.MapStrStr                              // 排序
+	Where          func(ctx context.Context) []g.Array      // 数据范围条件,为空时使用 PageQueryOp 的 Where
+	Extend         func(ctx g.Ctx, m *gdb.Model) *gdb.Model // 追加其他条件
+}
+
+// 下拉选项
+func (s *Service) ServiceOptions(ctx context.Context, req *OptionsReq) (data any, err error) {
+	var (
+		r            = g.RequestFromCtx(ctx)
+		op           = s.OptionsOp
+		dbRedisSlice g.SliceAny
+	)
+	if op == nil {
+		op = &OptionsOp{}
+	}
+	if req.Size <= 0 {
+		req.Size = 20
+	}
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	dbRedisSlice = append(dbRedisSlice, []any{r.Router.Uri, req.Page, req.Size, req.KeyWord, req.Ids, req.Distinct}...)
+
+	m := DDAO(s.Dao, ctx)
+
+	// 数据范围
+	where := op.Where
+	if where == nil && s.PageQueryOp != nil {
+		where = s.PageQueryOp.Where
+	}
+	if where != nil {
+		for _, v := range where(ctx) {
+			switch len(v) {
+			case 3:
+				if gconv.Bool(v[2]) {
+					m = m.Where(v[0], v[1])
+					dbRedisSlice = append(dbRedisSlice, gconv.String(v[0])+"-"+gconv.String(v[1]))
+				}
+			case 2:
+				m = m.Where(v[0], v[1])
+				dbRedisSlice = append(dbRedisSlice, gconv.String(v[0])+"-"+gconv.String(v[1]))
+			case 1:
+				m = m.Where(v[0])
+				dbRedisSlice = append(dbRedisSlice, gconv.String(v[0]))
+			}
+		}
+	}
+	if op.Extend != nil {
+		m = op.Extend(ctx, m)
+	}
+	if DbRedisEnable {
+		m = m.Cache(gdb.CacheOption{
+			Duration: time.Duration(DbExpire),
+			Name:     gstr.Replace(gstr.JoinAny(dbRedisSlice, "/"), " ", "&&"),
+			Force:    false,
+		})
+	}
+
+	if req.Distinct != "" {
+		return s.distinctOptions(ctx, m, op, req)
+	}
+
+	var (
+		valueField  = op.ValueField
+		labelFields []string
+	)
+	if valueField == "" {
+		valueField = "id"
+	}
+	if op.LabelTemplate != "" {
+		for _, match := range optionsTemplatePattern.FindAllStringSubmatch(op.LabelTemplate, -1) {
+			labelFields = append(labelFields, match[1])
+		}
+	} else if op.LabelField != "" {
+		labelFields = []string{op.LabelField}
+	} else {
+		labelFields = []string{"name"}
+	}
+	fields := garray.NewStrArrayFrom([]string{valueField})
+	fields.Append(labelFields...)
+	fields.Append(op.Fields...)
+	m = m.Fields(fields.Unique().Slice())
+
+	var (
+		result gdb.Result
+		total  int
+	)
+	if req.Ids != "" {
+		// 已选中的值,用于远程下拉框回显
+		result, err = m.WhereIn(valueField, gstr.SplitAndTrim(req.Ids, ",")).All()
+		total = len(result)
+	} else {
+		if req.KeyWord != "" {
+			searchFields := op.SearchFields
+			if len(searchFields) == 0 {
+				searchFields = labelFields
+			}
+			builder := m.Builder()
+			for _, field := range searchFields {
+				builder = builder.WhereOrLike(field, "%"+req.KeyWord+"%")
+			}
+			m = m.Where(builder)
+		}
+		for field, order := range op.AddOrderby {
+			m = m.Order(field, order)
+		}
+		result, total, err = m.Page(req.Page, req.Size).AllAndCount(false)
+	}
+	if err != nil {
+		return nil, err
+	}
+	// 值和名称按原字段读取,FieldRules 的重命名、隐藏只作用于额外返回的字段
+	raw := make(gdb.Result, len(result))
+	for i, record := range result {
+		raw[i] = make(gdb.Record, len(record))
+		for k, v := range record {
+			raw[i][k] = v
+		}
+	}
+	result = s.applyFieldRules(ctx, result)
+
+	list := make(g.List, 0, len(result))
+	for i, record := range result {
+		item := g.Map{"value": raw[i][valueField].Val()}
+		if op.LabelTemplate != "" {
+			item["label"] = optionsTemplatePattern.ReplaceAllStringFunc(op.LabelTemplate, func(match string) string {
+				return optionsLabelValue(raw[i], record, match[1:len(match)-1], s.FieldRules).String()
+			})
+		} else {
+			item["label"] = optionsLabelValue(raw[i], record, labelFields[0], s.FieldRules).String()
+		}
+		for _, field := range op.Fields {
+			key := field
+			if rule, ok := s.FieldRules[field]; ok && rule.Rename != "" {
+				key = rule.Rename
+			}
+			if value, ok := record[key]; ok {
+				item[key] = value.Val()
+			}
+		}
+		list = append(list, item)
+	}
+	data = g.Map{
+		"list": list,
+		"pagination": g.Map{
+			"page":  req.Page,
+			"size":  req.Size,
+			"total": total,
+		},
+	}
+	return
+}
+
+// 名称字段的值,按原字段名读取,不受 FieldRules 重命名、隐藏影响,掩码和格式化仍然生效
+func optionsLabelValue(raw, record gdb.Record, field string, rules map[string]*FieldRule) *g.Var {
+	rule, ok := rules[field]
+	if !ok || rule.Hide {
+		return raw[field]
+	}
+	key := field
+	if rule.Rename != "" {
+		key = rule.Rename
+	}
+	if value, ok := record[key]; ok {
+		return value
+	}
+	return raw[field]
+}
+
+// 字段去重值,用于筛选下拉框
+func (s *Service) distinctOptions(ctx context.Context, m *gdb.Model, op *OptionsOp, req *OptionsReq) (data any, err error) {
+	if !garray.NewStrArrayFrom(op.DistinctFields).Contains(req.Distinct) {
+		return nil, gerror.Newf("字段 %s 不允许获取去重值", req.Distinct)
+	}
+	m = m.Fields(req.Distinct).Distinct().WhereNotNull(req.Distinct)
+	if req.KeyWord != "" {
+		m = m.WhereLike(req.Distinct, "%"+req.KeyWord+"%")
+	}
+	result, total, err := m.Order(req.Distinct, "ASC").Page(req.Page, req.Size).AllAndCount(true)
+	if err != nil {
+		return nil, err
+	}
+	list := make(g.List, 0, len(result))
+	for _, record := range result {
+		value := record[req.Distinct]
+		list = append(list, g.Map{"value": value.Val(), "label": value.String()})
+	}
+	data = g.Map{
+		"list": list,
+		"pagination": g.Map{
+			"page":  req.Page,
+			"size":  req.Size,
+			"total": total,
+		},
+	}
+	return
+}
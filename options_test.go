@@ -0,0 +1,123 @@
+package dzhcore_test
+
+import (
+	"testing"
+
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gzdzh-cn/dzhcore"
+	"github.com/gzdzh-cn/dzhcore/dzhcoretest"
+)
+
+type testContact struct {
+	*dzhcore.Model
+	Name  string `gorm:"column:name;type:varchar(255)" json:"name"`
+	Phone string `gorm:"column:phone;type:varchar(20)" json:"phone"`
+	City  string `gorm:"column:city;type:varchar(50)" json:"city"`
+}
+
+func (*testContact) TableName() string {
+	return "test_contact"
+}
+
+func (*testContact) GroupName() string {
+	return "default"
+}
+
+func init() {
+	s := dzhcore.NewModelService(&testContact{})
+	s.Dao = dzhcore.NewModelDao(&testContact{})
+	s.OptionsOp = &dzhcore.OptionsOp{
+		LabelTemplate:  "{name}({phone})",
+		SearchFields:   []string{"name", "phone"},
+		Fields:         []string{"city"},
+		DistinctFields: []string{"city"},
+		AddOrderby:     g.MapStrStr{"name": "ASC"},
+	}
+	s.FieldRules = map[string]*dzhcore.FieldRule{
+		"name":  {Rename: "contactName"},
+		"phone": {Mask: "phone"},
+		"city":  {Rename: "area"},
+	}
+	testOptions.Models = append(testOptions.Models, &testContact{})
+	testOptions.Controllers = append(testOptions.Controllers, &dzhcore.Controller{
+		Prefix:  "/admin/test/contact",
+		Api:     []string{"Options"},
+		Service: s,
+	})
+}
+
+func setupOptions(t *testing.T) *dzhcoretest.App {
+	app := dzhcoretest.Setup(t)
+	app.Fixture(t, "test_contact", g.List{
+		{"id": "1", "name": "张三", "phone": "13800001111", "city": "广州"},
+		{"id": "2", "name": "李四", "phone": "13800002222", "city": "深圳"},
+		{"id": "3", "name": "王五", "phone": "13900003333", "city": "广州"},
+	})
+	return app
+}
+
+func optionsList(t *testing.T, res *dzhcoretest.Response) []g.Map {
+	t.Helper()
+	if !res.Ok() {
+		t.Fatalf("获取下拉选项失败: %s", res.Body)
+	}
+	return res.Json().Get("list").Maps()
+}
+
+// 名称按原字段读取,重命名不影响名称,掩码仍然生效
+func TestOptionsLabel(t *testing.T) {
+	app := setupOptions(t)
+	list := optionsList(t, app.Client.Get(t, "/admin/test/contact/options", g.Map{"keyWord": "张"}))
+	if len(list) != 1 {
+		t.Fatalf("搜索结果 %d 条, 期望 1", len(list))
+	}
+	item := list[0]
+	if item["value"] != "1" || item["label"] != "张三(138****1111)" {
+		t.Fatalf("选项 %v, 期望 value 1 label 张三(138****1111)", item)
+	}
+	// 额外字段按 FieldRules 重命名
+	if item["area"] != "广州" {
+		t.Fatalf("额外字段 %v, 期望 area 广州", item)
+	}
+}
+
+func TestOptionsSearchAndPage(t *testing.T) {
+	app := setupOptions(t)
+	res := app.Client.Get(t, "/admin/test/contact/options", g.Map{"keyWord": "1380000", "size": 1, "page": 2})
+	list := optionsList(t, res)
+	if total := res.Json().Get("pagination.total").Int(); total != 2 {
+		t.Fatalf("搜索总数 %d, 期望 2", total)
+	}
+	// 按名称升序,第二页为李四
+	if len(list) != 1 || list[0]["value"] != "2" {
+		t.Fatalf("第二页 %v, 期望李四", list)
+	}
+}
+
+// 已选中的值回显,不受分页影响
+func TestOptionsIds(t *testing.T) {
+	app := setupOptions(t)
+	list := optionsList(t, app.Client.Get(t, "/admin/test/contact/options", g.Map{"ids": "1,3", "size": 1}))
+	if len(list) != 2 {
+		t.Fatalf("回显 %d 条, 期望 2", len(list))
+	}
+	for _, item := range list {
+		if item["value"] != "1" && item["value"] != "3" {
+			t.Fatalf("回显了未选中的值 %v", item)
+		}
+	}
+}
+
+// 只有 DistinctFields 中的字段可以获取去重值
+func TestOptionsDistinct(t *testing.T) {
+	app := setupOptions(t)
+	list := optionsList(t, app.Client.Get(t, "/admin/test/contact/options", g.Map{"distinct": "city"}))
+	if len(list) != 2 || list[0]["value"] != "广州" || list[1]["value"] != "深圳" {
+		t.Fatalf("去重值 %v, 期望 [广州 深圳]", list)
+	}
+	for _, field := range []string{"phone", "name", "id"} {
+		if res := app.Client.Get(t, "/admin/test/contact/options", g.Map{"distinct": field}); res.Ok() {
+			t.Fatalf("未配置的字段 %s 获取到去重值: %s", field, res.Body)
+		}
+	}
+}
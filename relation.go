@@ -0,0 +1,325 @@
+package dzhcore
+
+import (
+	"context"
+	"strings"
+
+	"github.com/gogf/gf/v2/database/gdb"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/util/gconv"
+)
+
+// 关联类型
+const (
+	HasOne     RelationType = "HasOne"     // 一对一,关联表中保存本表主键
+	HasMany    RelationType = "HasMany"    // 一对多,关联表中保存本表主键
+	BelongsTo  RelationType = "BelongsTo"  // 从属,本表中保存关联表主键
+	ManyToMany RelationType = "ManyToMany" // 多对多,通过中间表关联
+)
+
+// 关联类型
+type RelationType string
+
+// Relation 模型关联
+type Relation struct {
+	Type            RelationType
+	Model           IModel                                   // 关联的model
+	Dao             IDao                                     // 关联的dao,不为空时优先使用
+	ForeignKey      string                                   // HasOne/HasMany: 关联表中指向本表的字段; BelongsTo: 本表中指向关联表的字段
+	LocalKey        string                                   // HasOne/HasMany: 本表字段; BelongsTo/ManyToMany: 关联表字段, 默认 id
+	Pivot           string                                   // ManyToMany 中间表
+	PivotLocalKey   string                                   // ManyToMany 中间表中指向本表的字段
+	PivotForeignKey string                                   // ManyToMany 中间表中指向关联表的字段
+	Fields          string                                   // 查询字段,多个字段用逗号隔开,为空时查询全部字段,没有关联字段时自动追加
+	Extend          func(ctx g.Ctx, m *gdb.Model) *gdb.Model // 追加其他条件
+}
+
+// IRelationModel 声明了关联关系的模型
+//
+//	func (*Order) Relations() map[string]*dzhcore.Relation {
+//		return map[string]*dzhcore.Relation{
+//			"items":    {Type: dzhcore.HasMany, Model: &OrderItem{}, ForeignKey: "orderId"},
+//			"customer": {Type: dzhcore.BelongsTo, Model: &Customer{}, ForeignKey: "customerId"},
+//			"tags":     {Type: dzhcore.ManyToMany, Model: &Tag{}, Pivot: "order_tag", PivotLocalKey: "orderId", PivotForeignKey: "tagId"},
+//		}
+//	}
+type IRelationModel interface {
+	Relations() map[string]*Relation
+}
+
+// 关联表查询
+func (rel *Relation) model(ctx context.Context) *gdb.Model {
+	if rel.Dao != nil {
+		return DDAO(rel.Dao, ctx)
+	}
+	return DBM(rel.Model).Ctx(ctx)
+}
+
+// 关联表字段,默认 id
+func (rel *Relation) localKey() string {
+	if rel.LocalKey == "" {
+		return "id"
+	}
+	return rel.LocalKey
+}
+
+// 中间表查询
+func (rel *Relation) pivot(ctx context.Context) *gdb.Model {
+	group := "default"
+	if rel.Dao != nil {
+		group = rel.Dao.Group()
+	} else if rel.Model != nil {
+		group = rel.Model.GroupName()
+	}
+	return g.DB(group).Model(rel.Pivot).Ctx(ctx)
+}
+
+// 获取 Service 模型声明的关联
+func (s *Service) getRelation(name string) (*Relation, error) {
+	if s.Model == nil {
+		return nil, gerror.Newf("Service 未设置模型,无法加载关联 %s", name)
+	}
+	m, ok := s.Model.(IRelationModel)
+	if !ok {
+		return nil, gerror.Newf("模型 %s 未声明关联关系", s.Model.TableName())
+	}
+	rel, ok := m.Relations()[name]
+	if !ok || rel == nil {
+		return nil, gerror.Newf("模型 %s 未声明关联 %s", s.Model.TableName(), name)
+	}
+	return rel, nil
+}
+
+// 按 With 批量预加载关联数据,每个关联只查询一次
+func (s *Service) loadRelations(ctx context.Context, result gdb.Result) gdb.Result {
+	for _, name := range s.With {
+		rel, err := s.getRelation(name)
+		if err != nil {
+			g.Log().Error(ctx, err.Error())
+			continue
+		}
+		if err = loadRelation(ctx, name, rel, result); err != nil {
+			g.Log().Errorf(ctx, "加载关联 %s 失败: %v", name, err)
+		}
+	}
+	return result
+}
+
+// 加载单个关联
+func loadRelation(ctx context.Context, name string, rel *Relation, result gdb.Result) (err error) {
+	var (
+		ownKey string // 本表中用于关联的字段
+		relKey string // 关联表中用于匹配的字段
+	)
+	switch rel.Type {
+	case HasOne, HasMany:
+		ownKey, relKey = rel.localKey(), rel.ForeignKey
+	case BelongsTo:
+		ownKey, relKey = rel.ForeignKey, rel.localKey()
+	case ManyToMany:
+		return loadManyToMany(ctx, name, rel, result)
+	default:
+		return gerror.Newf("不支持的关联类型 %s", rel.Type)
+	}
+
+	if err = checkRelationKey(result, ownKey); err != nil {
+		return err
+	}
+	values := recordValues(result, ownKey)
+	if len(values) == 0 {
+		return
+	}
+	related, err := rel.query(ctx, relKey, values)
+	if err != nil {
+		return err
+	}
+	grouped := make(map[string]g.List)
+	for _, item := range related {
+		key := item[relKey].String()
+		grouped[key] = append(grouped[key], item.Map())
+	}
+	for _, record := range result {
+		value, ok := record[ownKey]
+		if !ok {
+			continue
+		}
+		items := grouped[value.String()]
+		if rel.Type == HasMany {
+			if items == nil {
+				items = g.List{}
+			}
+			record[name] = g.NewVar(items)
+			continue
+		}
+		if len(items) > 0 {
+			record[name] = g.NewVar(items[0])
+		} else {
+			record[name] = g.NewVar(nil)
+		}
+	}
+	return
+}
+
+// 加载多对多关联
+func loadManyToMany(ctx context.Context, name string, rel *Relation, result gdb.Result) (err error) {
+	if err = checkRelationKey(result, "id"); err != nil {
+		return err
+	}
+	ids := recordValues(result, "id")
+	if len(ids) == 0 {
+		return
+	}
+	pivots, err := rel.pivot(ctx).Fields(rel.PivotLocalKey, rel.PivotForeignKey).WhereIn(rel.PivotLocalKey, ids).All()
+	if err != nil {
+		return err
+	}
+	var (
+		foreignIds = make([]interface{}, 0, len(pivots))
+		pivotMap   = make(map[string][]string)
+	)
+	for _, pivot := range pivots {
+		localId, foreignId := pivot[rel.PivotLocalKey].String(), pivot[rel.PivotForeignKey].String()
+		pivotMap[localId] = append(pivotMap[localId], foreignId)
+		foreignIds = append(foreignIds, foreignId)
+	}
+	relatedMap := make(map[string]g.Map)
+	if len(foreignIds) > 0 {
+		related, err := rel.query(ctx, rel.localKey(), foreignIds)
+		if err != nil {
+			return err
+		}
+		for _, item := range related {
+			relatedMap[item[rel.localKey()].String()] = item.Map()
+		}
+	}
+	for _, record := range result {
+		items := g.List{}
+		for _, foreignId := range pivotMap[record["id"].String()] {
+			if item, ok := relatedMap[foreignId]; ok {
+				items = append(items, item)
+			}
+		}
+		record[name] = g.NewVar(items)
+	}
+	return
+}
+
+// 按 key 批量查询关联表,Fields 中没有 key 时自动追加,否则无法按 key 匹配关联数据
+func (rel *Relation) query(ctx context.Context, key string, values []interface{}) (gdb.Result, error) {
+	m := rel.model(ctx).WhereIn(key, values)
+	if rel.Fields != "" {
+		m = m.Fields(relationFields(rel.Fields, key))
+	}
+	if rel.Extend != nil {
+		m = rel.Extend(ctx, m)
+	}
+	return m.All()
+}
+
+// 查询字段中没有 key 时追加 key,支持 a.key、x AS key 的写法
+func relationFields(fields string, key string) string {
+	for _, field := range strings.Split(fields, ",") {
+		words := strings.Fields(field)
+		if len(words) == 0 {
+			continue
+		}
+		name := words[len(words)-1]
+		if i := strings.LastIndex(name, "."); i >= 0 {
+			name = name[i+1:]
+		}
+		if name = strings.Trim(name, "`\""); name == "*" || name == key {
+			return fields
+		}
+	}
+	return fields + "," + key
+}
+
+// 本表查询结果中必须有用于关联的字段,查询字段中缺少时返回错误
+func checkRelationKey(result gdb.Result, key string) error {
+	if len(result) == 0 {
+		return nil
+	}
+	if _, ok := result[0][key]; !ok {
+		return gerror.Newf("查询结果中缺少关联字段 %s,请在查询字段中添加", key)
+	}
+	return nil
+}
+
+// 结果集中某字段的去重值
+func recordValues(result gdb.Result, field string) (values []interface{}) {
+	seen := make(map[string]struct{})
+	for _, record := range result {
+		value, ok := record[field]
+		if !ok || value.IsEmpty() {
+			continue
+		}
+		if _, ok = seen[value.String()]; ok {
+			continue
+		}
+		seen[value.String()] = struct{}{}
+		values = append(values, value.Val())
+	}
+	return
+}
+
+// SyncRelation 同步多对多中间表,删除不在 ids 中的关联并写入新的关联
+func (s *Service) SyncRelation(ctx context.Context, name string, id interface{}, ids []interface{}) (err error) {
+	rel, err := s.getRelation(name)
+	if err != nil {
+		return err
+	}
+	if rel.Type != ManyToMany {
+		return gerror.Newf("关联 %s 不是多对多关联,不支持同步", name)
+	}
+	if _, err = rel.pivot(ctx).Unscoped().Where(rel.PivotLocalKey, id).Delete(); err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		return
+	}
+	fields, err := rel.pivot(ctx).TableFields(rel.Pivot)
+	if err != nil {
+		return err
+	}
+	_, hasId := fields["id"]
+	data := make(g.List, 0, len(ids))
+	for _, foreignId := range ids {
+		item := g.Map{rel.PivotLocalKey: id, rel.PivotForeignKey: foreignId}
+		if hasId {
+			item["id"] = NodeSnowflake.Generate().String()
+		}
+		data = append(data, item)
+	}
+	_, err = rel.pivot(ctx).Data(data).Insert()
+	return
+}
+
+// 新增、修改时按 With 同步请求中携带的多对多关联
+func (s *Service) syncRelations(ctx context.Context, id interface{}, rmap g.Map) (err error) {
+	for _, name := range s.With {
+		value, ok := rmap[name]
+		if !ok {
+			continue
+		}
+		rel, err := s.getRelation(name)
+		if err != nil {
+			return err
+		}
+		if rel.Type != ManyToMany {
+			continue
+		}
+		if err = s.SyncRelation(ctx, name, id, gconv.SliceAny(value)); err != nil {
+			return err
+		}
+	}
+	return
+}
+
+// 关联查询的表名,Dao 不为空时优先使用 Dao
+func joinTable(join *JoinOp) string {
+	if join.Dao != nil {
+		return join.Dao.Table()
+	}
+	return join.Model.TableName()
+}
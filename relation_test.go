@@ -0,0 +1,211 @@
+package dzhcore_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gogf/gf/v2/database/gdb"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gzdzh-cn/dzhcore"
+	"github.com/gzdzh-cn/dzhcore/dzhcoretest"
+)
+
+type testOrder struct {
+	*dzhcore.Model
+	No         string `gorm:"column:no;type:varchar(50)" json:"no"`
+	CustomerId string `gorm:"column:customerId;type:varchar(255)" json:"customerId"`
+}
+
+func (*testOrder) TableName() string {
+	return "test_order"
+}
+
+func (*testOrder) GroupName() string {
+	return "default"
+}
+
+// 关联查询次数 key:关联名称
+var relationQueries = make(map[string]int)
+
+func countQuery(name string) func(ctx g.Ctx, m *gdb.Model) *gdb.Model {
+	return func(ctx g.Ctx, m *gdb.Model) *gdb.Model {
+		relationQueries[name]++
+		return m
+	}
+}
+
+// 关联的查询字段都不包含用于匹配的字段
+func (*testOrder) Relations() map[string]*dzhcore.Relation {
+	return map[string]*dzhcore.Relation{
+		"items":    {Type: dzhcore.HasMany, Model: &testOrderItem{}, ForeignKey: "orderId", Fields: "name", Extend: countQuery("items")},
+		"customer": {Type: dzhcore.BelongsTo, Model: &testCustomer{}, ForeignKey: "customerId", Fields: "name", Extend: countQuery("customer")},
+		"labels": {Type: dzhcore.ManyToMany, Model: &testLabel{}, Pivot: "test_order_label", PivotLocalKey: "orderId", PivotForeignKey: "labelId",
+			Fields: "name", Extend: countQuery("labels")},
+	}
+}
+
+type testOrderItem struct {
+	*dzhcore.Model
+	OrderId string `gorm:"column:orderId;type:varchar(255)" json:"orderId"`
+	Name    string `gorm:"column:name;type:varchar(255)" json:"name"`
+}
+
+func (*testOrderItem) TableName() string {
+	return "test_order_item"
+}
+
+func (*testOrderItem) GroupName() string {
+	return "default"
+}
+
+type testCustomer struct {
+	*dzhcore.Model
+	Name string `gorm:"column:name;type:varchar(255)" json:"name"`
+}
+
+func (*testCustomer) TableName() string {
+	return "test_customer"
+}
+
+func (*testCustomer) GroupName() string {
+	return "default"
+}
+
+type testLabel struct {
+	*dzhcore.Model
+	Name string `gorm:"column:name;type:varchar(255)" json:"name"`
+}
+
+func (*testLabel) TableName() string {
+	return "test_label"
+}
+
+func (*testLabel) GroupName() string {
+	return "default"
+}
+
+type testOrderLabel struct {
+	*dzhcore.Model
+	OrderId string `gorm:"column:orderId;type:varchar(255)" json:"orderId"`
+	LabelId string `gorm:"column:labelId;type:varchar(255)" json:"labelId"`
+}
+
+func (*testOrderLabel) TableName() string {
+	return "test_order_label"
+}
+
+func (*testOrderLabel) GroupName() string {
+	return "default"
+}
+
+func init() {
+	s := dzhcore.NewModelService(&testOrder{})
+	s.Dao = dzhcore.NewModelDao(&testOrder{})
+	s.With = []string{"items", "customer", "labels"}
+	s.ListQueryOp = &dzhcore.QueryOp{AddOrderby: g.MapStrStr{"no": "ASC"}}
+	// 查询字段中没有本表用于关联的 customerId
+	selected := dzhcore.NewModelService(&testOrder{})
+	selected.Dao = dzhcore.NewModelDao(&testOrder{})
+	selected.With = []string{"customer"}
+	selected.ListQueryOp = &dzhcore.QueryOp{Select: "id,no"}
+	testOptions.Models = append(testOptions.Models, &testOrder{}, &testOrderItem{}, &testCustomer{}, &testLabel{}, &testOrderLabel{})
+	testOptions.Controllers = append(testOptions.Controllers,
+		&dzhcore.Controller{Prefix: "/admin/test/order", Api: []string{"List", "Info"}, Service: s},
+		&dzhcore.Controller{Prefix: "/admin/test/orderSelect", Api: []string{"List"}, Service: selected},
+	)
+}
+
+func setupOrder(t *testing.T, orders int) *dzhcoretest.App {
+	app := dzhcoretest.Setup(t)
+	app.Fixture(t, "test_customer", g.List{{"id": "c1", "name": "张三"}, {"id": "c2", "name": "李四"}})
+	app.Fixture(t, "test_label", g.List{{"id": "l1", "name": "加急"}, {"id": "l2", "name": "赠品"}})
+	for i := 1; i <= orders; i++ {
+		id := g.NewVar(i).String()
+		customer := "c1"
+		if i%2 == 0 {
+			customer = "c2"
+		}
+		app.Fixture(t, "test_order", g.List{{"id": id, "no": "no" + id, "customerId": customer}})
+		app.Fixture(t, "test_order_item", g.List{{"orderId": id, "name": "a" + id}, {"orderId": id, "name": "b" + id}})
+		app.Fixture(t, "test_order_label", g.List{{"orderId": id, "labelId": "l1"}})
+	}
+	clear(relationQueries)
+	return app
+}
+
+// 每个关联只查询一次,关联的查询字段中没有匹配字段时仍能匹配
+func TestLoadRelations(t *testing.T) {
+	app := setupOrder(t, 2)
+	res := app.Client.Post(t, "/admin/test/order/list", nil)
+	if !res.Ok() {
+		t.Fatalf("列表失败: %s", res.Body)
+	}
+	list := res.Json()
+	if n := len(list.Array()); n != 2 {
+		t.Fatalf("列表 %d 条, 期望 2", n)
+	}
+	for i, want := range []struct {
+		customer string
+		items    []string
+	}{{"张三", []string{"a1", "b1"}}, {"李四", []string{"a2", "b2"}}} {
+		order := list.GetJson(g.NewVar(i).String())
+		if got := order.Get("customer.name").String(); got != want.customer {
+			t.Errorf("订单 %d 客户 %q, 期望 %q", i+1, got, want.customer)
+		}
+		var names []string
+		for _, item := range order.GetJsons("items") {
+			names = append(names, item.Get("name").String())
+		}
+		if !reflect.DeepEqual(names, want.items) {
+			t.Errorf("订单 %d 明细 %v, 期望 %v", i+1, names, want.items)
+		}
+		if got := order.Get("labels.0.name").String(); got != "加急" {
+			t.Errorf("订单 %d 标签 %q, 期望 加急", i+1, got)
+		}
+	}
+	for _, name := range []string{"items", "customer", "labels"} {
+		if relationQueries[name] != 1 {
+			t.Errorf("关联 %s 查询 %d 次, 期望 1", name, relationQueries[name])
+		}
+	}
+}
+
+// 数据条数增加时关联查询次数不变,不会每条数据查询一次
+func TestLoadRelationsNoNPlusOne(t *testing.T) {
+	app := setupOrder(t, 20)
+	res := app.Client.Post(t, "/admin/test/order/list", nil)
+	if !res.Ok() {
+		t.Fatalf("列表失败: %s", res.Body)
+	}
+	if n := len(res.Json().Array()); n != 20 {
+		t.Fatalf("列表 %d 条, 期望 20", n)
+	}
+	for _, name := range []string{"items", "customer", "labels"} {
+		if relationQueries[name] != 1 {
+			t.Errorf("20 条数据关联 %s 查询 %d 次, 期望 1", name, relationQueries[name])
+		}
+	}
+
+	info := app.Client.Get(t, "/admin/test/order/info", g.Map{"id": "3"})
+	if !info.Ok() {
+		t.Fatalf("详情失败: %s", info.Body)
+	}
+	if got := info.Json().Get("customer.name").String(); got != "张三" {
+		t.Fatalf("详情客户 %q, 期望 张三", got)
+	}
+}
+
+// 本表查询字段中没有关联字段时不加载关联
+func TestLoadRelationsMissingKey(t *testing.T) {
+	app := setupOrder(t, 1)
+	res := app.Client.Post(t, "/admin/test/orderSelect/list", nil)
+	if !res.Ok() {
+		t.Fatalf("列表失败: %s", res.Body)
+	}
+	if order := res.Json().GetJson("0"); order.Contains("customer") || order.Get("no").String() != "no1" {
+		t.Fatalf("列表数据 %s, 期望只有 id、no", order.MustToJsonString())
+	}
+	if relationQueries["customer"] != 0 {
+		t.Fatalf("缺少关联字段时查询了关联表 %d 次", relationQueries["customer"])
+	}
+}
@@ -0,0 +1,312 @@
+package dzhcore
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gzdzh-cn/dzhcore/coreconfig"
+	"github.com/gzdzh-cn/dzhcore/envconfig"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// 表结构差异类型
+type SchemaChangeKind string
+
+const (
+	SchemaMissingTable    SchemaChangeKind = "missingTable"    // 缺少表
+	SchemaMissingColumn   SchemaChangeKind = "missingColumn"   // 缺少列
+	SchemaExtraColumn     SchemaChangeKind = "extraColumn"     // 多余列
+	SchemaTypeChanged     SchemaChangeKind = "typeChanged"     // 列类型变更
+	SchemaNullableChanged SchemaChangeKind = "nullableChanged" // 列是否可空变更
+	SchemaMissingIndex    SchemaChangeKind = "missingIndex"    // 缺少索引
+	SchemaExtraIndex      SchemaChangeKind = "extraIndex"      // 多余索引
+	SchemaIndexChanged    SchemaChangeKind = "indexChanged"    // 索引列或唯一性变更
+)
+
+// 单项表结构差异
+type SchemaChange struct {
+	Kind        SchemaChangeKind `json:"kind"`        // 差异类型
+	Name        string           `json:"name"`        // 列名或索引名
+	Expected    string           `json:"expected"`    // 模型定义
+	Actual      string           `json:"actual"`      // 数据库现状
+	Destructive bool             `json:"destructive"` // 是否为破坏性变更,执行 AutoMigrate 可能丢失数据或失败
+	Message     string           `json:"message"`     // 说明
+}
+
+// 模型与数据库表的结构差异
+type SchemaDiff struct {
+	Group   string          `json:"group"`
+	Table   string          `json:"table"`
+	Changes []*SchemaChange `json:"changes"`
+}
+
+// Destructive 是否包含破坏性变更
+func (d *SchemaDiff) Destructive() bool {
+	for _, change := range d.Changes {
+		if change.Destructive {
+			return true
+		}
+	}
+	return false
+}
+
+// DiffSchema 对比全部已注册模型与数据库表结构,只返回存在差异的表
+func DiffSchema(ctx context.Context) (list []*SchemaDiff, err error) {
+	seen := make(map[string]bool)
+	for _, model := range Models {
+		key := model.GroupName() + "." + model.TableName()
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		diff, err := DiffModelSchema(model)
+		if err != nil {
+			return nil, gerror.Wrapf(err, "对比表 %s 结构失败", model.TableName())
+		}
+		if len(diff.Changes) > 0 {
+			list = append(list, diff)
+		}
+	}
+	return
+}
+
+// DiffModelSchema 对比模型与数据库表结构,只读取不修改
+func DiffModelSchema(model IModel) (diff *SchemaDiff, err error) {
+	db := getDBbyModel(model)
+	stmt := &gorm.Statement{DB: db}
+	if err = stmt.Parse(model); err != nil {
+		return nil, err
+	}
+	diff = &SchemaDiff{Group: model.GroupName(), Table: stmt.Schema.Table}
+
+	migrator := db.Migrator()
+	if !migrator.HasTable(model) {
+		diff.Changes = append(diff.Changes, &SchemaChange{
+			Kind:    SchemaMissingTable,
+			Name:    stmt.Schema.Table,
+			Message: "表不存在,将创建",
+		})
+		return diff, nil
+	}
+
+	columnTypes, err := migrator.ColumnTypes(model)
+	if err != nil {
+		return nil, err
+	}
+	columns := make(map[string]gorm.ColumnType, len(columnTypes))
+	for _, columnType := range columnTypes {
+		columns[strings.ToLower(columnType.Name())] = columnType
+	}
+
+	expected := make(map[string]bool)
+	for _, dbName := range stmt.Schema.DBNames {
+		field := stmt.Schema.FieldsByDBName[dbName]
+		if field.IgnoreMigration {
+			continue
+		}
+		expected[strings.ToLower(dbName)] = true
+		columnType, ok := columns[strings.ToLower(dbName)]
+		if !ok {
+			diff.Changes = append(diff.Changes, &SchemaChange{
+				Kind:     SchemaMissingColumn,
+				Name:     dbName,
+				Expected: db.Dialector.DataTypeOf(field),
+				Message:  "列不存在,将新增",
+			})
+			continue
+		}
+		diff.Changes = append(diff.Changes, diffColumn(db, field, columnType)...)
+	}
+	for _, columnType := range columnTypes {
+		if expected[strings.ToLower(columnType.Name())] {
+			continue
+		}
+		actual, _ := columnType.ColumnType()
+		diff.Changes = append(diff.Changes, &SchemaChange{
+			Kind:    SchemaExtraColumn,
+			Name:    columnType.Name(),
+			Actual:  actual,
+			Message: "模型中未定义该列,AutoMigrate 不会删除",
+		})
+	}
+
+	indexChanges, err := diffIndexes(db, model, stmt.Schema)
+	if err != nil {
+		return nil, err
+	}
+	diff.Changes = append(diff.Changes, indexChanges...)
+	return diff, nil
+}
+
+// 对比列类型、长度和是否可空,判断方式与 gorm 的 MigrateColumn 保持一致
+func diffColumn(db *gorm.DB, field *schema.Field, columnType gorm.ColumnType) (changes []*SchemaChange) {
+	var (
+		dataType = strings.ToLower(db.Dialector.DataTypeOf(field))
+		realType = strings.ToLower(columnType.DatabaseTypeName())
+		actual   = realType
+	)
+	if fullType, ok := columnType.ColumnType(); ok && fullType != "" {
+		actual = strings.ToLower(fullType)
+	}
+
+	if !field.PrimaryKey {
+		isSameType := strings.HasPrefix(dataType, realType)
+		if !isSameType {
+			for _, alias := range db.Migrator().GetTypeAliases(realType) {
+				if strings.HasPrefix(dataType, alias) {
+					isSameType = true
+					break
+				}
+			}
+		}
+		if !isSameType {
+			changes = append(changes, &SchemaChange{
+				Kind:        SchemaTypeChanged,
+				Name:        field.DBName,
+				Expected:    dataType,
+				Actual:      actual,
+				Destructive: true,
+				Message:     "列类型不一致,修改类型可能导致数据截断或转换失败",
+			})
+		} else if length, ok := columnType.Length(); ok && length > 0 && field.Size > 0 && length != int64(field.Size) {
+			changes = append(changes, &SchemaChange{
+				Kind:        SchemaTypeChanged,
+				Name:        field.DBName,
+				Expected:    dataType,
+				Actual:      actual,
+				Destructive: int64(field.Size) < length,
+				Message:     fmt.Sprintf("列长度不一致 %d -> %d", length, field.Size),
+			})
+		}
+	}
+
+	if nullable, ok := columnType.Nullable(); ok && !field.PrimaryKey && nullable == field.NotNull {
+		change := &SchemaChange{
+			Kind:     SchemaNullableChanged,
+			Name:     field.DBName,
+			Expected: nullableText(!field.NotNull),
+			Actual:   nullableText(nullable),
+			Message:  "列改为可空",
+		}
+		if field.NotNull {
+			// 已有空值时设置为 NOT NULL 会失败
+			change.Destructive = true
+			change.Message = "列改为不可空,已有空值时修改会失败"
+		}
+		changes = append(changes, change)
+	}
+	return
+}
+
+// 对比索引,忽略主键、数据库自动创建的索引和全文索引
+func diffIndexes(db *gorm.DB, model IModel, sch *schema.Schema) (changes []*SchemaChange, err error) {
+	indexes, err := db.Migrator().GetIndexes(model)
+	if err != nil {
+		return nil, err
+	}
+	actual := make(map[string]gorm.Index, len(indexes))
+	for _, index := range indexes {
+		if primary, ok := index.PrimaryKey(); ok && primary {
+			continue
+		}
+		if strings.HasPrefix(index.Name(), "sqlite_autoindex_") {
+			continue
+		}
+		// 全文索引由 Service.FullText 创建,不在模型中定义
+		if index.Name() == fullTextIndexName(sch.Table) {
+			continue
+		}
+		actual[index.Name()] = index
+	}
+
+	for _, index := range sch.ParseIndexes() {
+		var columns []string
+		for _, option := range index.Fields {
+			if option.Field != nil {
+				columns = append(columns, option.DBName)
+			}
+		}
+		expected := indexText(columns, index.Class == "UNIQUE")
+		current, ok := actual[index.Name]
+		if !ok {
+			changes = append(changes, &SchemaChange{
+				Kind:     SchemaMissingIndex,
+				Name:     index.Name,
+				Expected: expected,
+				Message:  "索引不存在,将创建",
+			})
+			continue
+		}
+		delete(actual, index.Name)
+		unique, _ := current.Unique()
+		if now := indexText(current.Columns(), unique); now != expected {
+			changes = append(changes, &SchemaChange{
+				Kind:     SchemaIndexChanged,
+				Name:     index.Name,
+				Expected: expected,
+				Actual:   now,
+				Message:  "索引定义不一致,AutoMigrate 不会修改已存在的索引",
+			})
+		}
+	}
+
+	names := make([]string, 0, len(actual))
+	for name := range actual {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		unique, _ := actual[name].Unique()
+		changes = append(changes, &SchemaChange{
+			Kind:    SchemaExtraIndex,
+			Name:    name,
+			Actual:  indexText(actual[name].Columns(), unique),
+			Message: "模型中未定义该索引,AutoMigrate 不会删除",
+		})
+	}
+	return
+}
+
+func nullableText(nullable bool) string {
+	if nullable {
+		return "NULL"
+	}
+	return "NOT NULL"
+}
+
+func indexText(columns []string, unique bool) string {
+	text := "(" + strings.Join(columns, ",") + ")"
+	if unique {
+		return "UNIQUE " + text
+	}
+	return text
+}
+
+// 启动时检查表结构,生产环境存在破坏性差异时拒绝启动
+func checkSchema() {
+	if !coreconfig.Config.Core.SchemaCheck.Enable {
+		return
+	}
+	list, err := DiffSchema(ctx)
+	if err != nil {
+		g.Log().Errorf(ctx, "表结构检查失败: %v", err)
+		panic(err)
+	}
+	var destructive []string
+	for _, diff := range list {
+		for _, change := range diff.Changes {
+			g.Log().Warningf(ctx, "表结构差异 %s.%s %s %s: %s", diff.Group, diff.Table, change.Kind, change.Name, change.Message)
+		}
+		if diff.Destructive() {
+			destructive = append(destructive, diff.Table)
+		}
+	}
+	if len(destructive) > 0 && envconfig.IsProd {
+		panic(gerror.Newf("表 %s 存在破坏性结构差异,拒绝启动,请先通过迁移处理", strings.Join(destructive, ",")))
+	}
+}
@@ -0,0 +1,75 @@
+package dzhcore_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gzdzh-cn/dzhcore"
+)
+
+// 测试中修改表结构,结束后恢复
+func alterTable(t *testing.T, sql string, revert string) {
+	t.Helper()
+	if _, err := g.DB().Exec(t.Context(), sql); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if _, err := g.DB().Exec(context.Background(), revert); err != nil {
+			t.Errorf("恢复表结构失败: %v", err)
+		}
+	})
+}
+
+func schemaChanges(t *testing.T, model dzhcore.IModel) map[string]dzhcore.SchemaChangeKind {
+	t.Helper()
+	diff, err := dzhcore.DiffModelSchema(model)
+	if err != nil {
+		t.Fatal(err)
+	}
+	changes := make(map[string]dzhcore.SchemaChangeKind)
+	for _, change := range diff.Changes {
+		changes[change.Name] = change.Kind
+	}
+	return changes
+}
+
+// 建表后模型与表结构一致,全文索引及其虚拟表、触发器不算差异
+func TestDiffSchemaFullText(t *testing.T) {
+	app := setupArticle(t)
+	if changes := schemaChanges(t, &testArticle{}); len(changes) != 0 {
+		t.Fatalf("建表后存在差异 %v", changes)
+	}
+	// mysql、pgsql 的全文索引名
+	alterTable(t, "CREATE INDEX ft_test_article ON test_article (title)", "DROP INDEX ft_test_article")
+	if changes := schemaChanges(t, &testArticle{}); len(changes) != 0 {
+		t.Fatalf("全文索引被当作差异 %v", changes)
+	}
+	list, err := dzhcore.DiffSchema(app.Ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, diff := range list {
+		t.Errorf("表 %s 存在差异 %+v", diff.Table, diff.Changes[0])
+	}
+}
+
+// 模型未定义的索引和列
+func TestDiffSchemaExtra(t *testing.T) {
+	setupArticle(t)
+	alterTable(t, "CREATE INDEX idx_test_article_title ON test_article (title)", "DROP INDEX idx_test_article_title")
+	alterTable(t, "ALTER TABLE test_article ADD COLUMN summary varchar(255)", "ALTER TABLE test_article DROP COLUMN summary")
+	want := map[string]dzhcore.SchemaChangeKind{
+		"idx_test_article_title": dzhcore.SchemaExtraIndex,
+		"summary":                dzhcore.SchemaExtraColumn,
+	}
+	changes := schemaChanges(t, &testArticle{})
+	if len(changes) != len(want) {
+		t.Fatalf("差异 %v, 期望 %v", changes, want)
+	}
+	for name, kind := range want {
+		if changes[name] != kind {
+			t.Errorf("%s 的差异 %s, 期望 %s", name, changes[name], kind)
+		}
+	}
+}
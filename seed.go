@@ -0,0 +1,580 @@
+package dzhcore
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gogf/gf/v2/container/garray"
+	"github.com/gogf/gf/v2/crypto/gmd5"
+	"github.com/gogf/gf/v2/database/gdb"
+	"github.com/gogf/gf/v2/encoding/gjson"
+	"github.com/gogf/gf/v2/encoding/gyaml"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gfile"
+	"github.com/gogf/gf/v2/os/gres"
+	"github.com/gogf/gf/v2/os/gtime"
+	"github.com/gogf/gf/v2/util/gconv"
+	"github.com/gzdzh-cn/dzhcore/coreconfig"
+)
+
+// 种子数据
+// 各模块在 init 中通过 AddSeed 注册种子数据,或通过 AddSeedFS 注册目录中的 <表名>.json/.yaml/.yml/.csv 文件
+// 按唯一键判断记录是否存在,存在则更新否则新增,可重复执行
+// 执行记录保存在 core_seed 表中,版本号变化后重新执行,未指定版本号时使用数据内容的校验值
+// 执行记录同时保存上次写入的数据,重新执行时已存在的记录只更新种子数据中变化的字段,不覆盖用户修改过的其他数据,force 时全部覆盖
+
+const (
+	TableNameSeed = "core_seed"
+)
+
+var (
+	Seeds []*Seed
+
+	seedTableOnce sync.Once
+	seedTableErr  error
+)
+
+// Seed 种子数据集
+type Seed struct {
+	Name    string   // 名称,同模块内唯一,默认为表名
+	Module  string   // 所属模块
+	Group   string   // 数据库分组,为空时使用 Model 的分组,默认 default
+	Table   string   // 表名,为空时使用 Model 的表名
+	Model   IModel   // 模型
+	Version string   // 版本号,为空时使用数据内容的校验值
+	Keys    []string // 唯一键,默认 id
+	Format  string   // 文件格式 json、yaml、csv,为空时按扩展名判断
+	FS      fs.FS    // 读取 Path 的文件系统,为空时先读取 gres 资源再读取本地文件
+	Path    string   // 数据文件路径
+	Data    g.List   // 直接提供的数据,不为空时忽略 Path
+
+	insertOnly bool // 没有唯一键,只在第一次执行时新增,用于没有 id 的旧 initjson 数据
+}
+
+// SeedStatus 种子数据执行状态
+type SeedStatus struct {
+	Name           string      `json:"name"`           // 名称
+	Module         string      `json:"module"`         // 所属模块
+	Group          string      `json:"group"`          // 数据库分组
+	Table          string      `json:"table"`          // 表名
+	Version        string      `json:"version"`        // 当前版本号
+	AppliedVersion string      `json:"appliedVersion"` // 已执行的版本号
+	Rows           int         `json:"rows"`           // 已执行的数据条数
+	AppliedAt      *gtime.Time `json:"appliedAt"`      // 执行时间
+}
+
+// Pending 是否需要执行
+func (s *SeedStatus) Pending() bool {
+	return s.Version != s.AppliedVersion
+}
+
+// SeedRecord 种子数据执行记录,映射表 <core_seed>
+type SeedRecord struct {
+	*Model
+	Module  string `gorm:"column:module;comment:所属模块;type:varchar(255);not null;uniqueIndex:idx_core_seed_module_name" json:"module"` // 所属模块
+	Name    string `gorm:"column:name;comment:名称;type:varchar(255);not null;uniqueIndex:idx_core_seed_module_name" json:"name"`       // 名称
+	Version string `gorm:"column:version;comment:版本号;type:varchar(255)" json:"version"`                                               // 版本号
+	Rows    int    `gorm:"column:rows;comment:数据条数;type:int;not null;default:0" json:"rows"`                                          // 数据条数
+	Data    string `gorm:"column:data;comment:上次写入的数据" json:"-"`                                                                      // 上次写入的数据
+}
+
+// TableName SeedRecord 的表名
+func (*SeedRecord) TableName() string {
+	return TableNameSeed
+}
+
+// GroupName SeedRecord 的表分组
+func (*SeedRecord) GroupName() string {
+	return "default"
+}
+
+// AddSeed 注册种子数据
+func AddSeed(seeds ...*Seed) {
+	Seeds = append(Seeds, seeds...)
+}
+
+// AddSeedFS 注册目录中的种子数据文件,文件名为 <表名>.json、<表名>.yaml、<表名>.yml 或 <表名>.csv
+//
+//	//go:embed seed
+//	var files embed.FS
+//
+//	func init() {
+//		dzhcore.AddSeedFS("user", files, "seed")
+//	}
+func AddSeedFS(module string, fsys fs.FS, dir string, group ...string) error {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		ext := path.Ext(name)
+		if entry.IsDir() || seedFormat(ext) == "" {
+			continue
+		}
+		seed := &Seed{
+			Name:   strings.TrimSuffix(name, ext),
+			Module: module,
+			Table:  strings.TrimSuffix(name, ext),
+			FS:     fsys,
+			Path:   path.Join(dir, name),
+		}
+		if len(group) > 0 {
+			seed.Group = group[0]
+		}
+		AddSeed(seed)
+	}
+	return nil
+}
+
+// 按扩展名判断文件格式,不支持时返回空
+func seedFormat(ext string) string {
+	switch strings.ToLower(strings.TrimPrefix(ext, ".")) {
+	case "json":
+		return "json"
+	case "yaml", "yml":
+		return "yaml"
+	case "csv":
+		return "csv"
+	}
+	return ""
+}
+
+// 种子数据的表名
+func (s *Seed) table() string {
+	if s.Table == "" && s.Model != nil {
+		return s.Model.TableName()
+	}
+	return s.Table
+}
+
+// 种子数据的名称
+func (s *Seed) name() string {
+	if s.Name == "" {
+		return s.table()
+	}
+	return s.Name
+}
+
+// 种子数据的数据库分组
+func (s *Seed) group() string {
+	if s.Group != "" {
+		return s.Group
+	}
+	if s.Model != nil && s.Model.GroupName() != "" {
+		return s.Model.GroupName()
+	}
+	return "default"
+}
+
+// 种子数据的唯一键
+func (s *Seed) keys() []string {
+	if len(s.Keys) == 0 {
+		return []string{"id"}
+	}
+	return s.Keys
+}
+
+// 读取种子数据和版本号
+func (s *Seed) load() (rows g.List, version string, err error) {
+	var content []byte
+	if s.Data != nil {
+		rows = s.Data
+		if content, err = gjson.Encode(s.Data); err != nil {
+			return nil, "", err
+		}
+	} else {
+		if content, err = s.read(); err != nil {
+			return nil, "", err
+		}
+		format := s.Format
+		if format == "" {
+			format = seedFormat(path.Ext(s.Path))
+		}
+		if rows, err = parseSeed(content, format); err != nil {
+			return nil, "", gerror.Wrapf(err, "解析种子数据 %s 失败", s.Path)
+		}
+	}
+	version = s.Version
+	if version == "" {
+		version = gmd5.MustEncryptBytes(content)
+	}
+	return
+}
+
+// 读取种子数据文件
+func (s *Seed) read() ([]byte, error) {
+	if s.Path == "" {
+		return nil, gerror.Newf("种子数据 %s 未指定数据或文件路径", s.name())
+	}
+	if s.FS != nil {
+		return fs.ReadFile(s.FS, s.Path)
+	}
+	if gres.Contains(s.Path) {
+		return gres.GetContent(s.Path), nil
+	}
+	if gfile.Exists(s.Path) {
+		return gfile.GetBytes(s.Path), nil
+	}
+	return nil, gerror.Newf("未找到种子数据文件 %s", s.Path)
+}
+
+// ParseSeedFile 按扩展名解析种子数据文件,支持 json、yaml、yml、csv
+func ParseSeedFile(file string, content []byte) (rows g.List, err error) {
+	format := seedFormat(path.Ext(file))
+	if format == "" {
+		return nil, gerror.Newf("不支持的种子数据文件: %s", file)
+	}
+	return parseSeed(content, format)
+}
+
+// 解析种子数据,json、yaml 为对象数组,csv 第一行为字段名
+func parseSeed(content []byte, format string) (rows g.List, err error) {
+	switch format {
+	case "csv":
+		records, err := csv.NewReader(bytes.NewReader(content)).ReadAll()
+		if err != nil {
+			return nil, err
+		}
+		if len(records) == 0 {
+			return nil, nil
+		}
+		header := records[0]
+		for _, record := range records[1:] {
+			row := make(g.Map, len(header))
+			for i, field := range header {
+				if i < len(record) {
+					row[strings.TrimSpace(field)] = record[i]
+				}
+			}
+			rows = append(rows, row)
+		}
+		return rows, nil
+	case "json":
+		err = gjson.DecodeTo(content, &rows)
+		return rows, err
+	case "yaml":
+		err = gyaml.DecodeTo(content, &rows)
+		return rows, err
+	}
+	return nil, gerror.Newf("不支持的种子数据格式: %s", format)
+}
+
+// 创建种子数据记录表
+func initSeedTable() error {
+	seedTableOnce.Do(func() {
+		seedTableErr = CreateTable(&SeedRecord{})
+	})
+	return seedTableErr
+}
+
+// 已执行的种子数据记录 key:模块/名称
+func appliedSeeds(ctx context.Context) (records map[string]gdb.Record, err error) {
+	result, err := g.DB("default").Model(TableNameSeed).Ctx(ctx).All()
+	if err != nil {
+		return nil, err
+	}
+	records = make(map[string]gdb.Record, len(result))
+	for _, record := range result {
+		records[record["module"].String()+"/"+record["name"].String()] = record
+	}
+	return
+}
+
+// 执行种子数据,版本号未变化且 force 为 false 时跳过
+// baseline 为 true 且没有执行记录时只写入记录不写入数据,用于已通过旧方式初始化过的表
+func applySeed(ctx context.Context, s *Seed, force, baseline bool) (applied bool, err error) {
+	rows, version, err := s.load()
+	if err != nil {
+		return false, err
+	}
+	record, err := g.DB("default").Model(TableNameSeed).Ctx(ctx).Where("module", s.Module).Where("name", s.name()).One()
+	if err != nil {
+		return false, err
+	}
+	if !force && !record.IsEmpty() && record["version"].String() == version {
+		return false, nil
+	}
+	if record.IsEmpty() && baseline {
+		g.Log().Debugf(ctx, "种子数据 %s/%s 已通过旧方式初始化,记录当前版本", s.Module, s.name())
+	} else if s.insertOnly && !record.IsEmpty() {
+		g.Log().Warningf(ctx, "种子数据 %s/%s 没有唯一键,只在第一次执行时写入,本次只记录当前版本", s.Module, s.name())
+	} else if err = upsertSeedRows(ctx, s, rows, appliedSeedRows(ctx, s, record, force)); err != nil {
+		return false, gerror.Wrapf(err, "执行种子数据 %s/%s 失败", s.Module, s.name())
+	}
+
+	content, err := gjson.EncodeString(rows)
+	if err != nil {
+		return false, err
+	}
+	data := g.Map{"version": version, "rows": len(rows), "data": content}
+	if record.IsEmpty() {
+		data["id"] = NodeSnowflake.Generate().String()
+		data["module"] = s.Module
+		data["name"] = s.name()
+		_, err = g.DB("default").Model(TableNameSeed).Ctx(ctx).Data(data).Insert()
+	} else {
+		_, err = g.DB("default").Model(TableNameSeed).Ctx(ctx).Where("id", record["id"]).Data(data).Update()
+	}
+	return true, err
+}
+
+// 上次写入的数据 key:唯一键的值,force 或没有记录时返回 nil
+func appliedSeedRows(ctx context.Context, s *Seed, record gdb.Record, force bool) map[string]g.Map {
+	if force || record.IsEmpty() || record["data"].IsEmpty() {
+		return nil
+	}
+	var rows g.List
+	if err := gjson.DecodeTo(record["data"].String(), &rows); err != nil {
+		g.Log().Warningf(ctx, "种子数据 %s/%s 的执行记录无法解析,本次全部更新: %v", s.Module, s.name(), err)
+		return nil
+	}
+	applied := make(map[string]g.Map, len(rows))
+	for _, row := range rows {
+		if key, ok := seedRowKey(row, s.keys()); ok {
+			applied[key] = row
+		}
+	}
+	return applied
+}
+
+// 唯一键的值拼接为 key
+func seedRowKey(row g.Map, keys []string) (string, bool) {
+	values := make([]string, len(keys))
+	for i, key := range keys {
+		value, ok := row[key]
+		if !ok {
+			return "", false
+		}
+		values[i] = gconv.String(value)
+	}
+	return strings.Join(values, "\x00"), true
+}
+
+// 按唯一键新增或更新种子数据
+// applied 不为 nil 时,上次写入过的记录只更新与上次相比变化的字段,没有变化时不更新
+func upsertSeedRows(ctx context.Context, s *Seed, rows g.List, applied map[string]g.Map) error {
+	var (
+		table = s.table()
+		keys  = s.keys()
+	)
+	fields, err := g.DB(s.group()).TableFields(ctx, table)
+	if err != nil {
+		return err
+	}
+	_, hasId := fields["id"]
+	return g.DB(s.group()).Transaction(ctx, func(ctx context.Context, tx gdb.TX) error {
+		for i, row := range rows {
+			data := make(g.Map, len(row)+1)
+			for k, v := range row {
+				data[k] = v
+			}
+			m := tx.Model(table).Ctx(ctx)
+			// 没有唯一键时直接新增
+			if !s.insertOnly {
+				where := make(g.Map, len(keys))
+				for _, key := range keys {
+					value, ok := row[key]
+					if !ok {
+						return gerror.Newf("第 %d 条数据缺少唯一键 %s", i+1, key)
+					}
+					where[key] = value
+				}
+				// 已软删除的记录视为存在,不更新也不重新新增
+				count, err := tx.Model(table).Ctx(ctx).Unscoped().Where(where).Count()
+				if err != nil {
+					return err
+				}
+				if count > 0 {
+					delete(data, "id")
+					if key, _ := seedRowKey(row, keys); applied != nil && applied[key] != nil {
+						for k, v := range applied[key] {
+							if old, ok := data[k]; ok && gconv.String(old) == gconv.String(v) {
+								delete(data, k)
+							}
+						}
+					}
+					if len(data) == 0 {
+						continue
+					}
+					if _, err = m.Where(where).Data(data).Update(); err != nil {
+						return err
+					}
+					continue
+				}
+			}
+			if _, ok := data["id"]; !ok && hasId {
+				data["id"] = NodeSnowflake.Generate().String()
+			}
+			if _, err = m.Data(data).Insert(); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// 按名称筛选种子数据,名称可以是 <名称> 或 <模块>/<名称>
+func filterSeeds(names []string) (seeds []*Seed, err error) {
+	if len(names) == 0 {
+		return Seeds, nil
+	}
+	filter := garray.NewStrArrayFrom(names)
+	for _, s := range Seeds {
+		if filter.Contains(s.name()) || filter.Contains(s.Module+"/"+s.name()) {
+			seeds = append(seeds, s)
+		}
+	}
+	if len(seeds) == 0 {
+		return nil, gerror.Newf("未找到种子数据: %s", strings.Join(names, ","))
+	}
+	return
+}
+
+// ApplySeeds 执行版本变化的种子数据,force 为 true 时全部重新执行,names 为空时执行全部,返回执行的名称
+func ApplySeeds(ctx context.Context, force bool, names ...string) (applied []string, err error) {
+	seeds, err := filterSeeds(names)
+	if err != nil {
+		return nil, err
+	}
+	if err = initSeedTable(); err != nil {
+		return nil, err
+	}
+	if err = CreateTable(&MigrationLock{}); err != nil {
+		return nil, err
+	}
+	// 与迁移共用同一把锁
+	unlock, err := lockMigration(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	for _, s := range seeds {
+		ok, err := applySeed(ctx, s, force, false)
+		if err != nil {
+			return applied, err
+		}
+		if ok {
+			applied = append(applied, s.Module+"/"+s.name())
+		}
+	}
+	return
+}
+
+// GetSeedStatus 获取全部种子数据的执行状态
+func GetSeedStatus(ctx context.Context) (list []*SeedStatus, err error) {
+	if err = initSeedTable(); err != nil {
+		return nil, err
+	}
+	records, err := appliedSeeds(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range Seeds {
+		_, version, err := s.load()
+		if err != nil {
+			return nil, err
+		}
+		status := &SeedStatus{
+			Name:    s.name(),
+			Module:  s.Module,
+			Group:   s.group(),
+			Table:   s.table(),
+			Version: version,
+		}
+		if record, ok := records[s.Module+"/"+s.name()]; ok {
+			status.AppliedVersion = record["version"].String()
+			status.Rows = record["rows"].Int()
+			status.AppliedAt = record["updateTime"].GTime()
+		}
+		list = append(list, status)
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		if list[i].Module != list[j].Module {
+			return list[i].Module < list[j].Module
+		}
+		return list[i].Name < list[j].Name
+	})
+	return
+}
+
+// 启动时执行版本变化的种子数据
+func runSeeds() {
+	if !coreconfig.Config.Core.Seed.AutoRun || len(Seeds) == 0 {
+		return
+	}
+	applied, err := ApplySeeds(ctx, false)
+	if err != nil {
+		g.Log().Errorf(ctx, "执行种子数据失败: %v", err)
+		panic(err)
+	}
+	if len(applied) > 0 {
+		g.Log().Infof(ctx, "执行种子数据 %d 个: %s", len(applied), strings.Join(applied, ","))
+	}
+}
+
+// SeedExportOption 导出种子数据的选项
+type SeedExportOption struct {
+	Group    string   // 数据库分组,默认 default
+	Table    string   // 表名
+	Where    string   // 条件,如 status = 1
+	Order    string   // 排序,为空时有 id 列按 id 排序
+	Exclude  []string // 不导出的列
+	KeepTime bool     // 是否导出创建、更新、删除时间列,默认不导出,避免数据未变化时校验值变化
+}
+
+// ExportSeed 按条件读取表中的数据,格式与 resource/initjson/<表名>.json 一致,已软删除的数据不导出
+func ExportSeed(ctx context.Context, opt *SeedExportOption) (rows g.List, err error) {
+	group := opt.Group
+	if group == "" {
+		group = "default"
+	}
+	fields, err := g.DB(group).TableFields(ctx, opt.Table)
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) == 0 {
+		return nil, gerror.Newf("表 %s 不存在", opt.Table)
+	}
+	exclude := garray.NewStrArrayFrom(opt.Exclude)
+	if !opt.KeepTime {
+		// 分组配置的时间列和 Model 的默认时间列
+		cfg := g.DB(group).GetConfig()
+		exclude.Append(cfg.CreatedAt, cfg.UpdatedAt, cfg.DeletedAt, "createTime", "updateTime", "deletedAt", "deleted_at")
+	}
+	m := g.DB(group).Model(opt.Table).Ctx(ctx)
+	if opt.Where != "" {
+		m = m.Where(opt.Where)
+	}
+	if opt.Order != "" {
+		m = m.Order(opt.Order)
+	} else if _, ok := fields["id"]; ok {
+		m = m.OrderAsc("id")
+	}
+	result, err := m.All()
+	if err != nil {
+		return nil, err
+	}
+	rows = make(g.List, 0, len(result))
+	for _, record := range result {
+		row := record.Map()
+		for _, field := range exclude.Slice() {
+			delete(row, field)
+		}
+		rows = append(rows, row)
+	}
+	return
+}
+
+// ImportSeed 按唯一键新增或更新数据,keys 为空时使用 id,不写入执行记录,用于在环境之间同步数据
+func ImportSeed(ctx context.Context, group, table string, rows g.List, keys ...string) error {
+	return upsertSeedRows(ctx, &Seed{Group: group, Table: table, Keys: keys}, rows, nil)
+}
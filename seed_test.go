@@ -0,0 +1,242 @@
+package dzhcore_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gtime"
+	"github.com/gogf/gf/v2/util/gconv"
+	"github.com/gzdzh-cn/dzhcore"
+	"github.com/gzdzh-cn/dzhcore/dzhcoretest"
+)
+
+// 只保留本测试的种子数据,并清除执行记录
+func setupSeed(t *testing.T, seeds ...*dzhcore.Seed) *dzhcoretest.App {
+	app := dzhcoretest.Setup(t)
+	old := dzhcore.Seeds
+	dzhcore.Seeds = nil
+	t.Cleanup(func() { dzhcore.Seeds = old })
+	// 没有种子数据时只创建执行记录表
+	if _, err := dzhcore.ApplySeeds(app.Ctx, false); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.DB().Model(dzhcore.TableNameSeed).Where("module", "test").Delete(); err != nil {
+		t.Fatal(err)
+	}
+	dzhcore.AddSeed(seeds...)
+	return app
+}
+
+func applySeeds(t *testing.T, app *dzhcoretest.App, force bool, want string) {
+	t.Helper()
+	applied, err := dzhcore.ApplySeeds(app.Ctx, force)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(applied, ","); got != want {
+		t.Fatalf("执行 %q, 期望 %q", got, want)
+	}
+}
+
+// 按 code 读取 test_product 的 name、price
+func seedProducts(t *testing.T) map[string]string {
+	t.Helper()
+	result, err := g.DB().Model("test_product").Unscoped().All()
+	if err != nil {
+		t.Fatal(err)
+	}
+	products := make(map[string]string, len(result))
+	for _, record := range result {
+		products[record["code"].String()] = record["name"].String() + "/" + record["price"].String()
+	}
+	return products
+}
+
+func checkProducts(t *testing.T, want map[string]string) {
+	t.Helper()
+	got := seedProducts(t)
+	if len(got) != len(want) {
+		t.Fatalf("数据 %v, 期望 %v", got, want)
+	}
+	for code, v := range want {
+		if got[code] != v {
+			t.Errorf("%s = %s, 期望 %s", code, got[code], v)
+		}
+	}
+}
+
+// 版本变化后只写入种子数据中变化的字段,不覆盖后台修改过的数据
+func TestApplySeeds(t *testing.T) {
+	seed := &dzhcore.Seed{
+		Module: "test",
+		Table:  "test_product",
+		Keys:   []string{"code"},
+		Data: g.List{
+			{"code": "c1", "name": "a", "price": 1},
+			{"code": "c2", "name": "b", "price": 2},
+		},
+	}
+	app := setupSeed(t, seed)
+	applySeeds(t, app, false, "test/test_product")
+	checkProducts(t, map[string]string{"c1": "a/1", "c2": "b/2"})
+	// 版本未变化时跳过
+	applySeeds(t, app, false, "")
+
+	if _, err := g.DB().Model("test_product").Where("code", "c1").Data(g.Map{"name": "edited"}).Update(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.DB().Model("test_product").Where("code", "c2").Data(g.Map{"name": "edited"}).Update(); err != nil {
+		t.Fatal(err)
+	}
+	seed.Data = g.List{
+		{"code": "c1", "name": "a", "price": 1},
+		{"code": "c2", "name": "b2", "price": 2},
+		{"code": "c3", "name": "c", "price": 3},
+	}
+	applySeeds(t, app, false, "test/test_product")
+	checkProducts(t, map[string]string{"c1": "edited/1", "c2": "b2/2", "c3": "c/3"})
+
+	// force 按种子数据全部覆盖
+	applySeeds(t, app, true, "test/test_product")
+	checkProducts(t, map[string]string{"c1": "a/1", "c2": "b2/2", "c3": "c/3"})
+}
+
+// 已软删除的记录不更新也不重新新增
+func TestApplySeedsSoftDeleted(t *testing.T) {
+	seed := &dzhcore.Seed{
+		Module: "test",
+		Table:  "test_product",
+		Keys:   []string{"code"},
+		Data:   g.List{{"code": "c1", "name": "a", "price": 1}},
+	}
+	app := setupSeed(t, seed)
+	applySeeds(t, app, false, "test/test_product")
+	if _, err := g.DB().Model("test_product").Where("code", "c1").Data(g.Map{"deletedAt": gtime.Now()}).Update(); err != nil {
+		t.Fatal(err)
+	}
+	seed.Data = g.List{{"code": "c1", "name": "a2", "price": 1}}
+	applySeeds(t, app, false, "test/test_product")
+	checkProducts(t, map[string]string{"c1": "a/1"})
+}
+
+// 从文件系统读取 csv、yaml 数据,缺少唯一键时报错
+func TestApplySeedsFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"seed/test_product.csv":  {Data: []byte("id,code,name,price\n1,c1,a,1\n2,c2,b,2\n")},
+		"seed/test_contact.yaml": {Data: []byte("- id: \"1\"\n  name: n1\n  phone: \"123\"\n")},
+	}
+	app := setupSeed(t)
+	if err := dzhcore.AddSeedFS("test", fsys, "seed"); err != nil {
+		t.Fatal(err)
+	}
+	applySeeds(t, app, false, "test/test_contact,test/test_product")
+	checkProducts(t, map[string]string{"c1": "a/1", "c2": "b/2"})
+	if phone, _ := g.DB().Model("test_contact").Where("id", "1").Value("phone"); phone.String() != "123" {
+		t.Fatalf("test_contact phone %q, 期望 123", phone)
+	}
+
+	setupSeed(t, &dzhcore.Seed{
+		Module: "test",
+		Table:  "test_product",
+		Keys:   []string{"code"},
+		Data:   g.List{{"name": "x"}},
+	})
+	if _, err := dzhcore.ApplySeeds(app.Ctx, false); err == nil || !strings.Contains(err.Error(), "缺少唯一键 code") {
+		t.Fatalf("缺少唯一键时错误 %v", err)
+	}
+}
+
+// 按条件、排序导出,不导出时间列和已软删除的数据
+func TestExportSeed(t *testing.T) {
+	app := dzhcoretest.Setup(t)
+	app.Fixture(t, "test_product", g.List{
+		{"code": "c1", "name": "a", "price": 1},
+		{"code": "c2", "name": "b", "price": 2, "remark": "r"},
+		{"code": "c3", "name": "c", "price": 3},
+		{"code": "c4", "name": "d", "price": 4, "deletedAt": gtime.Now()},
+	})
+	rows, err := dzhcore.ExportSeed(app.Ctx, &dzhcore.SeedExportOption{
+		Table:   "test_product",
+		Where:   "price >= 2",
+		Order:   "code desc",
+		Exclude: []string{"remark"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var codes []string
+	for _, row := range rows {
+		codes = append(codes, gconv.String(row["code"]))
+		for _, field := range []string{"createTime", "updateTime", "deletedAt", "remark"} {
+			if _, ok := row[field]; ok {
+				t.Errorf("导出了 %s", field)
+			}
+		}
+		if row["id"] == nil {
+			t.Error("未导出 id")
+		}
+	}
+	if got := strings.Join(codes, ","); got != "c3,c2" {
+		t.Fatalf("导出 %s, 期望 c3,c2", got)
+	}
+
+	rows, err = dzhcore.ExportSeed(app.Ctx, &dzhcore.SeedExportOption{Table: "test_product", KeepTime: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 3 || rows[0]["code"] != "c1" || rows[0]["createTime"] == nil {
+		t.Fatalf("KeepTime 导出 %v", rows)
+	}
+
+	if _, err = dzhcore.ExportSeed(app.Ctx, &dzhcore.SeedExportOption{Table: "test_missing"}); err == nil {
+		t.Fatal("表不存在时没有错误")
+	}
+}
+
+// 导出的文件导入到清空的表后数据一致,按唯一键更新已有的数据
+func TestImportSeed(t *testing.T) {
+	app := dzhcoretest.Setup(t)
+	app.Fixture(t, "test_product", g.List{
+		{"code": "c1", "name": "a", "price": 1},
+		{"code": "c2", "name": "b", "price": 2},
+	})
+	rows, err := dzhcore.ExportSeed(app.Ctx, &dzhcore.SeedExportOption{Table: "test_product"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := json.Marshal(rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rows, err = dzhcore.ParseSeedFile("test_product.json", content); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = g.DB().Model("test_product").Unscoped().Where("1=1").Delete(); err != nil {
+		t.Fatal(err)
+	}
+	if err = dzhcore.ImportSeed(app.Ctx, "", "test_product", rows); err != nil {
+		t.Fatal(err)
+	}
+	checkProducts(t, map[string]string{"c1": "a/1", "c2": "b/2"})
+
+	// 按 code 更新,不写入执行记录
+	err = dzhcore.ImportSeed(app.Ctx, "", "test_product", g.List{
+		{"code": "c1", "name": "a2"},
+		{"code": "c3", "name": "c", "price": 3},
+	}, "code")
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkProducts(t, map[string]string{"c1": "a2/1", "c2": "b/2", "c3": "c/3"})
+	if count, _ := g.DB().Model(dzhcore.TableNameSeed).Where("name", "test_product").Count(); count != 0 {
+		t.Fatalf("写入了执行记录 %d 条", count)
+	}
+
+	err = dzhcore.ImportSeed(app.Ctx, "", "test_product", g.List{{"name": "x"}}, "code")
+	if err == nil || !strings.Contains(err.Error(), "缺少唯一键 code") {
+		t.Fatalf("缺少唯一键时错误 %v", err)
+	}
+}
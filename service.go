@@ -3,6 +3,8 @@ package dzhcore
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/gogf/gf/v2/container/garray"
@@ -14,15 +16,18 @@ import (
 )
 
 type IService interface {
-	ServiceAdd(ctx context.Context, req *AddReq) (data any, err error)              // 新增
-	ServiceDelete(ctx context.Context, req *DeleteReq) (data any, err error)        // 删除
-	ServiceUpdate(ctx context.Context, req *UpdateReq) (data any, err error)        // 修改
-	ServiceInfo(ctx context.Context, req *InfoReq) (data any, err error)            // 详情
-	ServiceList(ctx context.Context, req *ListReq) (data any, err error)            // 列表
-	ServicePage(ctx context.Context, req *PageReq) (data any, err error)            // 分页
-	ModifyBefore(ctx context.Context, method string, param g.MapStrAny) (err error) // 新增|删除|修改前的操作
-	ModifyAfter(ctx context.Context, method string, param g.MapStrAny) (err error)  // 新增|删除|修改后的操作
-	CacheDo(ctx context.Context, method string, param g.MapStrAny) (err error)      // 处理 db 缓存
+	ServiceAdd(ctx context.Context, req *AddReq) (data any, err error)                 // 新增
+	ServiceDelete(ctx context.Context, req *DeleteReq) (data any, err error)           // 删除
+	ServiceUpdate(ctx context.Context, req *UpdateReq) (data any, err error)           // 修改
+	ServiceUpdateBatch(ctx context.Context, req *UpdateBatchReq) (data any, err error) // 批量修改
+	ServiceUpsert(ctx context.Context, req *UpsertReq) (data any, err error)           // 新增或修改
+	ServiceInfo(ctx context.Context, req *InfoReq) (data any, err error)               // 详情
+	ServiceList(ctx context.Context, req *ListReq) (data any, err error)               // 列表
+	ServicePage(ctx context.Context, req *PageReq) (data any, err error)               // 分页
+	ServiceOptions(ctx context.Context, req *OptionsReq) (data any, err error)         // 下拉选项
+	ModifyBefore(ctx context.Context, method string, param g.MapStrAny) (err error)    // 新增|删除|修改前的操作
+	ModifyAfter(ctx context.Context, method string, param g.MapStrAny) (err error)     // 新增|删除|修改后的操作
+	CacheDo(ctx context.Context, method string, param g.MapStrAny) (err error)         // 处理 db 缓存
 	GetModel() IModel
 	GetDao() IDao
 }
@@ -37,6 +42,13 @@ type Service struct {
 	InfoIgnoreProperty string                                // Info时忽略的字段,多个字段用逗号隔开
 	UniqueKey          g.MapStrStr                           // 唯一键 key:字段名 value:错误信息
 	NotNullKey         g.MapStrStr                           // 非空键 key:字段名 value:错误信息
+	FullText           *FullTextOp                           // 全文检索配置,为空时关键字使用 LIKE 模糊查询
+	FieldRules         map[string]*FieldRule                 // 字段输出规则 key:字段名 value:隐藏、掩码、格式化、重命名规则
+	DictFields         g.MapStrStr                           // 字典字段 key:字段名 value:字典类型key,结果中追加 <字段名>Label
+	With               []string                              // 预加载的关联,对应模型 Relations() 中的key
+	WritableFields     []string                              // UpdateBatch 可修改的字段,为空时不允许批量修改
+	UpsertKey          []string                              // Upsert 的冲突键,需要有唯一索引,为空时使用 id
+	OptionsOp          *OptionsOp                            // 下拉选项配置
 }
 
 // List/Add接口条件配置
@@ -109,6 +121,9 @@ func (s *Service) ServiceAdd(ctx context.Context, req *AddReq) (data any, err er
 	if err != nil {
 		return
 	}
+	if err = s.syncRelations(ctx, rmap["id"], rmap); err != nil {
+		return
+	}
 
 	data = g.Map{"id": rmap["id"]}
 
@@ -151,6 +166,193 @@ func (s *Service) ServiceUpdate(ctx context.Context, req *UpdateReq) (data any,
 	}
 
 	_, err = m.Data(rmap).Where("id", gconv.String(rmap["id"])).FieldsEx("createTime").Update()
+	if err != nil {
+		return
+	}
+	err = s.syncRelations(ctx, rmap["id"], rmap)
+	return
+}
+
+// 批量修改,按 ids 将 data 中的字段修改为相同的值
+func (s *Service) ServiceUpdateBatch(ctx context.Context, req *UpdateBatchReq) (data any, err error) {
+	r := g.RequestFromCtx(ctx)
+	ids := r.Get("ids").Slice()
+	fields := r.Get("data").Map()
+	if len(ids) == 0 {
+		return nil, gerror.New("请选择要修改的数据")
+	}
+	if len(fields) == 0 {
+		return nil, gerror.New("请填写要修改的字段")
+	}
+	if len(s.WritableFields) == 0 {
+		return nil, gerror.New("未配置可批量修改的字段")
+	}
+	writable := garray.NewStrArrayFrom(s.WritableFields)
+	for k, v := range fields {
+		if !writable.Contains(k) {
+			return nil, gerror.Newf("字段 %s 不允许修改", k)
+		}
+		if msg, ok := s.NotNullKey[k]; ok && v == nil {
+			return nil, gerror.New(msg)
+		}
+	}
+	m := DDAO(s.Dao, ctx)
+	// 唯一键 多条数据不能修改为相同的值
+	for k, v := range s.UniqueKey {
+		if fields[k] == nil {
+			continue
+		}
+		if len(ids) > 1 {
+			return nil, gerror.New(v)
+		}
+		count, err := m.Where(k, fields[k]).WhereNotIn("id", ids).Count()
+		if err != nil {
+			return nil, err
+		}
+		if count > 0 {
+			return nil, gerror.New(v)
+		}
+	}
+
+	result, err := m.Data(fields).WhereIn("id", ids).FieldsEx("createTime").Update()
+	if err != nil {
+		return
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return
+	}
+	data = g.Map{"affected": affected}
+	return
+}
+
+// 新增或修改,按 UpsertKey 冲突时修改,否则新增
+// 请求可以是单条数据,也可以是 list 数组批量提交
+func (s *Service) ServiceUpsert(ctx context.Context, req *UpsertReq) (data any, err error) {
+	var (
+		r    = g.RequestFromCtx(ctx)
+		list = r.Get("list").Maps()
+		keys = s.UpsertKey
+		m    = DDAO(s.Dao, ctx)
+	)
+	if r.Get("list").IsNil() {
+		list = []map[string]interface{}{r.GetMap()}
+	}
+	if len(list) == 0 {
+		return nil, gerror.New("请填写要保存的数据")
+	}
+	if len(keys) == 0 {
+		keys = []string{"id"}
+	}
+	var insertParams g.MapStrAny
+	if s.InsertParam != nil {
+		insertParams = s.InsertParam(ctx)
+	}
+
+	var (
+		hooks   = s.modifyHooks(ctx)
+		ids     = make([]string, 0, len(list))
+		methods = make([]string, 0, len(list))
+	)
+	for _, item := range list {
+		for k, v := range s.NotNullKey {
+			if item[k] == nil {
+				return nil, gerror.New(v)
+			}
+		}
+		// 查询冲突键对应的已有数据
+		var existId *g.Var
+		where := g.Map{}
+		for _, key := range keys {
+			if item[key] != nil {
+				where[key] = item[key]
+			}
+		}
+		if len(where) == len(keys) {
+			if existId, err = m.Where(where).Value("id"); err != nil {
+				return nil, err
+			}
+		}
+		// 唯一键
+		for k, v := range s.UniqueKey {
+			if item[k] == nil || garray.NewStrArrayFrom(keys).Contains(k) {
+				continue
+			}
+			um := m.Where(k, item[k])
+			if existId != nil && !existId.IsEmpty() {
+				um = um.WhereNot("id", existId.String())
+			}
+			count, err := um.Count()
+			if err != nil {
+				return nil, err
+			}
+			if count > 0 {
+				return nil, gerror.New(v)
+			}
+		}
+		// 已有数据按 Update,否则按 Add 调用 ModifyBefore
+		method, param := "Add", g.MapStrAny{}
+		for k, v := range item {
+			param[k] = v
+		}
+		if existId != nil && !existId.IsEmpty() {
+			method, param["id"] = "Update", existId.String()
+		}
+		if err = hooks.ModifyBefore(ctx, method, param); err != nil {
+			return nil, err
+		}
+		methods = append(methods, method)
+		for k, v := range insertParams {
+			item[k] = v
+		}
+		if existId != nil && !existId.IsEmpty() {
+			item["id"] = existId.String()
+		} else if item["id"] == nil {
+			item["id"] = NodeSnowflake.Generate().String()
+		}
+		ids = append(ids, gconv.String(item["id"]))
+	}
+
+	// 冲突时不修改 id、createTime 和 InsertParam 中的字段
+	exclude := []string{"id", "createTime"}
+	for k := range insertParams {
+		exclude = append(exclude, k)
+	}
+	// 批量保存时字段为所有数据字段的并集,缺少的字段会写为 NULL,按字段组合分组保存,只修改每条数据提交的字段
+	var (
+		groups  = make(map[string][]map[string]interface{})
+		columns []string
+	)
+	for _, item := range list {
+		fields := make([]string, 0, len(item))
+		for k := range item {
+			fields = append(fields, k)
+		}
+		sort.Strings(fields)
+		column := strings.Join(fields, ",")
+		if _, ok := groups[column]; !ok {
+			columns = append(columns, column)
+		}
+		groups[column] = append(groups[column], item)
+	}
+	var affected int64
+	for _, column := range columns {
+		result, err := m.Data(groups[column]).OnConflict(keys).OnDuplicateEx(exclude).Save()
+		if err != nil {
+			return nil, err
+		}
+		n, err := result.RowsAffected()
+		if err != nil {
+			return nil, err
+		}
+		affected += n
+	}
+	for i, item := range list {
+		if err = hooks.ModifyAfter(ctx, methods[i], item); err != nil {
+			return nil, err
+		}
+	}
+	data = g.Map{"affected": affected, "ids": ids}
 	return
 }
 
@@ -168,7 +370,11 @@ func (s *Service) ServiceInfo(ctx context.Context, req *InfoReq) (data any, err
 	if len(s.InfoIgnoreProperty) > 0 {
 		m.FieldsEx(s.InfoIgnoreProperty)
 	}
-	data, err = m.Clone().Where("id", gconv.String(req.Id)).One()
+	record, err := m.Clone().Where("id", gconv.String(req.Id)).One()
+	if err != nil {
+		return
+	}
+	data = s.processRecord(ctx, record)
 	return
 }
 
@@ -185,7 +391,7 @@ func (s *Service) ServiceList(ctx context.Context, req *ListReq) (data any, err
 	m := DDAO(s.Dao, ctx)
 
 	// 如果 req.Order 和 req.Sort 均不为空 则添加排序
-	if !r.Get("order").IsEmpty() && !r.Get("sort").IsEmpty() {
+	if !r.Get("order").IsEmpty() && !r.Get("sort").IsEmpty() && r.Get("order").String() != FullTextOrderRelevance {
 		m = m.Order(r.Get("order").String() + " " + r.Get("sort").String())
 	}
 	// 如果 ListQueryOp 不为空 则使用 ListQueryOp 进行查询
@@ -202,11 +408,11 @@ func (s *Service) ServiceList(ctx context.Context, req *ListReq) (data any, err
 			for _, join := range s.ListQueryOp.Join {
 				switch join.Type {
 				case LeftJoin:
-					m = m.LeftJoin(join.Model.TableName(), join.Condition).As(join.Alias)
+					m = m.LeftJoin(joinTable(join), join.Condition).As(join.Alias)
 				case RightJoin:
-					m = m.RightJoin(join.Model.TableName(), join.Condition).As(join.Alias)
+					m = m.RightJoin(joinTable(join), join.Condition).As(join.Alias)
 				case InnerJoin:
-					m = m.InnerJoin(join.Model.TableName(), join.Condition).As(join.Alias)
+					m = m.InnerJoin(joinTable(join), join.Condition).As(join.Alias)
 				}
 			}
 		}
@@ -221,7 +427,13 @@ func (s *Service) ServiceList(ctx context.Context, req *ListReq) (data any, err
 		}
 		// 如果KeyWordField不为空 则添加查询条件
 		if !r.Get("keyWord").IsEmpty() {
-			if len(s.ListQueryOp.KeyWordField) > 0 {
+			if s.fullTextEnabled() {
+				where, args := s.fullTextWhere(s.ListQueryOp, r.Get("keyWord").String())
+				m = m.Where(where, args...)
+				if r.Get("order").String() == FullTextOrderRelevance {
+					m = m.Order(s.fullTextOrder(s.ListQueryOp, r.Get("keyWord").String()))
+				}
+			} else if len(s.ListQueryOp.KeyWordField) > 0 {
 				builder := m.Builder()
 				for _, field := range s.ListQueryOp.KeyWordField {
 					builder = builder.WhereOrLike(field, "%"+r.Get("keyWord").String()+"%")
@@ -269,7 +481,7 @@ func (s *Service) ServiceList(ctx context.Context, req *ListReq) (data any, err
 	if result == nil {
 		data = garray.New()
 	} else {
-		data = result
+		data = s.processResult(ctx, result)
 	}
 	if s.ListQueryOp != nil {
 		if s.ListQueryOp.ModifyResult != nil {
@@ -319,11 +531,11 @@ func (s *Service) ServicePage(ctx context.Context, req *PageReq) (data any, err
 			for _, join := range s.PageQueryOp.Join {
 				switch join.Type {
 				case LeftJoin:
-					m = m.LeftJoin(join.Model.TableName(), join.Condition).As(join.Alias)
+					m = m.LeftJoin(joinTable(join), join.Condition).As(join.Alias)
 				case RightJoin:
-					m = m.RightJoin(join.Model.TableName(), join.Condition).As(join.Alias)
+					m = m.RightJoin(joinTable(join), join.Condition).As(join.Alias)
 				case InnerJoin:
-					m = m.InnerJoin(join.Model.TableName(), join.Condition).As(join.Alias)
+					m = m.InnerJoin(joinTable(join), join.Condition).As(join.Alias)
 				}
 			}
 		}
@@ -394,7 +606,14 @@ func (s *Service) ServicePage(ctx context.Context, req *PageReq) (data any, err
 
 		// 如果KeyWordField不为空 则添加查询条件
 		if !r.Get("keyWord").IsEmpty() {
-			if len(s.PageQueryOp.KeyWordField) > 0 {
+			if s.fullTextEnabled() {
+				where, args := s.fullTextWhere(s.PageQueryOp, r.Get("keyWord").String())
+				orBuilder = orBuilder.WhereOr(where, args...)
+				if r.Get("order").String() == FullTextOrderRelevance {
+					m = m.Order(s.fullTextOrder(s.PageQueryOp, r.Get("keyWord").String()))
+					dbRedisSlice = append(dbRedisSlice, FullTextOrderRelevance)
+				}
+			} else if len(s.PageQueryOp.KeyWordField) > 0 {
 				for _, field := range s.PageQueryOp.KeyWordField {
 					orBuilder = orBuilder.WhereOrLike(field, "%"+r.Get("keyWord").String()+"%")
 				}
@@ -428,7 +647,7 @@ func (s *Service) ServicePage(ctx context.Context, req *PageReq) (data any, err
 	}
 
 	// 如果 req.Order 和 req.Sort 均不为空 则添加排序
-	if !r.Get("order").IsEmpty() && !r.Get("sort").IsEmpty() {
+	if !r.Get("order").IsEmpty() && !r.Get("sort").IsEmpty() && r.Get("order").String() != FullTextOrderRelevance {
 		order := r.Get("order").String() + " " + r.Get("sort").String()
 		m = m.Order(order)
 		dbRedisSlice = append(dbRedisSlice, gstr.Replace(order, " ", "-"))
@@ -447,6 +666,7 @@ func (s *Service) ServicePage(ctx context.Context, req *PageReq) (data any, err
 	if err != nil {
 		return nil, err
 	}
+	result = s.processResult(ctx, result)
 
 	// 如果req.IsExport为true 则导出数据
 	if req.IsExport {
@@ -479,12 +699,35 @@ func (s *Service) ServicePage(ctx context.Context, req *PageReq) (data any, err
 	return data, nil
 }
 
+// 新增、修改前后的操作
+type modifyHooks interface {
+	ModifyBefore(ctx context.Context, method string, param g.MapStrAny) (err error)
+	ModifyAfter(ctx context.Context, method string, param g.MapStrAny) (err error)
+}
+
+type modifyHooksCtxKey struct{}
+
+// 保存控制器使用的 Service,ServiceUpsert 按每条数据调用其 ModifyBefore、ModifyAfter
+func withModifyHooks(ctx context.Context, hooks modifyHooks) context.Context {
+	return context.WithValue(ctx, modifyHooksCtxKey{}, hooks)
+}
+
+// ctx 中保存的 ModifyBefore、ModifyAfter,没有时使用 s
+func (s *Service) modifyHooks(ctx context.Context) modifyHooks {
+	if hooks, ok := ctx.Value(modifyHooksCtxKey{}).(modifyHooks); ok {
+		return hooks
+	}
+	return s
+}
+
 // 新增|删除|修改前的操作
+// method 为 Add、Delete、Update,UpdateBatch 按每个 id 以 Update 调用,param 为 data 和 id
+// Upsert 按每条数据以 Add 或 Update 调用,修改时 param 带已有数据的 id
 func (s *Service) ModifyBefore(ctx context.Context, method string, param g.MapStrAny) (err error) {
 	return
 }
 
-// 新增|删除|修改后的操作
+// 新增|删除|修改后的操作,method 与 ModifyBefore 相同,Add 的 param 带新增数据的 id
 func (s *Service) ModifyAfter(ctx context.Context, method string, param g.MapStrAny) (err error) {
 	return
 }
@@ -524,6 +767,17 @@ func (s *Service) GetDao() IDao {
 	return s.Dao
 }
 
+// 获取分组和表名,Dao 不为空时优先使用 Dao
+func (s *Service) groupTable() (group, table string) {
+	if s.Dao != nil {
+		return s.Dao.Group(), s.Dao.Table()
+	}
+	if s.Model != nil {
+		return s.Model.GroupName(), s.Model.TableName()
+	}
+	return
+}
+
 func NewModelService(model IModel) *Service {
 	return &Service{
 		Model: model,
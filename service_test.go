@@ -0,0 +1,191 @@
+package dzhcore_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gzdzh-cn/dzhcore"
+	"github.com/gzdzh-cn/dzhcore/dzhcoretest"
+)
+
+type testProduct struct {
+	*dzhcore.Model
+	Code   string  `gorm:"column:code;type:varchar(50);uniqueIndex" json:"code"`
+	Name   string  `gorm:"column:name;type:varchar(255)" json:"name"`
+	Price  *int    `gorm:"column:price" json:"price"`
+	Remark *string `gorm:"column:remark;type:varchar(255)" json:"remark"`
+}
+
+func (*testProduct) TableName() string {
+	return "test_product"
+}
+
+func (*testProduct) GroupName() string {
+	return "default"
+}
+
+// 记录 ModifyBefore、ModifyAfter 的调用,name 为 reject 时拒绝修改
+type testProductService struct {
+	*dzhcore.Service
+	calls []string
+}
+
+var productService = &testProductService{Service: dzhcore.NewModelService(&testProduct{})}
+
+func (s *testProductService) ModifyBefore(ctx context.Context, method string, param g.MapStrAny) error {
+	if param["name"] == "reject" {
+		return gerror.New("rejected")
+	}
+	s.calls = append(s.calls, fmt.Sprintf("before %s %v", method, param["id"]))
+	return nil
+}
+
+func (s *testProductService) ModifyAfter(ctx context.Context, method string, param g.MapStrAny) error {
+	s.calls = append(s.calls, fmt.Sprintf("after %s %v", method, param["id"]))
+	return nil
+}
+
+func init() {
+	productService.Dao = dzhcore.NewModelDao(&testProduct{})
+	productService.UpsertKey = []string{"code"}
+	productService.WritableFields = []string{"name", "price"}
+	// 未配置 WritableFields 的批量修改
+	s := dzhcore.NewModelService(&testProduct{})
+	s.Dao = dzhcore.NewModelDao(&testProduct{})
+	testOptions.Models = append(testOptions.Models, &testProduct{})
+	testOptions.Controllers = append(testOptions.Controllers,
+		&dzhcore.Controller{
+			Prefix:  "/admin/test/product",
+			Api:     []string{"Upsert", "UpdateBatch"},
+			Service: productService,
+		},
+		&dzhcore.Controller{
+			Prefix:  "/admin/test/productAll",
+			Api:     []string{"UpdateBatch"},
+			Service: s,
+		},
+	)
+}
+
+func setupProduct(t *testing.T) *dzhcoretest.App {
+	app := dzhcoretest.Setup(t)
+	productService.calls = nil
+	return app
+}
+
+// 每条数据提交的字段不同时,不能把其他数据的字段写为 NULL
+func TestServiceUpsertHeterogeneousList(t *testing.T) {
+	app := setupProduct(t)
+	app.Fixture(t, "test_product", g.List{
+		{"id": "3", "code": "c3", "name": "c", "price": 7, "remark": "r3"},
+	})
+
+	res := app.Client.Post(t, "/admin/test/product/upsert", g.Map{"list": g.List{
+		{"code": "c1", "name": "a", "price": 1},
+		{"code": "c3", "name": "b"},
+		{"code": "c4", "remark": "r4"},
+	}})
+	if !res.Ok() {
+		t.Fatalf("upsert 失败: %s", res.Body)
+	}
+	if ids := res.Json().Get("ids").Strings(); len(ids) != 3 {
+		t.Fatalf("ids 数量 %d, 期望 3", len(ids))
+	}
+
+	result, err := g.DB().Model("test_product").Order("code").All()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []g.Map{
+		{"code": "c1", "name": "a", "price": 1, "remark": nil},
+		{"code": "c3", "name": "b", "price": 7, "remark": "r3"},
+		{"code": "c4", "name": nil, "price": nil, "remark": "r4"},
+	}
+	if len(result) != len(want) {
+		t.Fatalf("数据条数 %d, 期望 %d", len(result), len(want))
+	}
+	for i, record := range result {
+		for k, v := range want[i] {
+			got := record[k]
+			if v == nil {
+				if !got.IsNil() {
+					t.Errorf("%s.%s = %v, 期望 NULL", want[i]["code"], k, got)
+				}
+				continue
+			}
+			if got.String() != g.NewVar(v).String() {
+				t.Errorf("%s.%s = %v, 期望 %v", want[i]["code"], k, got, v)
+			}
+		}
+	}
+
+	// 已有数据按 Update、新数据按 Add 调用 ModifyBefore、ModifyAfter
+	ids := res.Json().Get("ids").Strings()
+	calls := []string{
+		"before Add <nil>", "before Update 3", "before Add <nil>",
+		"after Add " + ids[0], "after Update 3", "after Add " + ids[2],
+	}
+	if got := strings.Join(productService.calls, ","); got != strings.Join(calls, ",") {
+		t.Fatalf("调用 %s, 期望 %s", got, strings.Join(calls, ","))
+	}
+}
+
+// 批量修改按每个 id 以 Update 调用 ModifyBefore、ModifyAfter
+func TestUpdateBatch(t *testing.T) {
+	app := setupProduct(t)
+	app.Fixture(t, "test_product", g.List{
+		{"id": "1", "code": "c1", "name": "a"},
+		{"id": "2", "code": "c2", "name": "b"},
+		{"id": "3", "code": "c3", "name": "c"},
+	})
+	res := app.Client.Post(t, "/admin/test/product/updateBatch", g.Map{"ids": g.Slice{"1", "2"}, "data": g.Map{"name": "x", "price": 5}})
+	if !res.Ok() {
+		t.Fatalf("批量修改失败: %s", res.Body)
+	}
+	if affected := res.Json().Get("affected").Int(); affected != 2 {
+		t.Fatalf("修改 %d 条, 期望 2", affected)
+	}
+	want := "before Update 1,before Update 2,after Update 1,after Update 2"
+	if got := strings.Join(productService.calls, ","); got != want {
+		t.Fatalf("调用 %s, 期望 %s", got, want)
+	}
+	names, err := g.DB().Model("test_product").Order("code").Array("name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := g.NewVar(names).Strings(); strings.Join(got, ",") != "x,x,c" {
+		t.Fatalf("修改后 %v, 期望 [x x c]", got)
+	}
+}
+
+func TestUpdateBatchRejected(t *testing.T) {
+	app := setupProduct(t)
+	app.Fixture(t, "test_product", g.List{
+		{"id": "1", "code": "c1", "name": "a"},
+		{"id": "2", "code": "c2", "name": "b"},
+	})
+	for _, tc := range []struct {
+		prefix string
+		data   g.Map
+	}{
+		{"/admin/test/product", g.Map{"name": "reject"}}, // ModifyBefore 拒绝
+		{"/admin/test/product", g.Map{"code": "c9"}},     // 不在 WritableFields 中
+		{"/admin/test/productAll", g.Map{"name": "y"}},   // 未配置 WritableFields
+	} {
+		res := app.Client.Post(t, tc.prefix+"/updateBatch", g.Map{"ids": g.Slice{"1", "2"}, "data": tc.data})
+		if res.Ok() {
+			t.Fatalf("%s 修改 %v 应失败: %s", tc.prefix, tc.data, res.Body)
+		}
+	}
+	names, err := g.DB().Model("test_product").Order("code").Array("name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := g.NewVar(names).Strings(); strings.Join(got, ",") != "a,b" {
+		t.Fatalf("失败后数据 %v, 期望不变", got)
+	}
+}
@@ -0,0 +1,381 @@
+package dzhcore
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gogf/gf/v2/database/gdb"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/net/ghttp"
+	"github.com/gogf/gf/v2/os/gctx"
+	"github.com/gzdzh-cn/dzhcore/coreconfig"
+	"gorm.io/gorm"
+)
+
+// SQL观测
+// 开启 core.sqlObserver.enable 后,g.DB() 通过包装 gdb 驱动的 DoCommit、gorm 通过回调统计每条sql的耗时,
+// 不依赖 sql 日志,也不需要开启 debug;语句统计和接口统计最多保留 core.sqlObserver.maxStats 条,超出时淘汰执行次数最少的
+
+// sqlStatsCtxKey 请求上下文中SQL统计的key
+type sqlStatsCtxKey struct{}
+
+// gorm 回调中记录开始时间的key
+const sqlObserverStartKey = "dzhcore:sql_observer_start"
+
+var (
+	// 归一化sql,去掉参数值
+	sqlStringPattern = regexp.MustCompile(`'(?:[^']|'')*'`)
+	sqlNumberPattern = regexp.MustCompile(`\b\d+(?:\.\d+)?\b`)
+	sqlInPattern     = regexp.MustCompile(`\(\?(?:\s*,\s*\?)*\)`)
+
+	sqlStatMu   sync.Mutex
+	sqlStatMap  = make(map[string]*SqlStat)   // 语句统计 key:归一化sql
+	sqlRouteMap = make(map[string]*RouteStat) // 接口统计 key:路由
+)
+
+// SqlStat 语句统计
+type SqlStat struct {
+	Sql       string `json:"sql"`       // 归一化后的sql
+	Example   string `json:"example"`   // 最近一次执行的完整sql
+	Count     int64  `json:"count"`     // 执行次数
+	SlowCount int64  `json:"slowCount"` // 慢查询次数
+	TotalCost int64  `json:"totalCost"` // 总耗时（毫秒）
+	MaxCost   int64  `json:"maxCost"`   // 最大耗时（毫秒）
+	Route     string `json:"route"`     // 最近一次执行的路由
+}
+
+// RouteStat 接口SQL统计
+type RouteStat struct {
+	Route        string `json:"route"`        // 路由
+	Requests     int64  `json:"requests"`     // 请求次数
+	Queries      int64  `json:"queries"`      // 查询总数
+	TotalCost    int64  `json:"totalCost"`    // 数据库总耗时（毫秒）
+	MaxQueries   int64  `json:"maxQueries"`   // 单次请求最多查询数
+	NPlusOneHits int64  `json:"nPlusOneHits"` // N+1 告警次数
+}
+
+// RequestSqlStats 单次请求的SQL统计
+type RequestSqlStats struct {
+	mu         sync.Mutex
+	Count      int64            // 查询次数
+	Cost       int64            // 数据库耗时（毫秒）
+	Statements map[string]int64 // 归一化sql执行次数
+}
+
+// 包装配置中用到的 gdb 驱动,需在创建 g.DB() 之前调用
+func observeDrivers(config gdb.Config) {
+	wrapped := make(map[string]bool)
+	for _, nodes := range config {
+		for _, node := range nodes {
+			if wrapped[node.Type] {
+				continue
+			}
+			wrapped[node.Type] = true
+			db, err := gdb.New(node)
+			if err != nil {
+				g.Log().Errorf(ctx, "SQL观测包装数据库驱动 %s 失败: %v", node.Type, err)
+				continue
+			}
+			if wrapper, ok := db.(*gdb.DriverWrapperDB); ok {
+				db = wrapper.DB
+			}
+			// 已经包装过
+			if _, ok := db.(*sqlObserverDB); ok {
+				continue
+			}
+			driver, ok := db.(gdb.Driver)
+			if !ok {
+				g.Log().Errorf(ctx, "SQL观测不支持数据库驱动 %s", node.Type)
+				continue
+			}
+			if err = gdb.Register(node.Type, &sqlObserverDriver{driver}); err != nil {
+				g.Log().Errorf(ctx, "SQL观测包装数据库驱动 %s 失败: %v", node.Type, err)
+			}
+		}
+	}
+}
+
+// sqlObserverDriver 创建 sqlObserverDB 的 gdb 驱动
+type sqlObserverDriver struct {
+	gdb.Driver
+}
+
+// New 创建数据库对象
+func (d *sqlObserverDriver) New(core *gdb.Core, node *gdb.ConfigNode) (gdb.DB, error) {
+	db, err := d.Driver.New(core, node)
+	if err != nil {
+		return nil, err
+	}
+	return &sqlObserverDB{DB: db}, nil
+}
+
+// sqlObserverDB 统计 g.DB() 执行的sql,其他方法与原驱动一致
+type sqlObserverDB struct {
+	gdb.DB
+}
+
+// DoCommit 执行sql并统计,事务的开始、提交、回滚和预编译不统计
+func (d *sqlObserverDB) DoCommit(ctx context.Context, in gdb.DoCommitInput) (out gdb.DoCommitOutput, err error) {
+	start := time.Now()
+	out, err = d.DB.DoCommit(ctx, in)
+	switch in.Type {
+	case gdb.SqlTypeBegin, gdb.SqlTypeTXCommit, gdb.SqlTypeTXRollback, gdb.SqlTypePrepareContext:
+		return
+	}
+	observeSql(ctx, d.GetGroup(), in.Sql, gdb.FormatSqlWithArgs(in.Sql, in.Args), time.Since(start))
+	return
+}
+
+// sqlObserverPlugin 通过 gorm 回调统计sql
+type sqlObserverPlugin struct {
+	group string
+}
+
+// Name 插件名称
+func (p *sqlObserverPlugin) Name() string {
+	return "dzhcore:sql_observer"
+}
+
+// Initialize 在 gorm 的各类操作前后注册回调
+func (p *sqlObserverPlugin) Initialize(db *gorm.DB) error {
+	before := func(db *gorm.DB) {
+		db.InstanceSet(sqlObserverStartKey, time.Now())
+	}
+	after := func(db *gorm.DB) {
+		value, ok := db.InstanceGet(sqlObserverStartKey)
+		if !ok || db.Statement.SQL.Len() == 0 {
+			return
+		}
+		sql := db.Statement.SQL.String()
+		observeSql(db.Statement.Context, p.group, sql, db.Dialector.Explain(sql, db.Statement.Vars...), time.Since(value.(time.Time)))
+	}
+	var (
+		callback = db.Callback()
+		name     = p.Name()
+	)
+	return errors.Join(
+		callback.Create().Before("gorm:create").Register(name+"_before_create", before),
+		callback.Create().After("gorm:create").Register(name+"_after_create", after),
+		callback.Query().Before("gorm:query").Register(name+"_before_query", before),
+		callback.Query().After("gorm:query").Register(name+"_after_query", after),
+		callback.Update().Before("gorm:update").Register(name+"_before_update", before),
+		callback.Update().After("gorm:update").Register(name+"_after_update", after),
+		callback.Delete().Before("gorm:delete").Register(name+"_before_delete", before),
+		callback.Delete().After("gorm:delete").Register(name+"_after_delete", after),
+		callback.Row().Before("gorm:row").Register(name+"_before_row", before),
+		callback.Row().After("gorm:row").Register(name+"_after_row", after),
+		callback.Raw().Before("gorm:raw").Register(name+"_before_raw", before),
+		callback.Raw().After("gorm:raw").Register(name+"_after_raw", after),
+	)
+}
+
+// 统计一条sql,sql 为带占位符的语句,example 为填入参数后的完整sql
+func observeSql(ctx context.Context, group string, sql string, example string, elapsed time.Duration) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	var (
+		cost       = elapsed.Milliseconds()
+		route      = sqlRoute(ctx)
+		normalized = normalizeSql(sql)
+		isSlow     = cost >= int64(coreconfig.Config.Core.SQLObserver.SlowThreshold)
+	)
+	if isSlow {
+		g.Log().Warningf(ctx, "[慢查询] [%d ms] [分组：%s] [路由：%s] [TraceId：%s] %s", cost, group, route, gctx.CtxId(ctx), example)
+	}
+
+	if stats, ok := ctx.Value(sqlStatsCtxKey{}).(*RequestSqlStats); ok {
+		stats.mu.Lock()
+		stats.Count++
+		stats.Cost += cost
+		stats.Statements[normalized]++
+		stats.mu.Unlock()
+	}
+
+	sqlStatMu.Lock()
+	defer sqlStatMu.Unlock()
+	stat, ok := sqlStatMap[normalized]
+	if !ok {
+		evictStat(sqlStatMap, func(stat *SqlStat) int64 { return stat.Count })
+		stat = &SqlStat{Sql: normalized}
+		sqlStatMap[normalized] = stat
+	}
+	stat.Example = example
+	stat.Route = route
+	stat.Count++
+	stat.TotalCost += cost
+	if cost > stat.MaxCost {
+		stat.MaxCost = cost
+	}
+	if isSlow {
+		stat.SlowCount++
+	}
+}
+
+// 统计达到 core.sqlObserver.maxStats 条时淘汰执行次数最少的一条,需持有 sqlStatMu
+func evictStat[T any](stats map[string]T, count func(T) int64) {
+	maxStats := coreconfig.Config.Core.SQLObserver.MaxStats
+	if maxStats <= 0 || len(stats) < maxStats {
+		return
+	}
+	var (
+		evictKey string
+		evictMin int64 = -1
+	)
+	for key, stat := range stats {
+		if c := count(stat); evictMin < 0 || c < evictMin {
+			evictKey, evictMin = key, c
+		}
+	}
+	delete(stats, evictKey)
+}
+
+// 归一化sql,相同结构的语句归为一类
+func normalizeSql(sql string) string {
+	sql = sqlStringPattern.ReplaceAllString(sql, "?")
+	sql = sqlNumberPattern.ReplaceAllString(sql, "?")
+	sql = sqlInPattern.ReplaceAllString(sql, "(?)")
+	return strings.Join(strings.Fields(sql), " ")
+}
+
+// 当前请求的路由
+func sqlRoute(ctx context.Context) string {
+	r := g.RequestFromCtx(ctx)
+	if r == nil {
+		return ""
+	}
+	if r.Router != nil && r.Router.Uri != "" {
+		return r.Router.Uri
+	}
+	return r.URL.Path
+}
+
+// SqlObserve 请求SQL统计中间件,统计单次请求的查询次数和耗时,检测 N+1 查询
+func SqlObserve(r *ghttp.Request) {
+	stats := &RequestSqlStats{
+		Statements: make(map[string]int64),
+	}
+	r.SetCtx(context.WithValue(r.Context(), sqlStatsCtxKey{}, stats))
+
+	r.Middleware.Next()
+
+	var (
+		ctx      = r.Context()
+		route    = sqlRoute(ctx)
+		nPlusOne = int64(coreconfig.Config.Core.SQLObserver.NPlusOne)
+		hits     int64
+	)
+	stats.mu.Lock()
+	defer stats.mu.Unlock()
+	if nPlusOne > 0 {
+		for sql, count := range stats.Statements {
+			if count >= nPlusOne {
+				hits++
+				g.Log().Warningf(ctx, "[N+1查询] [路由：%s] [TraceId：%s] 相同语句执行 %d 次: %s", route, gctx.CtxId(ctx), count, sql)
+			}
+		}
+	}
+
+	sqlStatMu.Lock()
+	defer sqlStatMu.Unlock()
+	stat, ok := sqlRouteMap[route]
+	if !ok {
+		evictStat(sqlRouteMap, func(stat *RouteStat) int64 { return stat.Requests })
+		stat = &RouteStat{Route: route}
+		sqlRouteMap[route] = stat
+	}
+	stat.Requests++
+	stat.Queries += stats.Count
+	stat.TotalCost += stats.Cost
+	stat.NPlusOneHits += hits
+	if stats.Count > stat.MaxQueries {
+		stat.MaxQueries = stats.Count
+	}
+}
+
+// GetRequestSqlStats 获取当前请求的SQL统计,未开启SQL观测时返回nil
+func GetRequestSqlStats(ctx context.Context) *RequestSqlStats {
+	stats, _ := ctx.Value(sqlStatsCtxKey{}).(*RequestSqlStats)
+	return stats
+}
+
+// GetSqlTop 获取SQL统计排行
+func GetSqlTop(size int) g.Map {
+	if size <= 0 {
+		size = coreconfig.Config.Core.SQLObserver.TopSize
+	}
+	sqlStatMu.Lock()
+	defer sqlStatMu.Unlock()
+
+	var (
+		statements []*SqlStat
+		slow       []*SqlStat
+		routes     []*RouteStat
+	)
+	for _, stat := range sqlStatMap {
+		item := *stat
+		statements = append(statements, &item)
+		if item.SlowCount > 0 {
+			slow = append(slow, &item)
+		}
+	}
+	for _, stat := range sqlRouteMap {
+		item := *stat
+		routes = append(routes, &item)
+	}
+	sort.Slice(statements, func(i, j int) bool { return statements[i].TotalCost > statements[j].TotalCost })
+	sort.Slice(slow, func(i, j int) bool { return slow[i].MaxCost > slow[j].MaxCost })
+	sort.Slice(routes, func(i, j int) bool { return routes[i].Queries > routes[j].Queries })
+
+	return g.Map{
+		"statements": statements[:min(size, len(statements))],
+		"slow":       slow[:min(size, len(slow))],
+		"routes":     routes[:min(size, len(routes))],
+	}
+}
+
+// ResetSqlStats 清空SQL统计
+func ResetSqlStats() {
+	sqlStatMu.Lock()
+	defer sqlStatMu.Unlock()
+	sqlStatMap = make(map[string]*SqlStat)
+	sqlRouteMap = make(map[string]*RouteStat)
+}
+
+// SqlObserverController SQL统计接口
+type SqlObserverController struct {
+	*ControllerSimple
+}
+
+type SqlTopReq struct {
+	g.Meta `path:"/top" method:"GET"`
+	Size   int `json:"size"` // 返回条数,默认 core.sqlObserver.topSize
+}
+
+type SqlResetReq struct {
+	g.Meta `path:"/reset" method:"POST"`
+}
+
+// Top SQL统计排行
+func (c *SqlObserverController) Top(ctx context.Context, req *SqlTopReq) (res *BaseRes, err error) {
+	return Ok(GetSqlTop(req.Size)), nil
+}
+
+// Reset 清空SQL统计
+func (c *SqlObserverController) Reset(ctx context.Context, req *SqlResetReq) (res *BaseRes, err error) {
+	ResetSqlStats()
+	return Ok(nil), nil
+}
+
+func init() {
+	if coreconfig.Config.Core.SQLObserver.Enable {
+		AddControllerSimple(&SqlObserverController{
+			&ControllerSimple{Prefix: "/admin/core/sql"},
+		})
+	}
+}
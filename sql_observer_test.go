@@ -0,0 +1,146 @@
+package dzhcore_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gzdzh-cn/dzhcore"
+	"github.com/gzdzh-cn/dzhcore/coreconfig"
+	"github.com/gzdzh-cn/dzhcore/dzhcoretest"
+)
+
+// 同一请求中按 id 逐条查询
+type testSqlController struct {
+	*dzhcore.ControllerSimple
+}
+
+type testSqlLoopReq struct {
+	g.Meta `path:"/loop" method:"GET"`
+	Times  int `json:"times"`
+}
+
+func (c *testSqlController) Loop(ctx context.Context, req *testSqlLoopReq) (res *dzhcore.BaseRes, err error) {
+	for i := 0; i < req.Times; i++ {
+		if _, err = g.DB().Model("test_product").Ctx(ctx).Where("id", i).One(); err != nil {
+			return dzhcore.Fail(err.Error()), err
+		}
+	}
+	stats := dzhcore.GetRequestSqlStats(ctx)
+	return dzhcore.Ok(g.Map{"count": stats.Count, "statements": stats.Statements}), nil
+}
+
+func init() {
+	g.Server().BindMiddleware("/admin/test/sql/*", dzhcore.SqlObserve)
+	testOptions.ControllerSimples = append(testOptions.ControllerSimples,
+		&testSqlController{&dzhcore.ControllerSimple{Prefix: "/admin/test/sql"}},
+	)
+}
+
+func setupSqlObserver(t *testing.T) *dzhcoretest.App {
+	app := dzhcoretest.Setup(t)
+	dzhcore.ResetSqlStats()
+	return app
+}
+
+// 归一化sql的统计
+func sqlStat(t *testing.T, sql string) *dzhcore.SqlStat {
+	t.Helper()
+	for _, stat := range dzhcore.GetSqlTop(1000)["statements"].([]*dzhcore.SqlStat) {
+		if stat.Sql == sql {
+			return stat
+		}
+	}
+	return nil
+}
+
+// 不开启 debug 也能统计 g.DB() 和 gorm 执行的sql
+func TestSqlObserver(t *testing.T) {
+	app := setupSqlObserver(t)
+	if g.DB().GetDebug() {
+		t.Fatal("开启SQL观测后打开了 debug")
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := g.DB().GetAll(app.Ctx, "SELECT code FROM test_product WHERE id = ?", i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	stat := sqlStat(t, "SELECT code FROM test_product WHERE id = ?")
+	if stat == nil || stat.Count != 3 {
+		t.Fatalf("g.DB() 语句统计 %+v, 期望执行 3 次", stat)
+	}
+
+	db, err := dzhcore.GetDBbyGroup("default")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for i := 0; i < 2; i++ {
+		if err = db.WithContext(app.Ctx).Raw("SELECT name FROM test_product WHERE code = ?", "c1").Scan(&names).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = db.WithContext(app.Ctx).Table("test_product").Where("code = ?", "c2").Pluck("name", &names).Error; err != nil {
+		t.Fatal(err)
+	}
+	if stat = sqlStat(t, "SELECT name FROM test_product WHERE code = ?"); stat == nil || stat.Count != 2 {
+		t.Fatalf("gorm 语句统计 %+v, 期望执行 2 次", stat)
+	}
+	if stat = sqlStat(t, "SELECT `name` FROM `test_product` WHERE code = ?"); stat == nil || stat.Count != 1 {
+		t.Fatalf("gorm 查询统计 %+v, 期望执行 1 次", stat)
+	}
+}
+
+// 请求中的查询次数和接口统计
+func TestSqlObserveRequest(t *testing.T) {
+	app := setupSqlObserver(t)
+	res := app.Client.Get(t, "/admin/test/sql/loop", g.Map{"times": 6})
+	if !res.Ok() {
+		t.Fatalf("请求失败: %s", res.Body)
+	}
+	data := res.Json()
+	if count := data.Get("count").Int(); count < 6 {
+		t.Fatalf("请求查询次数 %d, 期望至少 6", count)
+	}
+	var repeated int
+	for _, count := range data.Get("statements").MapStrVar() {
+		repeated = max(repeated, count.Int())
+	}
+	if repeated != 6 {
+		t.Fatalf("相同语句最多执行 %d 次, 期望 6", repeated)
+	}
+
+	routes := dzhcore.GetSqlTop(10)["routes"].([]*dzhcore.RouteStat)
+	if len(routes) != 1 || routes[0].Route != "/admin/test/sql/loop" || routes[0].Requests != 1 || routes[0].NPlusOneHits != 1 {
+		t.Fatalf("接口统计 %+v, 期望 /admin/test/sql/loop 请求 1 次、N+1 告警 1 次", routes)
+	}
+}
+
+// 语句统计超过 maxStats 时淘汰执行次数最少的
+func TestSqlStatsLimit(t *testing.T) {
+	app := setupSqlObserver(t)
+	maxStats := coreconfig.Config.Core.SQLObserver.MaxStats
+	coreconfig.Config.Core.SQLObserver.MaxStats = 3
+	t.Cleanup(func() { coreconfig.Config.Core.SQLObserver.MaxStats = maxStats })
+
+	for _, sql := range []string{
+		"SELECT id FROM test_product", "SELECT id FROM test_product", "SELECT id FROM test_product",
+		"SELECT code FROM test_product", "SELECT code FROM test_product",
+		"SELECT name FROM test_product",
+		"SELECT price FROM test_product",
+		"SELECT remark FROM test_product",
+	} {
+		if _, err := g.DB().GetAll(app.Ctx, sql); err != nil {
+			t.Fatal(err)
+		}
+	}
+	statements := dzhcore.GetSqlTop(10)["statements"].([]*dzhcore.SqlStat)
+	if len(statements) != 3 {
+		t.Fatalf("语句统计 %d 条, 期望 3", len(statements))
+	}
+	for _, sql := range []string{"SELECT id FROM test_product", "SELECT code FROM test_product", "SELECT remark FROM test_product"} {
+		if sqlStat(t, sql) == nil {
+			t.Errorf("淘汰了 %s", sql)
+		}
+	}
+}
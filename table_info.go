@@ -0,0 +1,155 @@
+package dzhcore
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gogf/gf/v2/container/garray"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"gorm.io/gorm"
+)
+
+// 读取数据库中已有表的结构,供代码生成使用
+
+// TableInfo 表结构
+type TableInfo struct {
+	Group   string         `json:"group"`   // 数据库分组
+	Name    string         `json:"name"`    // 表名
+	Comment string         `json:"comment"` // 表注释
+	Columns []*TableColumn `json:"columns"` // 列
+	Indexes []*TableIndex  `json:"indexes"` // 索引,不包括主键
+}
+
+// TableColumn 列结构
+type TableColumn struct {
+	Name          string `json:"name"`          // 列名
+	Type          string `json:"type"`          // 类型名,如 varchar
+	ColumnType    string `json:"columnType"`    // 完整类型,如 varchar(255)
+	Length        int64  `json:"length"`        // 长度,没有时为 0
+	Nullable      bool   `json:"nullable"`      // 是否可空
+	PrimaryKey    bool   `json:"primaryKey"`    // 是否主键
+	AutoIncrement bool   `json:"autoIncrement"` // 是否自增
+	HasDefault    bool   `json:"hasDefault"`    // 是否有默认值
+	Default       string `json:"default"`       // 默认值
+	Comment       string `json:"comment"`       // 注释
+}
+
+// TableIndex 索引结构
+type TableIndex struct {
+	Name    string   `json:"name"`    // 索引名
+	Columns []string `json:"columns"` // 列,按索引顺序
+	Unique  bool     `json:"unique"`  // 是否唯一索引
+}
+
+// 内置表,不参与代码生成
+var internalTablePrefixes = []string{"core_", "sqlite_"}
+
+// 分组的 gorm 连接,group 为空时为 default
+func tableDB(group string) (*gorm.DB, string, error) {
+	if group == "" {
+		group = "default"
+	}
+	if !garray.NewStrArrayFrom(DbGroups).Contains(group) {
+		return nil, group, gerror.Newf("数据库分组 %s 不存在", group)
+	}
+	db, err := getDBbyGroup(group)
+	return db, group, err
+}
+
+// GetTableNames 获取分组中的业务表,不包括 dzhcore 内置表和 sqlite 全文索引表
+func GetTableNames(ctx context.Context, group string) (names []string, err error) {
+	db, _, err := tableDB(group)
+	if err != nil {
+		return nil, err
+	}
+	tables, err := db.WithContext(ctx).Migrator().GetTables()
+	if err != nil {
+		return nil, err
+	}
+	for _, table := range tables {
+		if isInternalTable(table) {
+			continue
+		}
+		names = append(names, table)
+	}
+	sort.Strings(names)
+	return
+}
+
+func isInternalTable(table string) bool {
+	for _, prefix := range internalTablePrefixes {
+		if strings.HasPrefix(table, prefix) {
+			return true
+		}
+	}
+	// sqlite 全文索引的虚拟表和影子表
+	return strings.HasSuffix(table, "_fts") || strings.Contains(table, "_fts_")
+}
+
+// GetTableInfo 获取分组中表的结构
+func GetTableInfo(ctx context.Context, group, table string) (info *TableInfo, err error) {
+	db, group, err := tableDB(group)
+	if err != nil {
+		return nil, err
+	}
+	migrator := db.WithContext(ctx).Migrator()
+	if !migrator.HasTable(table) {
+		return nil, gerror.Newf("表 %s 不存在", table)
+	}
+	info = &TableInfo{Group: group, Name: table}
+	// sqlite 不支持表注释
+	if tableType, err := migrator.TableType(table); err == nil {
+		info.Comment, _ = tableType.Comment()
+	}
+
+	columnTypes, err := migrator.ColumnTypes(table)
+	if err != nil {
+		return nil, err
+	}
+	for _, columnType := range columnTypes {
+		column := &TableColumn{
+			Name: columnType.Name(),
+			Type: strings.ToLower(columnType.DatabaseTypeName()),
+		}
+		column.ColumnType, _ = columnType.ColumnType()
+		// sqlite 的 decimal(10,2) 会在逗号处截断
+		if strings.Count(column.ColumnType, "(") != strings.Count(column.ColumnType, ")") {
+			column.ColumnType = column.Type
+			if precision, scale, ok := columnType.DecimalSize(); ok && precision > 0 {
+				column.ColumnType = fmt.Sprintf("%s(%d,%d)", column.Type, precision, scale)
+			}
+		}
+		if column.ColumnType == "" {
+			column.ColumnType = column.Type
+		}
+		column.Length, _ = columnType.Length()
+		column.Nullable, _ = columnType.Nullable()
+		column.PrimaryKey, _ = columnType.PrimaryKey()
+		column.AutoIncrement, _ = columnType.AutoIncrement()
+		column.Default, column.HasDefault = columnType.DefaultValue()
+		column.Comment, _ = columnType.Comment()
+		info.Columns = append(info.Columns, column)
+	}
+
+	indexes, err := migrator.GetIndexes(table)
+	if err != nil {
+		return nil, err
+	}
+	for _, index := range indexes {
+		if primary, _ := index.PrimaryKey(); primary || strings.HasPrefix(index.Name(), "sqlite_autoindex_") {
+			continue
+		}
+		unique, _ := index.Unique()
+		info.Indexes = append(info.Indexes, &TableIndex{
+			Name:    index.Name(),
+			Columns: index.Columns(),
+			Unique:  unique,
+		})
+	}
+	sort.Slice(info.Indexes, func(i, j int) bool {
+		return info.Indexes[i].Name < info.Indexes[j].Name
+	})
+	return info, nil
+}
@@ -1,9 +1,25 @@
 package env
 
-import "github.com/gogf/gf/v2/frame/g"
+import (
+	"sync"
+
+	"github.com/gogf/gf/v2/frame/g"
+)
+
+var (
+	defaultsMu sync.RWMutex
+	defaults   = make(map[string]*g.Var) // 读取过的配置项和默认值 key:配置项
+)
 
 // GetCfgWithDefault get config with default value
 func GetCfgWithDefault(ctx g.Ctx, key string, defaultValue *g.Var) *g.Var {
+	// 同一配置项只记录第一次的默认值,即 coreconfig 中的默认值
+	defaultsMu.Lock()
+	if _, ok := defaults[key]; !ok {
+		defaults[key] = defaultValue
+	}
+	defaultsMu.Unlock()
+
 	value, err := g.Cfg().GetWithEnv(ctx, key)
 	if err != nil {
 		return defaultValue
@@ -13,3 +29,14 @@ func GetCfgWithDefault(ctx g.Ctx, key string, defaultValue *g.Var) *g.Var {
 	}
 	return value
 }
+
+// Defaults 通过 GetCfgWithDefault 读取过的配置项和默认值,用于查看配置的来源
+func Defaults() map[string]*g.Var {
+	defaultsMu.RLock()
+	defer defaultsMu.RUnlock()
+	result := make(map[string]*g.Var, len(defaults))
+	for key, value := range defaults {
+		result[key] = value
+	}
+	return result
+}
@@ -186,8 +186,11 @@ func (t *ToolUtil) GetDataBasePath(dbFileName string, isProd bool, appName strin
 // 获取上传文件路径
 func (t *ToolUtil) GetUploadPath(isProd bool, appName string, isDesktop bool, defaultPath string) string {
 
-	rootPath := t.GetRootPath(isProd, appName, isDesktop)
-	path := filepath.Join(rootPath, coreconfig.Config.Core.File.UploadPath)
+	path := coreconfig.Config.Core.File.UploadPath
+	// 绝对路径时直接使用,如测试时的临时目录
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(t.GetRootPath(isProd, appName, isDesktop), path)
+	}
 	// 创建目录，失败则 fallback
 	if err := os.MkdirAll(path, 0755); err != nil {
 		g.Log().Error(t.ctx, err.Error())
@@ -198,6 +201,31 @@ func (t *ToolUtil) GetUploadPath(isProd bool, appName string, isDesktop bool, de
 
 }
 
+// CheckWritable 检查目录是否可写,目录不存在时检查能否创建,不会创建目录
+func (t *ToolUtil) CheckWritable(dir string) error {
+	path, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	// 向上找到已存在的目录
+	for !gfile.Exists(path) {
+		parent := filepath.Dir(path)
+		if parent == path {
+			break
+		}
+		path = parent
+	}
+	if !gfile.IsDir(path) {
+		return fmt.Errorf("%s 不是目录", path)
+	}
+	file, err := os.CreateTemp(path, ".writable-*")
+	if err != nil {
+		return fmt.Errorf("目录 %s 不可写: %v", path, err)
+	}
+	file.Close()
+	return os.Remove(file.Name())
+}
+
 // 获取日志路径
 func (t *ToolUtil) GetLoggerPath(isProd bool, appName string, isDesktop bool, defaultPath string) string {
 
@@ -240,6 +268,26 @@ func (t *ToolUtil) GetSqlLoggerPath(isProd bool, appName string, isDesktop bool,
 
 }
 
+// 获取数据库备份路径
+func (t *ToolUtil) GetBackupPath(isProd bool, appName string, isDesktop bool, defaultPath string) string {
+
+	if isProd && isDesktop {
+		rootPath := t.GetRootPath(isProd, appName, isDesktop)
+		path := filepath.Join(rootPath, "data", "backup")
+		if err := os.MkdirAll(path, 0755); err != nil {
+			g.Log().Error(t.ctx, err.Error())
+			panic(err)
+		}
+		return path
+	}
+
+	if defaultPath != "" {
+		return defaultPath
+	}
+	return coreconfig.Config.Core.Backup.Path
+
+}
+
 // 带吞吐量，响应时间参数的运行日志
 func (t *ToolUtil) GetRunLoggerPath(isProd bool, appName string, isDesktop bool, defaultPath string) string {
 
@@ -260,8 +308,8 @@ func (t *ToolUtil) GetRunLoggerPath(isProd bool, appName string, isDesktop bool,
 
 }
 
-// 日式打印运行时间
-func (t *ToolUtil) StdOutLog(ctx context.Context, startTime time.Time, memStatsStart runtime.MemStats) {
+// 日式打印运行时间, extra 为追加的日志行
+func (t *ToolUtil) StdOutLog(ctx context.Context, startTime time.Time, memStatsStart runtime.MemStats, extra ...string) {
 	var (
 		r           = g.RequestFromCtx(ctx)
 		ctxId       = gctx.CtxId(r.GetCtx()) //获取当前请求的ctxid
@@ -326,6 +374,7 @@ func (t *ToolUtil) StdOutLog(ctx context.Context, startTime time.Time, memStatsS
 		fmt.Sprintf("[ 运行时间：%vs ] [TraceId：%v ] [ 吞吐率：%vreq/s ] [ 内存消耗：%v ]\n", outLogger_.RunTime, ctxId, throughputStringFixed, humanize.Bytes(outLogger_.MemUsed)),
 		fmt.Sprintf("[ info ] [ PARAM ] [ %v ]\n", t.StrTranLine(outLogger_.Params)),
 	}
+	logSlice = append(logSlice, extra...)
 
 	//超过容量就切割
 	byteSize, _ := humanize.ParseBytes(outLogger_.RotateSize)